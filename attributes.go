@@ -0,0 +1,106 @@
+package topogrid
+
+import (
+	"sort"
+)
+
+// attribute is a key and a value of an equipment attribute
+type attribute struct {
+	key   string
+	value string
+}
+
+// SetEquipmentAttribute sets the attribute of the equipment, e.g. a substation code, a bay or a manufacturer
+func (t *TopologyGridStruct) SetEquipmentAttribute(equipmentId int, key string, value string) error {
+	t.Lock()
+	defer t.Unlock()
+
+	if _, exists := t.equipment[equipmentId]; !exists {
+		return equipmentNotFound(equipmentId)
+	}
+
+	t.setEquipmentAttribute(equipmentId, key, value)
+	t.recordChange(ChangeAttribute, equipmentId)
+
+	return nil
+}
+
+func (t *TopologyGridStruct) setEquipmentAttribute(equipmentId int, key string, value string) {
+	attributes, exists := t.attributesFromEquipmentId[equipmentId]
+	if !exists {
+		attributes = make(map[string]string)
+		t.attributesFromEquipmentId[equipmentId] = attributes
+	}
+
+	if oldValue, exists := attributes[key]; exists {
+		t.unindexAttribute(equipmentId, attribute{key: key, value: oldValue})
+	}
+
+	attributes[key] = value
+
+	a := attribute{key: key, value: value}
+	if _, exists := t.equipmentIdsFromAttribute[a]; !exists {
+		t.equipmentIdsFromAttribute[a] = make(map[int]bool)
+	}
+	t.equipmentIdsFromAttribute[a][equipmentId] = true
+}
+
+// EquipmentAttribute returns the value of the equipment attribute and true if the attribute is set
+func (t *TopologyGridStruct) EquipmentAttribute(equipmentId int, key string) (string, bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	value, exists := t.attributesFromEquipmentId[equipmentId][key]
+
+	return value, exists
+}
+
+// EquipmentAttributes returns a copy of all attributes of the equipment: Key -> Value
+func (t *TopologyGridStruct) EquipmentAttributes(equipmentId int) map[string]string {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.equipmentAttributes(equipmentId)
+}
+
+// equipmentAttributes returns a copy of the equipment attributes or nil if there are none
+func (t *TopologyGridStruct) equipmentAttributes(equipmentId int) map[string]string {
+	if len(t.attributesFromEquipmentId[equipmentId]) == 0 {
+		return nil
+	}
+
+	return copyMap(t.attributesFromEquipmentId[equipmentId])
+}
+
+// EquipmentIdsByAttribute returns sorted ids of equipment with the attribute set to the value
+func (t *TopologyGridStruct) EquipmentIdsByAttribute(key, value string) []int {
+	t.RLock()
+	defer t.RUnlock()
+
+	equipmentIds := make([]int, 0, len(t.equipmentIdsFromAttribute[attribute{key: key, value: value}]))
+	for equipmentId := range t.equipmentIdsFromAttribute[attribute{key: key, value: value}] {
+		equipmentIds = append(equipmentIds, equipmentId)
+	}
+
+	sort.Ints(equipmentIds)
+
+	return equipmentIds
+}
+
+// deleteEquipmentAttributes removes all attributes of the equipment
+func (t *TopologyGridStruct) deleteEquipmentAttributes(equipmentId int) {
+	for key, value := range t.attributesFromEquipmentId[equipmentId] {
+		t.unindexAttribute(equipmentId, attribute{key: key, value: value})
+	}
+
+	delete(t.attributesFromEquipmentId, equipmentId)
+}
+
+// unindexAttribute removes the equipment from the lookup by the attribute
+func (t *TopologyGridStruct) unindexAttribute(equipmentId int, a attribute) {
+	delete(t.equipmentIdsFromAttribute[a], equipmentId)
+
+	if len(t.equipmentIdsFromAttribute[a]) == 0 {
+		delete(t.equipmentIdsFromAttribute, a)
+	}
+}
@@ -0,0 +1,99 @@
+package topogrid
+
+import (
+	"fmt"
+	"slices"
+)
+
+// NodeDefinition describes a node added by AddNodes, the fields match the AddNode arguments
+type NodeDefinition struct {
+	Id              int
+	EquipmentId     int
+	EquipmentTypeId int
+	EquipmentName   string
+}
+
+// EdgeDefinition describes an edge added by AddEdges, the fields match the AddEdge arguments
+type EdgeDefinition struct {
+	Id              int
+	Terminal1       int
+	Terminal2       int
+	State           int
+	EquipmentId     int
+	EquipmentTypeId int
+	EquipmentName   string
+}
+
+// AddNodes adds nodes to grid topology like AddNode under one lock. The whole batch is validated first,
+// so either all nodes are added or none. Returns ErrDuplicateNodeId if a node id already exists or is repeated in the batch
+func (t *TopologyGridStruct) AddNodes(nodes []NodeDefinition) error {
+	t.Lock()
+	defer t.Unlock()
+
+	seen := make(map[int]bool, len(nodes))
+
+	for _, node := range nodes {
+		if _, exists := t.nodeIdxFromNodeId[node.Id]; exists || seen[node.Id] {
+			return &IdError{Err: ErrDuplicateNodeId, Id: node.Id}
+		}
+		seen[node.Id] = true
+	}
+
+	if t.nodeIdx+len(nodes) > len(t.nodes) {
+		t.grow(max(t.nodeIdx+len(nodes), 2*len(t.nodes), 16))
+	}
+
+	if len(t.nodeIdxFromNodeId) == 0 {
+		t.nodeIdxFromNodeId = make(map[int]int, len(nodes))
+	}
+
+	for _, node := range nodes {
+		if err := t.addNode(node.Id, node.EquipmentId, node.EquipmentTypeId, node.EquipmentName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddEdges adds edges to grid topology like AddEdge under one lock. The whole batch is validated first,
+// so either all edges are added or none. Returns ErrDuplicateEdgeId if an edge id already exists or is repeated
+// in the batch, ErrNodeNotFound if a terminal node does not exist and ErrInvalidSwitchState for an invalid state
+func (t *TopologyGridStruct) AddEdges(edges []EdgeDefinition) error {
+	t.Lock()
+	defer t.Unlock()
+
+	seen := make(map[int]bool, len(edges))
+
+	for _, edge := range edges {
+		if _, exists := t.edgeIdxFromEdgeId[edge.Id]; exists || seen[edge.Id] {
+			return &IdError{Err: ErrDuplicateEdgeId, Id: edge.Id}
+		}
+		seen[edge.Id] = true
+
+		if !isValidSwitchState(edge.State) {
+			return fmt.Errorf("%w: %d for edge id %d", ErrInvalidSwitchState, edge.State, edge.Id)
+		}
+
+		for _, nodeId := range []int{edge.Terminal1, edge.Terminal2} {
+			if _, exists := t.nodeIdxFromNodeId[nodeId]; !exists {
+				return nodeNotFound(nodeId)
+			}
+		}
+	}
+
+	t.edges = slices.Grow(t.edges, len(edges))
+
+	if len(t.edgeIdxFromEdgeId) == 0 {
+		t.edgeIdxFromEdgeId = make(map[int]int, len(edges))
+		t.edgeIdArrayFromTerminalStruct = make(map[TerminalStruct][]int, len(edges))
+	}
+
+	for _, edge := range edges {
+		if err := t.addEdge(edge.Id, edge.Terminal1, edge.Terminal2, edge.State, edge.EquipmentId, edge.EquipmentTypeId, edge.EquipmentName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package topogrid
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+const benchmarkNumberOfEdges = 100000
+
+// radialDefinitions returns definitions of a radial topology with the number of edges: every node is connected
+// to the node with the half id by a closed circuit breaker or disconnect switch
+func radialDefinitions(numberOfEdges int) ([]NodeDefinition, []EdgeDefinition) {
+	nodes := make([]NodeDefinition, 0, numberOfEdges+1)
+	edges := make([]EdgeDefinition, 0, numberOfEdges)
+
+	nodes = append(nodes, NodeDefinition{Id: 1, EquipmentId: 1000001, EquipmentTypeId: TypePower, EquipmentName: "P1"})
+
+	for id := 2; id <= numberOfEdges+1; id++ {
+		nodes = append(nodes, NodeDefinition{Id: id, EquipmentId: 1000000 + id, EquipmentTypeId: TypeConsumer, EquipmentName: fmt.Sprintf("N%d", id)})
+
+		typeId := TypeDisconnectSwitch
+		if id%10 == 0 {
+			typeId = TypeCircuitBreaker
+		}
+		edges = append(edges, EdgeDefinition{Id: id, Terminal1: id / 2, Terminal2: id, State: SwitchStateClose,
+			EquipmentId: 2000000 + id, EquipmentTypeId: typeId, EquipmentName: fmt.Sprintf("E%d", id)})
+	}
+
+	return nodes, edges
+}
+
+func BenchmarkAddNodesAddEdges(b *testing.B) {
+	nodes, edges := radialDefinitions(benchmarkNumberOfEdges)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		topology := NewDynamic()
+
+		if err := topology.AddNodes(nodes); err != nil {
+			b.Fatal(err)
+		}
+
+		if err := topology.AddEdges(edges); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAddNodeAddEdge(b *testing.B) {
+	nodes, edges := radialDefinitions(benchmarkNumberOfEdges)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		topology := NewDynamic()
+
+		for _, node := range nodes {
+			if err := topology.AddNode(node.Id, node.EquipmentId, node.EquipmentTypeId, node.EquipmentName); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		for _, edge := range edges {
+			if err := topology.AddEdge(edge.Id, edge.Terminal1, edge.Terminal2, edge.State, edge.EquipmentId, edge.EquipmentTypeId, edge.EquipmentName); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestAddEdgesValidatesBatch(t *testing.T) {
+	nodes, edges := radialDefinitions(10)
+
+	topology := NewDynamic()
+	mustSucceed(t, topology.AddNodes(nodes))
+
+	for _, test := range []struct {
+		name   string
+		modify func(edges []EdgeDefinition)
+		err    error
+	}{
+		{"repeated edge id", func(edges []EdgeDefinition) { edges[9].Id = edges[0].Id }, ErrDuplicateEdgeId},
+		{"missing terminal", func(edges []EdgeDefinition) { edges[9].Terminal2 = 999 }, ErrNodeNotFound},
+		{"invalid state", func(edges []EdgeDefinition) { edges[9].State = 7 }, ErrInvalidSwitchState},
+	} {
+		batch := append([]EdgeDefinition{}, edges...)
+		test.modify(batch)
+
+		if err := topology.AddEdges(batch); !errors.Is(err, test.err) {
+			t.Fatalf("%s: AddEdges returns %v, want %v", test.name, err, test.err)
+		}
+
+		if edgeIds := topology.EdgeIds(); len(edgeIds) != 0 {
+			t.Fatalf("%s: failed batch adds edges %v", test.name, edgeIds)
+		}
+	}
+
+	mustSucceed(t, topology.AddEdges(edges))
+
+	if err := topology.AddNodes(nodes[:1]); !errors.Is(err, ErrDuplicateNodeId) {
+		t.Fatalf("adding an existing node returns %v", err)
+	}
+}
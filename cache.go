@@ -0,0 +1,54 @@
+package topogrid
+
+import (
+	"github.com/yourbasic/graph"
+)
+
+// componentCache is the connected component labeling of a topology graph
+type componentCache struct {
+	version uint64
+	labels  []int // NodeIdx -> Island
+}
+
+// componentLabels returns connected component labels of the current or the full topology graph, building them
+// if the graph has changed since the last call. Must be called with at least the read lock held
+func (t *TopologyGridStruct) componentLabels(full bool) []int {
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+
+	i, g := 0, t.currentGraph
+	if full {
+		i, g = 1, t.fullGraph
+	}
+
+	if t.components[i] == nil || t.components[i].version != t.graphVersion {
+		labels, _ := t.islandLabels(g)
+		t.components[i] = &componentCache{version: t.graphVersion, labels: labels}
+	}
+
+	return t.components[i].labels
+}
+
+// sortedGraphCache is a topology graph sorted by graph.Sort
+type sortedGraphCache struct {
+	version uint64
+	graph   *graph.Immutable
+}
+
+// sortedGraph returns the sorted current or full topology graph, sorting it again only if the graph has changed
+// since the last call. Must be called with at least the read lock held
+func (t *TopologyGridStruct) sortedGraph(full bool) *graph.Immutable {
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+
+	i, g := 0, t.currentGraph
+	if full {
+		i, g = 1, t.fullGraph
+	}
+
+	if t.sorted[i] == nil || t.sorted[i].version != t.graphVersion {
+		t.sorted[i] = &sortedGraphCache{version: t.graphVersion, graph: graph.Sort(g)}
+	}
+
+	return t.sorted[i].graph
+}
@@ -0,0 +1,50 @@
+package topogrid
+
+import "testing"
+
+// benchmarkBfsFromPowerNodes traverses the topology from every power node like the electrical state calculation,
+// invalidating the sorted graph before every traversal if cached is false
+func benchmarkBfsFromPowerNodes(b *testing.B, cached bool) {
+	topology := newLargeGrid(b, 50000, 10)
+	powerNodeIds := topology.NodeIdsByType(TypePower)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, powerNodeId := range powerNodeIds {
+			if !cached {
+				topology.graphVersion++
+			}
+
+			topology.BfsFromNodeId(powerNodeId)
+		}
+	}
+}
+
+func BenchmarkBfsFromNodeIdCached(b *testing.B) {
+	benchmarkBfsFromPowerNodes(b, true)
+}
+
+func BenchmarkBfsFromNodeIdUncached(b *testing.B) {
+	benchmarkBfsFromPowerNodes(b, false)
+}
+
+func TestSortedGraphIsRebuiltAfterSwitching(t *testing.T) {
+	topology := newTestGrid(t)
+
+	if path := topology.BfsFromNodeId(5); len(path) != 1 {
+		t.Fatalf("path from P2 %v, want one arc to the node 6", path)
+	}
+
+	mustSucceed(t, topology.SetSwitchState(150, SwitchStateClose))
+
+	if path := topology.BfsFromNodeId(5); len(path) != 5 {
+		t.Fatalf("path from P2 after closing CB50 %v, want 5 arcs", path)
+	}
+
+	mustSucceed(t, topology.SetSwitchState(150, SwitchStateOpen))
+
+	if path := topology.BfsFromNodeId(5); len(path) != 1 {
+		t.Fatalf("path from P2 after opening CB50 %v, want one arc to the node 6", path)
+	}
+}
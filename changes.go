@@ -0,0 +1,82 @@
+package topogrid
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ChangeKind is the kind of topology change, see Changes
+type ChangeKind int
+
+const (
+	ChangeNodeAdded   ChangeKind = iota // Node added, the id is the node id
+	ChangeNodeRemoved                   // Node removed, the id is the node id
+	ChangeNodeData                      // Node coordinates or voltage level set, the id is the node id
+	ChangeEdgeAdded                     // Edge added, the id is the edge id
+	ChangeEdgeRemoved                   // Edge removed, the id is the edge id
+	ChangeEdgeData                      // Edge weight or length set, the id is the edge id
+	ChangeSwitchState                   // Switch state changed, the id is the equipment id
+	ChangeAttribute                     // Equipment attribute set, the id is the equipment id
+	ChangeEquipment                     // Equipment name, type, priority, load, fault, service or point binding set, the id is the equipment id
+)
+
+// changeLogSize is the number of the latest changes kept for Changes
+const changeLogSize = 4096
+
+// ChangeRecord is a topology change and the version it produced
+type ChangeRecord struct {
+	Version uint64
+	Kind    ChangeKind
+	Id      int
+}
+
+// Version returns the topology version incremented on every change of nodes, edges, switch states and equipment.
+// Electrical states calculated by SetEquipmentElectricalState do not change the version
+func (t *TopologyGridStruct) Version() uint64 {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.version
+}
+
+// Changes returns changes made after the version in the order they were made. Only the latest changes are kept,
+// returns ErrChangesTruncated if some of the changes after the version are not kept anymore
+func (t *TopologyGridStruct) Changes(sinceVersion uint64) ([]ChangeRecord, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	changes := make([]ChangeRecord, 0)
+
+	if sinceVersion >= t.version {
+		return changes, nil
+	}
+
+	log := append(slices.Clone(t.changeLog[t.changeLogStart:]), t.changeLog[:t.changeLogStart]...)
+
+	if len(log) == 0 || log[0].Version > sinceVersion+1 {
+		return nil, fmt.Errorf("%w: since version %d", ErrChangesTruncated, sinceVersion)
+	}
+
+	for _, record := range log {
+		if record.Version > sinceVersion {
+			changes = append(changes, record)
+		}
+	}
+
+	return changes, nil
+}
+
+// recordChange increments the topology version and records the change, replacing the oldest one if the log is full
+func (t *TopologyGridStruct) recordChange(kind ChangeKind, id int) {
+	t.version++
+
+	record := ChangeRecord{Version: t.version, Kind: kind, Id: id}
+
+	if len(t.changeLog) < changeLogSize {
+		t.changeLog = append(t.changeLog, record)
+		return
+	}
+
+	t.changeLog[t.changeLogStart] = record
+	t.changeLogStart = (t.changeLogStart + 1) % changeLogSize
+}
@@ -0,0 +1,116 @@
+package topogrid
+
+import (
+	"slices"
+
+	"github.com/yourbasic/graph"
+)
+
+// Clone returns a deep copy of the topology. Changes of the copy never affect the original topology and vice versa
+func (t *TopologyGridStruct) Clone() *TopologyGridStruct {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.clone()
+}
+
+func (t *TopologyGridStruct) clone() *TopologyGridStruct {
+	clone := &TopologyGridStruct{
+		currentGraph:                       graph.Copy(t.currentGraph),
+		fullGraph:                          graph.Copy(t.fullGraph),
+		weightedGraph:                      graph.Copy(t.weightedGraph),
+		nodes:                              make([]NodeStruct, len(t.nodes)),
+		edges:                              make([]EdgeStruct, len(t.edges)),
+		equipment:                          make(map[int]EquipmentStruct, len(t.equipment)),
+		nodeIdxFromNodeId:                  copyMap(t.nodeIdxFromNodeId),
+		nodeIdArrayFromEquipmentTypeId:     copyArrayMap(t.nodeIdArrayFromEquipmentTypeId),
+		nodeIdArrayFromEquipmentId:         copyArrayMap(t.nodeIdArrayFromEquipmentId),
+		edgeIdxFromEdgeId:                  copyMap(t.edgeIdxFromEdgeId),
+		edgeIdArrayFromEquipmentTypeId:     copyArrayMap(t.edgeIdArrayFromEquipmentTypeId),
+		edgeIdArrayFromTerminalStruct:      copyArrayMap(t.edgeIdArrayFromTerminalStruct),
+		edgeIdArrayFromNodeId:              copyArrayMap(t.edgeIdArrayFromNodeId),
+		edgeIdArrayFromEquipmentId:         copyArrayMap(t.edgeIdArrayFromEquipmentId),
+		terminalNodeIdsFromEdgeEquipmentId: copyArrayMap(t.terminalNodeIdsFromEdgeEquipmentId),
+		nodeIdx:                            t.nodeIdx,
+		edgeIdx:                            t.edgeIdx,
+		unusedNodeSlots:                    t.unusedNodeSlots,
+		coordinatesFromNodeId:              copyMap(t.coordinatesFromNodeId),
+		voltageLevelFromNodeId:             copyMap(t.voltageLevelFromNodeId),
+		attributesFromEquipmentId:          make(map[int]map[string]string, len(t.attributesFromEquipmentId)),
+		equipmentIdsFromAttribute:          make(map[attribute]map[int]bool, len(t.equipmentIdsFromAttribute)),
+		equipmentIdFromPointId:             copyMap(t.equipmentIdFromPointId),
+		graphVersion:                       t.graphVersion,
+		version:                            t.version,
+		changeLog:                          slices.Clone(t.changeLog),
+		changeLogStart:                     t.changeLogStart,
+	}
+
+	copy(clone.nodes, t.nodes)
+	copy(clone.edges, t.edges)
+
+	for id, equipment := range t.equipment {
+		equipment.poweredBy = copyMap(equipment.poweredBy)
+		clone.equipment[id] = equipment
+	}
+
+	for id, attributes := range t.attributesFromEquipmentId {
+		clone.attributesFromEquipmentId[id] = copyMap(attributes)
+	}
+
+	for key, equipmentIds := range t.equipmentIdsFromAttribute {
+		clone.equipmentIdsFromAttribute[key] = copyMap(equipmentIds)
+	}
+
+	return clone
+}
+
+// copyMap returns a copy of the map
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	copied := make(map[K]V, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
+
+// copyArrayMap returns a copy of the map with copies of the arrays
+func copyArrayMap[K comparable](arrayMap map[K][]int) map[K][]int {
+	copied := make(map[K][]int, len(arrayMap))
+	for key, array := range arrayMap {
+		copied[key] = append(make([]int, 0, len(array)), array...)
+	}
+	return copied
+}
+
+// adopt replaces the topology with the copy made by clone and changed since. Locks, caches and observers are kept,
+// caches are invalidated by the graph version of the copy and the electrical state is recalculated in full next time
+func (t *TopologyGridStruct) adopt(clone *TopologyGridStruct) {
+	t.currentGraph = clone.currentGraph
+	t.fullGraph = clone.fullGraph
+	t.weightedGraph = clone.weightedGraph
+	t.nodes = clone.nodes
+	t.edges = clone.edges
+	t.equipment = clone.equipment
+	t.nodeIdxFromNodeId = clone.nodeIdxFromNodeId
+	t.nodeIdArrayFromEquipmentTypeId = clone.nodeIdArrayFromEquipmentTypeId
+	t.nodeIdArrayFromEquipmentId = clone.nodeIdArrayFromEquipmentId
+	t.edgeIdxFromEdgeId = clone.edgeIdxFromEdgeId
+	t.edgeIdArrayFromEquipmentTypeId = clone.edgeIdArrayFromEquipmentTypeId
+	t.edgeIdArrayFromTerminalStruct = clone.edgeIdArrayFromTerminalStruct
+	t.edgeIdArrayFromNodeId = clone.edgeIdArrayFromNodeId
+	t.edgeIdArrayFromEquipmentId = clone.edgeIdArrayFromEquipmentId
+	t.terminalNodeIdsFromEdgeEquipmentId = clone.terminalNodeIdsFromEdgeEquipmentId
+	t.nodeIdx = clone.nodeIdx
+	t.edgeIdx = clone.edgeIdx
+	t.unusedNodeSlots = clone.unusedNodeSlots
+	t.coordinatesFromNodeId = clone.coordinatesFromNodeId
+	t.voltageLevelFromNodeId = clone.voltageLevelFromNodeId
+	t.attributesFromEquipmentId = clone.attributesFromEquipmentId
+	t.equipmentIdsFromAttribute = clone.equipmentIdsFromAttribute
+	t.equipmentIdFromPointId = clone.equipmentIdFromPointId
+	t.graphVersion = clone.graphVersion
+	t.version = clone.version
+	t.changeLog = clone.changeLog
+	t.changeLogStart = clone.changeLogStart
+	t.sourceReaches = nil
+}
@@ -0,0 +1,110 @@
+package topogrid
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/yourbasic/graph"
+)
+
+// topologySnapshot returns the graphs, nodes, edges, equipment, lookups, change log and caches of the topology.
+// Caches are compared by their addresses, since a cache is replaced when it is rebuilt
+func topologySnapshot(topology *TopologyGridStruct) string {
+	return fmt.Sprintf("%s\n%s\n%s\n%v\n%v\n%v\n%v %v %v %v %v %v %v %v %v\n%v %v %v %v %v %v\n%v %v %v\n%p %p %p %p %p %p %p\n%v %v %v\n",
+		graph.String(topology.currentGraph), graph.String(topology.fullGraph), graph.String(topology.weightedGraph),
+		topology.nodes, topology.edges, topology.equipment,
+		topology.nodeIdxFromNodeId, topology.nodeIdArrayFromEquipmentTypeId, topology.nodeIdArrayFromEquipmentId,
+		topology.edgeIdxFromEdgeId, topology.edgeIdArrayFromEquipmentTypeId, topology.edgeIdArrayFromTerminalStruct,
+		topology.edgeIdArrayFromNodeId, topology.edgeIdArrayFromEquipmentId, topology.terminalNodeIdsFromEdgeEquipmentId,
+		topology.coordinatesFromNodeId, topology.voltageLevelFromNodeId, topology.attributesFromEquipmentId,
+		topology.equipmentIdsFromAttribute, topology.equipmentIdFromPointId, topology.nodeIdx,
+		topology.version, topology.changeLog, topology.graphVersion,
+		topology.zones, topology.components[0], topology.components[1], topology.sorted[0], topology.sorted[1], topology.sections, topology.feeders,
+		topology.sourceReaches, topology.reachVersion, topology.blockedArcs)
+}
+
+// buildCaches builds all lazily built caches of the topology
+func buildCaches(topology *TopologyGridStruct) {
+	topology.RLock()
+	defer topology.RUnlock()
+
+	topology.zoneCache()
+	topology.sectionCache()
+	topology.feederCache()
+	topology.componentLabels(false)
+	topology.componentLabels(true)
+	topology.sortedGraph(false)
+	topology.sortedGraph(true)
+}
+
+// mutateHeavily changes switch states, types, faults, attributes, point bindings, coordinates and voltage levels
+// of the topology, adds nodes and edges and recalculates electrical states and caches
+func mutateHeavily(t testing.TB, topology *TopologyGridStruct, r *rand.Rand) {
+	t.Helper()
+
+	states := []int{SwitchStateOpen, SwitchStateClose, SwitchStateUnknown}
+	equipmentIds := switchEquipmentIds(topology)
+	nodeIds := topology.NodeIds()
+
+	for i := 0; i < 20; i++ {
+		equipmentId := equipmentIds[r.Intn(len(equipmentIds))]
+
+		_, err := topology.UpdateElectricalStateAfterSwitch(equipmentId, states[r.Intn(len(states))])
+		mustSucceed(t, err)
+	}
+
+	mustSucceed(t, topology.SetEquipmentType(equipmentIds[0], TypeFuse))
+	mustSucceed(t, topology.SetEquipmentFault(equipmentIds[1], true))
+	mustSucceed(t, topology.SetEquipmentOutOfService(equipmentIds[2], true))
+
+	for i, equipmentId := range topology.EquipmentIds() {
+		mustSucceed(t, topology.SetEquipmentAttribute(equipmentId, "substation", fmt.Sprintf("X%d", i%4)))
+		mustSucceed(t, topology.SetEquipmentAttribute(equipmentId, "owner", "clone"))
+		mustSucceed(t, topology.BindPoint(uint64(1000+i), equipmentId))
+	}
+
+	for _, nodeId := range nodeIds {
+		mustSucceed(t, topology.SetNodeCoordinates(nodeId, -1, -1))
+		mustSucceed(t, topology.SetNodeVoltageLevel(nodeId, 0.4))
+	}
+
+	// New nodes grow the node slots and the graphs of the clone
+	for id := 1000; id < 1000+2*len(nodeIds); id++ {
+		mustSucceed(t, topology.AddNode(id, 30000+id, TypeConsumer, fmt.Sprintf("X%d", id)))
+		mustSucceed(t, topology.AddEdge(5000+id, nodeIds[r.Intn(len(nodeIds))], id, SwitchStateClose, 40000+id, TypeCircuitBreaker, fmt.Sprintf("XE%d", id)))
+	}
+
+	topology.SetEquipmentElectricalState()
+	buildCaches(topology)
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		topology := newRandomGrid(t, r)
+		decorateRandomGrid(t, topology, r)
+		buildCaches(topology)
+
+		before := topologySnapshot(topology)
+		clone := topology.Clone()
+
+		if queryResults(t, clone) != queryResults(t, topology) {
+			t.Fatalf("seed %d: clone differs from the original", seed)
+		}
+
+		mutateHeavily(t, clone, r)
+
+		if after := topologySnapshot(topology); after != before {
+			t.Fatalf("seed %d: changes of the clone change the original\nbefore:\n%s\nafter:\n%s", seed, before, after)
+		}
+
+		// And the other way round
+		cloneBefore := topologySnapshot(clone)
+		mutateHeavily(t, topology, r)
+
+		if after := topologySnapshot(clone); after != cloneBefore {
+			t.Fatalf("seed %d: changes of the original change the clone", seed)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package topogrid
+
+import (
+	"sort"
+
+	"github.com/yourbasic/graph"
+)
+
+// CriticalEquipment is the number of consumers that would lose all supply without the equipment
+type CriticalEquipment struct {
+	EquipmentId int
+	Consumers   int
+}
+
+// EquipmentCriticality returns the number of energized consumers that would lose all supply if the edges
+// of the equipment were removed from the current topology graph. For equipment of nodes the edges connected
+// to its nodes are removed. The topology is not changed
+func (t *TopologyGridStruct) EquipmentCriticality(equipmentId int) (int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	if _, exists := t.equipment[equipmentId]; !exists {
+		return 0, equipmentNotFound(equipmentId)
+	}
+
+	return t.equipmentCriticality(equipmentId, t.reachableFromPower(t.currentGraph)), nil
+}
+
+// CriticalityRanking returns the topN equipment of edges with the highest EquipmentCriticality sorted from the highest,
+// equipment with the same criticality is sorted by id. Equipment whose removal disconnects no consumer is skipped,
+// a non-positive topN returns all equipment. Bridges of the current topology graph are found by a single depth-first
+// search from all power nodes, and the consumers cut off by every bridge are counted in the same search,
+// so only equipment with several edges is evaluated separately
+func (t *TopologyGridStruct) CriticalityRanking(topN int) []CriticalEquipment {
+	t.RLock()
+	defer t.RUnlock()
+
+	// Depth-first search tree of the current topology graph rooted at an extra node connected to every power node,
+	// arcs of the extra node have no edge number
+	root := t.nodeIdx
+	edges := t.graphEdges(t.isEdgeClosed)
+
+	type arc struct {
+		to      int
+		edgeNum int
+	}
+
+	adjacency := make([][]arc, t.nodeIdx+1)
+	edgeNumFromEdgeId := make(map[int]int, len(edges))
+
+	for edgeNum, edge := range edges {
+		edgeNumFromEdgeId[edge.id] = edgeNum
+
+		node1Idx := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+
+		adjacency[node1Idx] = append(adjacency[node1Idx], arc{to: node2Idx, edgeNum: edgeNum})
+		adjacency[node2Idx] = append(adjacency[node2Idx], arc{to: node1Idx, edgeNum: edgeNum})
+	}
+
+	for _, nodeIdOfPowerNode := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		if nodeIdx, exists := t.nodeIdxFromNodeId[nodeIdOfPowerNode]; exists {
+			adjacency[root] = append(adjacency[root], arc{to: nodeIdx, edgeNum: -1})
+			adjacency[nodeIdx] = append(adjacency[nodeIdx], arc{to: root, edgeNum: -1})
+		}
+	}
+
+	order := make([]int, t.nodeIdx+1)
+	low := make([]int, t.nodeIdx+1)
+	consumers := make([]int, t.nodeIdx+1) // Consumers in the subtree
+	cutOff := make([]int, len(edges))     // Edge -> consumers cut off by the bridge
+	counter := 0
+
+	var visit func(v int, parentEdgeNum int)
+	visit = func(v int, parentEdgeNum int) {
+		counter++
+		order[v] = counter
+		low[v] = counter
+
+		if v != root && t.nodeTypeId(t.nodes[v]) == TypeConsumer && t.nodes[v].equipmentId != 0 {
+			consumers[v] = 1
+		}
+
+		for _, a := range adjacency[v] {
+			if a.edgeNum == parentEdgeNum && a.edgeNum >= 0 {
+				continue
+			}
+
+			if order[a.to] == 0 {
+				visit(a.to, a.edgeNum)
+				low[v] = min(low[v], low[a.to])
+				consumers[v] += consumers[a.to]
+
+				if a.edgeNum >= 0 && low[a.to] > order[v] {
+					cutOff[a.edgeNum] = consumers[a.to]
+				}
+			} else {
+				low[v] = min(low[v], order[a.to])
+			}
+		}
+	}
+
+	visit(root, -1)
+
+	ranking := make([]CriticalEquipment, 0)
+	var reachable []bool
+
+	for equipmentId, edgeIdArray := range t.edgeIdArrayFromEquipmentId {
+		criticality := 0
+
+		switch {
+		case len(edgeIdArray) == 0:
+			continue
+		case len(edgeIdArray) == 1:
+			if edgeNum, exists := edgeNumFromEdgeId[edgeIdArray[0]]; exists {
+				criticality = cutOff[edgeNum]
+			}
+		default:
+			if reachable == nil {
+				reachable = t.reachableFromPower(t.currentGraph)
+			}
+			criticality = t.equipmentCriticality(equipmentId, reachable)
+		}
+
+		if criticality > 0 {
+			ranking = append(ranking, CriticalEquipment{EquipmentId: equipmentId, Consumers: criticality})
+		}
+	}
+
+	sort.Slice(ranking, func(i, j int) bool {
+		if ranking[i].Consumers != ranking[j].Consumers {
+			return ranking[i].Consumers > ranking[j].Consumers
+		}
+		return ranking[i].EquipmentId < ranking[j].EquipmentId
+	})
+
+	if topN > 0 && len(ranking) > topN {
+		ranking = ranking[:topN]
+	}
+
+	return ranking
+}
+
+// equipmentCriticality returns the number of consumers with a node reachable from the power nodes before
+// and none after removing the equipment edges or the edges connected to the equipment nodes from a copy
+// of the current topology graph
+func (t *TopologyGridStruct) equipmentCriticality(equipmentId int, reachableBefore []bool) int {
+	scratchGraph := graph.Copy(t.currentGraph)
+
+	removeEdge := func(edge EdgeStruct) {
+		node1Idx, existsNode1 := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx, existsNode2 := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+		if existsNode1 && existsNode2 {
+			scratchGraph.DeleteBoth(node1Idx, node2Idx)
+		}
+	}
+
+	isRemoved := make(map[int]bool)
+	for _, edgeId := range t.edgeIdArrayFromEquipmentId[equipmentId] {
+		isRemoved[edgeId] = true
+	}
+
+	for _, edgeId := range t.edgeIdArrayFromEquipmentId[equipmentId] {
+		edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+
+		// Parallel closed edges of other equipment keep the arc
+		keep := false
+		for _, parallelId := range t.edgeIdsBetweenNodes(edge.terminal.node1Id, edge.terminal.node2Id) {
+			if parallel := t.edges[t.edgeIdxFromEdgeId[parallelId]]; !isRemoved[parallelId] && t.isEdgeClosed(parallel) {
+				keep = true
+			}
+		}
+
+		if !keep {
+			removeEdge(edge)
+		}
+	}
+
+	for _, nodeId := range t.nodeIdArrayFromEquipmentId[equipmentId] {
+		for _, edgeId := range t.edgeIdArrayFromNodeId[nodeId] {
+			removeEdge(t.edges[t.edgeIdxFromEdgeId[edgeId]])
+		}
+	}
+
+	reachableAfter := t.reachableFromPower(scratchGraph)
+	criticality := 0
+
+	consumerIds := make(map[int]bool)
+	for _, nodeId := range t.nodeIdArrayFromEquipmentTypeId[TypeConsumer] {
+		if nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]; exists && t.nodes[nodeIdx].equipmentId != 0 {
+			consumerIds[t.nodes[nodeIdx].equipmentId] = true
+		}
+	}
+
+	for consumerId := range consumerIds {
+		wasSupplied, isSupplied := false, false
+
+		for _, nodeIdx := range t.equipmentTerminalIdxArray(consumerId) {
+			wasSupplied = wasSupplied || reachableBefore[nodeIdx]
+			isSupplied = isSupplied || reachableAfter[nodeIdx]
+		}
+
+		if wasSupplied && !isSupplied {
+			criticality++
+		}
+	}
+
+	return criticality
+}
@@ -0,0 +1,106 @@
+package topogrid
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// checkCriticalityRanking compares CriticalityRanking with EquipmentCriticality of every equipment of edges
+func checkCriticalityRanking(t *testing.T, topology *TopologyGridStruct) []CriticalEquipment {
+	t.Helper()
+
+	ranking := topology.CriticalityRanking(0)
+
+	want := make([]CriticalEquipment, 0)
+	for equipmentId, edgeIdArray := range topology.edgeIdArrayFromEquipmentId {
+		if len(edgeIdArray) == 0 {
+			continue
+		}
+
+		consumers, err := topology.EquipmentCriticality(equipmentId)
+		mustSucceed(t, err)
+
+		if consumers > 0 {
+			want = append(want, CriticalEquipment{EquipmentId: equipmentId, Consumers: consumers})
+		}
+	}
+
+	slices.SortFunc(want, func(a, b CriticalEquipment) int {
+		if a.Consumers != b.Consumers {
+			return b.Consumers - a.Consumers
+		}
+		return a.EquipmentId - b.EquipmentId
+	})
+
+	if !slices.Equal(ranking, want) {
+		t.Fatalf("CriticalityRanking = %v, EquipmentCriticality of every equipment = %v", ranking, want)
+	}
+
+	return ranking
+}
+
+func TestCriticalityRanking(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		topology func(t testing.TB) *TopologyGridStruct
+		want     []CriticalEquipment
+	}{
+		// The open CB40 carries no supply and is not critical
+		{"ring with an open point", newRingGrid, []CriticalEquipment{{110, 2}, {120, 2}, {130, 1}, {150, 1}, {160, 1}}},
+		{"radial feeder", newRadialGrid, []CriticalEquipment{{110, 2}, {120, 2}, {130, 1}, {140, 1}}},
+		// Either parallel line keeps the consumer supplied
+		{"parallel lines", newParallelGrid, []CriticalEquipment{{150, 1}}},
+		{"test grid", newTestGrid, []CriticalEquipment{{110, 1}, {120, 1}, {130, 1}}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if ranking := checkCriticalityRanking(t, test.topology(t)); !slices.Equal(ranking, test.want) {
+				t.Fatalf("CriticalityRanking = %v, want %v", ranking, test.want)
+			}
+		})
+	}
+}
+
+func TestCriticalityRankingOfClosedRing(t *testing.T) {
+	topology := newRingGrid(t)
+	mustSucceed(t, topology.SetSwitchState(140, SwitchStateClose))
+
+	// Every consumer of a closed ring is supplied from both sides
+	if ranking := checkCriticalityRanking(t, topology); len(ranking) != 0 {
+		t.Fatalf("CriticalityRanking of a closed ring = %v, want empty", ranking)
+	}
+
+	// The consumer itself is cut off by removing the edges of its node
+	if consumers, err := topology.EquipmentCriticality(104); err != nil || consumers != 1 {
+		t.Fatalf("EquipmentCriticality of a consumer = %d, %v, want 1", consumers, err)
+	}
+
+	if _, err := topology.EquipmentCriticality(999); !errors.Is(err, ErrEquipmentNotFound) {
+		t.Fatalf("EquipmentCriticality of an unknown equipment returns %v, want ErrEquipmentNotFound", err)
+	}
+}
+
+func TestCriticalityRankingOfEquipmentWithSeveralEdges(t *testing.T) {
+	// The double circuit line LN60 of two edges feeds C3, removing the equipment removes both circuits
+	topology := newRadialGrid(t)
+	mustSucceed(t, topology.AddNode(6, 106, TypeConsumer, "C3"))
+	mustSucceed(t, topology.AddEdge(60, 3, 6, SwitchStateClose, 160, TypeLine, "LN60"))
+	mustSucceed(t, topology.AddEdge(61, 3, 6, SwitchStateClose, 160, TypeLine, "LN60"))
+
+	want := []CriticalEquipment{{110, 3}, {120, 3}, {130, 1}, {140, 1}, {160, 1}}
+	if ranking := checkCriticalityRanking(t, topology); !slices.Equal(ranking, want) {
+		t.Fatalf("CriticalityRanking = %v, want %v", ranking, want)
+	}
+
+	if ranking := topology.CriticalityRanking(2); !slices.Equal(ranking, want[:2]) {
+		t.Fatalf("CriticalityRanking(2) = %v, want %v", ranking, want[:2])
+	}
+}
+
+func TestCriticalityRankingMatchesEquipmentCriticality(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		checkCriticalityRanking(t, newRandomGrid(t, r))
+	}
+}
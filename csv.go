@@ -0,0 +1,112 @@
+package topogrid
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Column layout of CSV files
+//
+//	nodes: node_id, equipment_id, type_id, name
+//	edges: edge_id, node1, node2, state, equipment_id, type_id, name
+const (
+	csvNumberOfNodeColumns = 4
+	csvNumberOfEdgeColumns = 7
+)
+
+// NewFromCSV creates a topology from two CSV files with nodes and edges. The first row of each file is treated as
+// a header if its first field is not a number. Errors are reported with the row number
+func NewFromCSV(nodes io.Reader, edges io.Reader) (*TopologyGridStruct, error) {
+	nodeRows, err := readCsvRows(nodes, csvNumberOfNodeColumns)
+	if err != nil {
+		return nil, fmt.Errorf("nodes: %w", err)
+	}
+
+	edgeRows, err := readCsvRows(edges, csvNumberOfEdgeColumns)
+	if err != nil {
+		return nil, fmt.Errorf("edges: %w", err)
+	}
+
+	t := New(len(nodeRows))
+
+	for _, row := range nodeRows {
+		var id, equipmentId, typeId int
+
+		if err := row.ints(&id, &equipmentId, &typeId); err != nil {
+			return nil, fmt.Errorf("nodes: %w", err)
+		}
+
+		if err := t.AddNode(id, equipmentId, typeId, row.fields[3]); err != nil {
+			return nil, fmt.Errorf("nodes: row %d: %w", row.number, err)
+		}
+	}
+
+	for _, row := range edgeRows {
+		var id, node1Id, node2Id, state, equipmentId, typeId int
+
+		if err := row.ints(&id, &node1Id, &node2Id, &state, &equipmentId, &typeId); err != nil {
+			return nil, fmt.Errorf("edges: %w", err)
+		}
+
+		if err := t.AddEdge(id, node1Id, node2Id, state, equipmentId, typeId, row.fields[6]); err != nil {
+			return nil, fmt.Errorf("edges: row %d: %w", row.number, err)
+		}
+	}
+
+	return t, nil
+}
+
+type csvRow struct {
+	number int
+	fields []string
+}
+
+// ints parses leading fields of the row as integers
+func (r csvRow) ints(values ...*int) error {
+	for i, value := range values {
+		var err error
+		if *value, err = strconv.Atoi(r.fields[i]); err != nil {
+			return fmt.Errorf("row %d, column %d: %w", r.number, i+1, err)
+		}
+	}
+	return nil
+}
+
+// readCsvRows reads all rows with the fixed number of columns, skipping the header row
+func readCsvRows(r io.Reader, numberOfColumns int) ([]csvRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = numberOfColumns
+	reader.TrimLeadingSpace = true
+
+	rows := make([]csvRow, 0)
+
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		number, _ := reader.FieldPos(0)
+
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+
+		if len(rows) == 0 && number == 1 {
+			if _, err := strconv.Atoi(fields[0]); err != nil {
+				continue
+			}
+		}
+
+		rows = append(rows, csvRow{number: number, fields: fields})
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,96 @@
+package topogrid
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Node and edge attributes of the DOT output, mirroring the GML graphics. Attributes of equipment types
+// are built from the colors and shapes registered in types.go
+const (
+	dotAttributesJoin     = "shape=point color=\"#808080\""
+	dotAttributesStateOff = "style=dashed color=\"#000000\""
+)
+
+// GetAsDot returns a string with an undirected graph represented by the Graphviz DOT language
+func (t *TopologyGridStruct) GetAsDot() string {
+	t.RLock()
+	defer t.RUnlock()
+
+	var dot strings.Builder
+
+	t.writeDot(&dot)
+
+	return dot.String()
+}
+
+// WriteDot writes the graph returned by GetAsDot into w
+func (t *TopologyGridStruct) WriteDot(w io.Writer) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	return writeBuffered(w, func(writer exportWriter) error {
+		t.writeDot(writer)
+		return nil
+	})
+}
+
+func (t *TopologyGridStruct) writeDot(dot exportWriter) {
+	_, _ = dot.WriteString("graph {\n")
+
+	for _, node := range t.nodes[:t.nodeIdx] {
+		attributes := dotAttributesJoin
+
+		if registered := registeredEquipmentType(t.equipment[node.equipmentId].typeId); registered.nodeGraphics() {
+			attributes = fmt.Sprintf("shape=%s style=filled fillcolor=\"%s\"", registered.dotShape(), registered.fill)
+		}
+
+		_, _ = fmt.Fprintf(dot, "  %d [label=%s %s];\n", node.id, dotQuote(t.equipment[node.equipmentId].name), attributes)
+	}
+
+	for _, edge := range t.edges {
+		typeId, state := t.edgeState(edge)
+
+		var attributes string
+		if state == SwitchStateOpen {
+			attributes = dotAttributesStateOff
+		}
+
+		if registered := registeredEquipmentType(typeId); registered.edgeGraphics() {
+			if state == SwitchStateClose {
+				attributes = fmt.Sprintf("color=\"%s\"", registered.fill)
+			} else {
+				attributes = fmt.Sprintf("style=dashed color=\"%s\"", registered.fill)
+			}
+		}
+
+		_, _ = fmt.Fprintf(dot, "  %d -- %d [label=%s %s];\n",
+			edge.terminal.node1Id, edge.terminal.node2Id, dotQuote(t.equipment[edge.equipmentId].name), attributes)
+	}
+
+	_, _ = dot.WriteString("}\n")
+}
+
+// dotQuote returns a DOT quoted string with escaped quotes, backslashes and line breaks
+func dotQuote(s string) string {
+	var quoted strings.Builder
+
+	quoted.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			quoted.WriteString("\\\"")
+		case '\\':
+			quoted.WriteString("\\\\")
+		case '\n':
+			quoted.WriteString("\\n")
+		case '\r':
+		default:
+			quoted.WriteRune(r)
+		}
+	}
+	quoted.WriteByte('"')
+
+	return quoted.String()
+}
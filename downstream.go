@@ -0,0 +1,232 @@
+package topogrid
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yourbasic/graph"
+)
+
+// EquipmentDownstreamOfSwitch returns a sorted array of equipment ids that would lose supply if the switch edge opened,
+// including the switch itself if one of its terminals would be dead. Reachability from the power nodes is recalculated
+// on a copy of the current topology graph, so the topology is not changed
+func (t *TopologyGridStruct) EquipmentDownstreamOfSwitch(edgeId int) ([]int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.equipmentDownstreamOfSwitch(edgeId)
+}
+
+func (t *TopologyGridStruct) equipmentDownstreamOfSwitch(edgeId int) ([]int, error) {
+	edgeIdx, exists := t.edgeIdxFromEdgeId[edgeId]
+	if !exists {
+		return nil, edgeNotFound(edgeId)
+	}
+
+	edge := t.edges[edgeIdx]
+
+	if typeId, _ := t.edgeState(edge); !isSwitchType(typeId) {
+		return nil, &IdError{Err: ErrEquipmentIsNotSwitch, Id: edge.equipmentId}
+	}
+
+	downstream := make([]int, 0)
+
+	if !t.isEdgeClosed(edge) || t.hasParallelClosedEdge(edge) {
+		return downstream, nil
+	}
+
+	node1Idx, existsNode1 := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+	node2Idx, existsNode2 := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+	if !existsNode1 || !existsNode2 {
+		return downstream, nil
+	}
+
+	scratchGraph := graph.Copy(t.currentGraph)
+	scratchGraph.DeleteBoth(node1Idx, node2Idx)
+
+	energizedAfter := t.energizedEquipmentIds(scratchGraph)
+
+	for equipmentId := range t.energizedEquipmentIds(t.currentGraph) {
+		if !energizedAfter[equipmentId] {
+			downstream = append(downstream, equipmentId)
+		}
+	}
+
+	sort.Ints(downstream)
+
+	return downstream, nil
+}
+
+// IsSafeToOpenDisconnector returns false with a reason if the disconnect switch is under load: opening it would isolate
+// an energized consumer, or both its terminals would stay energized, i.e. it closes a live loop or parallels power
+// sources. The check is done on a copy of the current topology graph, so the topology is not changed
+func (t *TopologyGridStruct) IsSafeToOpenDisconnector(equipmentId int) (bool, string, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	equipment, exists := t.equipment[equipmentId]
+	if !exists {
+		return false, "", equipmentNotFound(equipmentId)
+	}
+
+	if equipment.typeId != TypeDisconnectSwitch {
+		return false, "", &IdError{Err: ErrEquipmentIsNotDisconnector, Id: equipmentId}
+	}
+
+	edgeIdArray := t.edgeIdArrayFromEquipmentId[equipmentId]
+	if len(edgeIdArray) == 0 {
+		return false, "", &IdError{Err: ErrEquipmentHasNoEdges, Id: equipmentId}
+	}
+
+	scratchGraph := graph.Copy(t.currentGraph)
+	reachableBefore := t.reachableFromPower(t.currentGraph)
+	isEnergized := false
+
+	for _, edgeId := range edgeIdArray {
+		edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+
+		node1Idx, existsNode1 := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx, existsNode2 := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+		if !existsNode1 || !existsNode2 || !t.isEdgeClosed(edge) {
+			continue
+		}
+
+		isEnergized = isEnergized || reachableBefore[node1Idx]
+
+		if !t.hasParallelClosedEdge(edge) {
+			scratchGraph.DeleteBoth(node1Idx, node2Idx)
+		}
+	}
+
+	if !isEnergized {
+		return true, "", nil
+	}
+
+	energizedAfter := t.energizedEquipmentIds(scratchGraph)
+	isolatedConsumers := make([]int, 0)
+
+	for consumerId := range t.energizedEquipmentIds(t.currentGraph) {
+		if t.equipment[consumerId].typeId == TypeConsumer && !energizedAfter[consumerId] {
+			isolatedConsumers = append(isolatedConsumers, consumerId)
+		}
+	}
+
+	if len(isolatedConsumers) != 0 {
+		sort.Ints(isolatedConsumers)
+		return false, fmt.Sprintf("opening isolates energized consumers %v", isolatedConsumers), nil
+	}
+
+	reachableAfter := t.reachableFromPower(scratchGraph)
+
+	for _, edgeId := range edgeIdArray {
+		edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+		if !t.isEdgeClosed(edge) {
+			continue
+		}
+
+		if reachableAfter[t.nodeIdxFromNodeId[edge.terminal.node1Id]] && reachableAfter[t.nodeIdxFromNodeId[edge.terminal.node2Id]] {
+			return false, "opening breaks a live loop or a parallel of power sources", nil
+		}
+	}
+
+	return true, "", nil
+}
+
+// hasParallelClosedEdge returns true if another closed edge connects the same two nodes as the edge
+func (t *TopologyGridStruct) hasParallelClosedEdge(edge EdgeStruct) bool {
+	for _, edgeId := range t.edgeIdsBetweenNodes(edge.terminal.node1Id, edge.terminal.node2Id) {
+		if parallel := t.edges[t.edgeIdxFromEdgeId[edgeId]]; parallel.id != edge.id && t.isEdgeClosed(parallel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reachableFromPower returns node indexes reachable from the power nodes in the topology graph
+func (t *TopologyGridStruct) reachableFromPower(g graph.Iterator) []bool {
+	reachable := make([]bool, g.Order())
+
+	for _, nodeIdOfPowerNode := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		nodeIdx, exists := t.nodeIdxFromNodeId[nodeIdOfPowerNode]
+		if !exists || reachable[nodeIdx] {
+			continue
+		}
+
+		reachable[nodeIdx] = true
+		graph.BFS(g, nodeIdx, func(v, w int, c int64) {
+			reachable[w] = true
+		})
+	}
+
+	return reachable
+}
+
+// energizedEquipmentIds returns equipment ids energized in the topology graph by the rules of SetEquipmentElectricalState:
+// arcs of equipment out of service are not traversed from the power nodes, equipment out of service is not energized,
+// and equipment of edges is energized only if the edge is closed in the graph and a terminal is energized,
+// or both terminals are energized
+func (t *TopologyGridStruct) energizedEquipmentIds(g *graph.Mutable) map[int]bool {
+	isPowered := make(map[int]bool)
+	isNodeEnergized := make([]bool, g.Order())
+	blockedArcs := t.outOfServiceArcs()
+
+	for _, nodeIdOfPowerNode := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		nodeIdx, exists := t.nodeIdxFromNodeId[nodeIdOfPowerNode]
+		if !exists || isNodeEnergized[nodeIdx] {
+			continue
+		}
+
+		isNodeEnergized[nodeIdx] = true
+		sortedBFS(g, nodeIdx, blockedArcs, func(v, w int, c int64) {
+			isNodeEnergized[w] = true
+			t.addEquipmentIdsOfNode(isPowered, t.nodes[v])
+			t.addEquipmentIdsOfNode(isPowered, t.nodes[w])
+		})
+	}
+
+	energized := make(map[int]bool)
+
+	for equipmentId := range isPowered {
+		if t.equipment[equipmentId].outOfService {
+			continue
+		}
+
+		for _, nodeId := range t.nodeIdArrayFromEquipmentId[equipmentId] {
+			if isNodeEnergized[t.nodeIdxFromNodeId[nodeId]] {
+				energized[equipmentId] = true
+			}
+		}
+
+		for _, edgeId := range t.edgeIdArrayFromEquipmentId[equipmentId] {
+			edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+
+			node1Idx, existsNode1 := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+			node2Idx, existsNode2 := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+			if !existsNode1 || !existsNode2 {
+				continue
+			}
+
+			isClosed := t.isEdgeClosed(edge) && g.Edge(node1Idx, node2Idx)
+
+			if isNodeEnergized[node1Idx] && isNodeEnergized[node2Idx] || isClosed && (isNodeEnergized[node1Idx] || isNodeEnergized[node2Idx]) {
+				energized[equipmentId] = true
+			}
+		}
+	}
+
+	return energized
+}
+
+// addEquipmentIdsOfNode adds ids of the node equipment and of the equipment of all edges connected to the node
+func (t *TopologyGridStruct) addEquipmentIdsOfNode(equipmentIds map[int]bool, node NodeStruct) {
+	if node.equipmentId != 0 {
+		equipmentIds[node.equipmentId] = true
+	}
+
+	for _, edgeId := range t.edgeIdArrayFromNodeId[node.id] {
+		if equipmentId := t.edges[t.edgeIdxFromEdgeId[edgeId]].equipmentId; equipmentId != 0 {
+			equipmentIds[equipmentId] = true
+		}
+	}
+}
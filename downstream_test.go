@@ -0,0 +1,95 @@
+package topogrid
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// energizedEquipmentIdsAfterFullRecompute returns sorted equipment ids energized by SetEquipmentElectricalState
+func energizedEquipmentIdsAfterFullRecompute(t testing.TB, topology *TopologyGridStruct) []int {
+	t.Helper()
+
+	topology.SetEquipmentElectricalState()
+
+	energized := make([]int, 0)
+	for _, equipmentId := range topology.EquipmentIds() {
+		state, err := topology.EquipmentElectricalStateById(equipmentId)
+		mustSucceed(t, err)
+
+		if IsEnergized(state) {
+			energized = append(energized, equipmentId)
+		}
+	}
+
+	return energized
+}
+
+func TestEquipmentDownstreamOfSwitchOpenBreakerWithOneLiveTerminal(t *testing.T) {
+	topology := newTestGrid(t)
+
+	downstream, err := topology.EquipmentDownstreamOfSwitch(20)
+	mustSucceed(t, err)
+
+	// The open CB50 stays connected to the live node 6 but loses supply with C1, DS20 keeps only one live terminal
+	if want := []int{103, 104, 120, 130, 150}; !slices.Equal(downstream, want) {
+		t.Fatalf("downstream of DS20 %v, want %v", downstream, want)
+	}
+
+	mustSucceed(t, topology.SetEquipmentOutOfService(140, true))
+
+	downstream, err = topology.EquipmentDownstreamOfSwitch(20)
+	mustSucceed(t, err)
+
+	// P2 is blocked by the breaker out of service, so the open CB50 has one live terminal before opening DS20
+	if want := []int{103, 104, 120, 130}; !slices.Equal(downstream, want) {
+		t.Fatalf("downstream of DS20 with CB40 out of service %v, want %v", downstream, want)
+	}
+
+	downstream, err = topology.EquipmentDownstreamOfSwitch(40)
+	mustSucceed(t, err)
+
+	if len(downstream) != 0 {
+		t.Fatalf("downstream of CB40 out of service %v, want none", downstream)
+	}
+}
+
+func TestEquipmentDownstreamOfSwitchMatchesElectricalState(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		topology := newRandomGrid(t, r)
+
+		equipmentIds := switchEquipmentIds(topology)
+		for i := r.Intn(3); i > 0; i-- {
+			mustSucceed(t, topology.SetEquipmentOutOfService(equipmentIds[r.Intn(len(equipmentIds))], true))
+		}
+
+		energizedBefore := energizedEquipmentIdsAfterFullRecompute(t, topology.Clone())
+
+		for _, edgeId := range topology.EdgeIds() {
+			edge := topology.edges[topology.edgeIdxFromEdgeId[edgeId]]
+			if typeId, state := topology.edgeState(edge); !isSwitchType(typeId) || state != SwitchStateClose ||
+				len(topology.EdgeIdsByEquipmentId(edge.equipmentId)) != 1 {
+				continue
+			}
+
+			downstream, err := topology.EquipmentDownstreamOfSwitch(edgeId)
+			mustSucceed(t, err)
+
+			opened := topology.Clone()
+			mustSucceed(t, opened.SetSwitchState(edge.equipmentId, SwitchStateOpen))
+			energizedAfter := energizedEquipmentIdsAfterFullRecompute(t, opened)
+
+			want := make([]int, 0)
+			for _, equipmentId := range energizedBefore {
+				if !slices.Contains(energizedAfter, equipmentId) {
+					want = append(want, equipmentId)
+				}
+			}
+
+			if !slices.Equal(downstream, want) {
+				t.Fatalf("seed %d: downstream of edge %d %v, want %v", seed, edgeId, downstream, want)
+			}
+		}
+	}
+}
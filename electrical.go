@@ -0,0 +1,507 @@
+package topogrid
+
+import (
+	"maps"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/yourbasic/graph"
+)
+
+// EquipmentStateChange is a change of the equipment electrical state and of the power nodes it is powered by
+type EquipmentStateChange struct {
+	EquipmentId  int
+	OldState     uint8
+	NewState     uint8
+	OldPoweredBy map[int]int64 // PowerNodeId -> number of switches before the change
+	NewPoweredBy map[int]int64 // PowerNodeId -> number of switches after the change
+}
+
+// sourceReach is the part of the electrical state calculated from one power node
+type sourceReach struct {
+	powerNodeId  int
+	nodeIdxArray []int         // Indexes of energized nodes
+	poweredBy    map[int]int64 // EquipmentId -> number of switches between the power node and the equipment
+}
+
+// SetEquipmentElectricalStateWithDiff sets electrical states for all equipment like SetEquipmentElectricalState
+// and returns changes sorted by the equipment id
+func (t *TopologyGridStruct) SetEquipmentElectricalStateWithDiff() []EquipmentStateChange {
+	t.Lock()
+	changes := t.setEquipmentElectricalState()
+	t.Unlock()
+
+	t.notifyObservers(changes)
+
+	return changes
+}
+
+// setEquipmentElectricalState traverses the topology from every power node in parallel, each traversal only reads
+// the topology, then merges the results
+func (t *TopologyGridStruct) setEquipmentElectricalState() []EquipmentStateChange {
+	powerNodeIds := t.nodeIdArrayFromEquipmentTypeId[TypePower]
+	reaches := make([]sourceReach, len(powerNodeIds))
+	blockedArcs := t.outOfServiceArcs()
+
+	var wg sync.WaitGroup
+	next := make(chan int)
+
+	for worker := 0; worker < min(runtime.GOMAXPROCS(0), len(powerNodeIds)); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				reaches[i] = t.powerSourceReach(powerNodeIds[i], blockedArcs)
+			}
+		}()
+	}
+
+	for i := range powerNodeIds {
+		next <- i
+	}
+	close(next)
+
+	wg.Wait()
+
+	t.blockedArcs = blockedArcs
+
+	return t.applySourceReaches(reaches)
+}
+
+// powerSourceReach traverses the current topology graph from the power node skipping the blocked arcs.
+// Nodes reached are energized, as well as the equipment of these nodes and of all edges connected to them
+func (t *TopologyGridStruct) powerSourceReach(nodeIdOfPowerNode int, blockedArcs map[[2]int]bool) sourceReach {
+	reach := sourceReach{
+		powerNodeId:  nodeIdOfPowerNode,
+		nodeIdxArray: []int{t.nodeIdxFromNodeId[nodeIdOfPowerNode]},
+		poweredBy:    make(map[int]int64),
+	}
+
+	cost := make(map[int]int64)
+
+	sortedBFS(t.currentGraph, t.nodeIdxFromNodeId[nodeIdOfPowerNode], blockedArcs, func(v, w int, c int64) {
+		terminal := TerminalStruct{node1Id: t.nodes[v].id, node2Id: t.nodes[w].id, numberOfSwitches: c}
+
+		cost[terminal.node2Id] = terminal.numberOfSwitches + cost[terminal.node1Id]
+		reach.nodeIdxArray = append(reach.nodeIdxArray, w)
+
+		for _, nodeId := range []int{terminal.node1Id, terminal.node2Id} {
+			if node := t.nodes[t.nodeIdxFromNodeId[nodeId]]; node.equipmentId != 0 {
+				reach.poweredBy[node.equipmentId] = cost[nodeId]
+			}
+
+			for _, edgeId := range t.edgeIdArrayFromNodeId[nodeId] {
+				if edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]; edge.equipmentId != 0 {
+					reach.poweredBy[edge.equipmentId] = cost[nodeId]
+				}
+			}
+		}
+	})
+
+	return reach
+}
+
+// sortedBFS traverses the graph in breadth-first order visiting neighbours in ascending order,
+// so the traversal is the same as graph.BFS over graph.Sort(g) without sorting the whole graph.
+// Arcs in the blocked set, keyed by the ordered pair of vertices, are not traversed
+func sortedBFS(g *graph.Mutable, v int, blockedArcs map[[2]int]bool, do func(v, w int, c int64)) {
+	type arc struct {
+		w int
+		c int64
+	}
+
+	visited := map[int]bool{v: true}
+	arcs := make([]arc, 0)
+
+	for queue := []int{v}; len(queue) > 0; {
+		v := queue[0]
+		queue = queue[1:]
+
+		arcs = arcs[:0]
+		g.Visit(v, func(w int, c int64) bool {
+			if !blockedArcs[[2]int{min(v, w), max(v, w)}] {
+				arcs = append(arcs, arc{w: w, c: c})
+			}
+			return false
+		})
+		sort.Slice(arcs, func(i, j int) bool { return arcs[i].w < arcs[j].w })
+
+		for _, a := range arcs {
+			if visited[a.w] {
+				continue
+			}
+			do(v, a.w, a.c)
+			visited[a.w] = true
+			queue = append(queue, a.w)
+		}
+	}
+}
+
+// UpdateElectricalStateAfterSwitch sets the switch state and updates electrical states of the equipment
+// powered from the power nodes of the islands the switch connects, instead of recalculating the whole topology.
+// Falls back to SetEquipmentElectricalState if the topology has changed since the last calculation.
+// Returns changes sorted by the equipment id
+func (t *TopologyGridStruct) UpdateElectricalStateAfterSwitch(equipmentId int, newState int) ([]EquipmentStateChange, error) {
+	t.Lock()
+	changes, err := t.updateElectricalStateAfterSwitch(equipmentId, newState)
+	t.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	t.notifyObservers(changes)
+
+	return changes, nil
+}
+
+func (t *TopologyGridStruct) updateElectricalStateAfterSwitch(equipmentId int, newState int) ([]EquipmentStateChange, error) {
+	if err := t.checkSwitchState(equipmentId, newState); err != nil {
+		return nil, err
+	}
+
+	if t.sourceReaches == nil || t.reachVersion != t.graphVersion {
+		if err := t.setSwitchState(equipmentId, newState); err != nil {
+			return nil, err
+		}
+		return t.setEquipmentElectricalState(), nil
+	}
+
+	if t.equipment[equipmentId].switchState == newState {
+		return make([]EquipmentStateChange, 0), nil
+	}
+
+	// Islands are looked up while the switch is closed, so they cover both sides of the switch
+	var powerNodeIds, nodeIdxArray []int
+
+	if newState != SwitchStateClose {
+		powerNodeIds, nodeIdxArray = t.islandsOfEquipment(equipmentId)
+	}
+
+	if err := t.setSwitchState(equipmentId, newState); err != nil {
+		return nil, err
+	}
+
+	if newState == SwitchStateClose {
+		powerNodeIds, nodeIdxArray = t.islandsOfEquipment(equipmentId)
+	}
+
+	t.updateOutOfServiceArcs(equipmentId)
+
+	return t.updateSourceReaches(powerNodeIds, nodeIdxArray, equipmentId), nil
+}
+
+// islandsOfEquipment returns ids of power nodes and indexes of all nodes in the islands of the current topology graph
+// containing the equipment terminals
+func (t *TopologyGridStruct) islandsOfEquipment(equipmentId int) ([]int, []int) {
+	reached := make(map[int]bool)
+	nodeIdxArray := make([]int, 0)
+
+	for _, nodeIdx := range t.equipmentTerminalIdxArray(equipmentId) {
+		if reached[nodeIdx] {
+			continue
+		}
+
+		reached[nodeIdx] = true
+		nodeIdxArray = append(nodeIdxArray, nodeIdx)
+
+		graph.BFS(t.currentGraph, nodeIdx, func(v, w int, c int64) {
+			reached[w] = true
+			nodeIdxArray = append(nodeIdxArray, w)
+		})
+	}
+
+	powerNodeIds := make([]int, 0)
+	for _, nodeIdOfPowerNode := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		if reached[t.nodeIdxFromNodeId[nodeIdOfPowerNode]] {
+			powerNodeIds = append(powerNodeIds, nodeIdOfPowerNode)
+		}
+	}
+
+	return powerNodeIds, nodeIdxArray
+}
+
+// updateSourceReaches recalculates electrical states from the power nodes in the islands of the nodes.
+// Nodes reached from these power nodes are not reached from others, so only the nodes of the islands
+// and the equipment reached before or after the recalculation are updated, as well as the switched equipment
+func (t *TopologyGridStruct) updateSourceReaches(powerNodeIds []int, nodeIdxArray []int, switchEquipmentId int) []EquipmentStateChange {
+	isUpdated := make(map[int]bool, len(powerNodeIds))
+	equipmentIds := map[int]bool{switchEquipmentId: true}
+	reaches := make([]sourceReach, 0, len(powerNodeIds))
+	blockedArcs := t.blockedArcs
+
+	for _, nodeIdOfPowerNode := range powerNodeIds {
+		isUpdated[nodeIdOfPowerNode] = true
+
+		for _, nodeIdx := range t.sourceReaches[nodeIdOfPowerNode].nodeIdxArray {
+			t.nodes[nodeIdx].electricalState &^= StateEnergized
+		}
+
+		for equipmentId := range t.sourceReaches[nodeIdOfPowerNode].poweredBy {
+			equipmentIds[equipmentId] = true
+		}
+
+		reaches = append(reaches, t.powerSourceReach(nodeIdOfPowerNode, blockedArcs))
+	}
+
+	for _, reach := range reaches {
+		for _, nodeIdx := range reach.nodeIdxArray {
+			t.nodes[nodeIdx].electricalState |= StateEnergized
+		}
+
+		for equipmentId := range reach.poweredBy {
+			equipmentIds[equipmentId] = true
+		}
+
+		t.sourceReaches[reach.powerNodeId] = reach
+	}
+
+	for _, nodeIdx := range t.propagateGrounding(nodeIdxArray) {
+		t.addEquipmentIdsOfNode(equipmentIds, t.nodes[nodeIdx])
+	}
+
+	for _, nodeIdx := range t.markConnectedDead(nodeIdxArray) {
+		t.addEquipmentIdsOfNode(equipmentIds, t.nodes[nodeIdx])
+	}
+
+	changes := make([]EquipmentStateChange, 0)
+
+	for equipmentId := range equipmentIds {
+		equipment, exists := t.equipment[equipmentId]
+		if !exists {
+			continue
+		}
+
+		poweredBy := make(map[int]int64, len(equipment.poweredBy))
+		for powerNodeId, numberOfSwitches := range equipment.poweredBy {
+			if !isUpdated[powerNodeId] {
+				poweredBy[powerNodeId] = numberOfSwitches
+			}
+		}
+
+		for _, reach := range reaches {
+			if numberOfSwitches, exists := reach.poweredBy[equipmentId]; exists {
+				poweredBy[reach.powerNodeId] = numberOfSwitches
+			}
+		}
+
+		electricalState := t.equipmentElectricalState(equipmentId, poweredBy)
+
+		if equipment.electricalState != electricalState || !maps.Equal(equipment.poweredBy, poweredBy) {
+			changes = append(changes, EquipmentStateChange{
+				EquipmentId:  equipmentId,
+				OldState:     equipment.electricalState,
+				NewState:     electricalState,
+				OldPoweredBy: equipment.poweredBy,
+				NewPoweredBy: maps.Clone(poweredBy),
+			})
+		}
+
+		equipment.electricalState = electricalState
+		equipment.poweredBy = poweredBy
+		t.equipment[equipmentId] = equipment
+	}
+
+	t.reachVersion = t.graphVersion
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].EquipmentId < changes[j].EquipmentId })
+
+	return changes
+}
+
+// applySourceReaches merges electrical states calculated from every power node into nodes and equipment
+// and returns changes sorted by the equipment id
+func (t *TopologyGridStruct) applySourceReaches(reaches []sourceReach) []EquipmentStateChange {
+	for idx := range t.nodes {
+		t.nodes[idx].electricalState = StateIsolated
+	}
+
+	poweredByFromEquipmentId := make(map[int]map[int]int64)
+
+	for _, reach := range reaches {
+		for _, nodeIdx := range reach.nodeIdxArray {
+			t.nodes[nodeIdx].electricalState |= StateEnergized
+		}
+
+		for equipmentId, numberOfSwitches := range reach.poweredBy {
+			if poweredByFromEquipmentId[equipmentId] == nil {
+				poweredByFromEquipmentId[equipmentId] = make(map[int]int64)
+			}
+			poweredByFromEquipmentId[equipmentId][reach.powerNodeId] = numberOfSwitches
+		}
+	}
+
+	nodeIdxArray := make([]int, t.nodeIdx)
+	for nodeIdx := range nodeIdxArray {
+		nodeIdxArray[nodeIdx] = nodeIdx
+	}
+
+	t.propagateGrounding(nodeIdxArray)
+	t.markConnectedDead(nodeIdxArray)
+
+	t.sourceReaches = make(map[int]sourceReach, len(reaches))
+	for _, reach := range reaches {
+		t.sourceReaches[reach.powerNodeId] = reach
+	}
+	t.reachVersion = t.graphVersion
+
+	changes := make([]EquipmentStateChange, 0)
+
+	for id, equipment := range t.equipment {
+		poweredBy := poweredByFromEquipmentId[id]
+		if poweredBy == nil {
+			poweredBy = make(map[int]int64)
+		}
+
+		electricalState := t.equipmentElectricalState(id, poweredBy)
+
+		if equipment.electricalState != electricalState || !maps.Equal(equipment.poweredBy, poweredBy) {
+			changes = append(changes, EquipmentStateChange{
+				EquipmentId:  id,
+				OldState:     equipment.electricalState,
+				NewState:     electricalState,
+				OldPoweredBy: equipment.poweredBy,
+				NewPoweredBy: maps.Clone(poweredBy),
+			})
+		}
+
+		equipment.electricalState = electricalState
+		equipment.poweredBy = poweredBy
+		t.equipment[id] = equipment
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].EquipmentId < changes[j].EquipmentId })
+
+	return changes
+}
+
+// equipmentElectricalState returns the electrical state of the equipment powered by the power nodes.
+// Node states must be set before. Equipment of edges is energized only if one of its edges is closed
+// or both terminals of the edge are energized, e.g. an open switch with one live terminal is isolated.
+// The connected dead and the grounded states are resolved from the node states the same way
+func (t *TopologyGridStruct) equipmentElectricalState(equipmentId int, poweredBy map[int]int64) uint8 {
+	electricalState := StateIsolated
+
+	if t.equipment[equipmentId].outOfService {
+		electricalState |= StateOutOfService
+	} else if len(poweredBy) != 0 && t.equipmentHasState(equipmentId, StateEnergized) {
+		electricalState |= StateEnergized
+	} else if t.equipmentHasState(equipmentId, StateConnectedDead) {
+		electricalState |= StateConnectedDead
+	}
+
+	if t.equipmentHasState(equipmentId, StateGrounded) {
+		electricalState |= StateGrounded
+	}
+
+	if t.equipment[equipmentId].faulted {
+		electricalState |= StateFault
+	}
+
+	return electricalState
+}
+
+// markConnectedDead sets the connected dead state of the nodes that are not energized but have arcs in the current
+// topology graph, i.e. belong to an island without power sources. Node energized states must be set before.
+// Returns indexes of nodes whose connected dead state has changed
+func (t *TopologyGridStruct) markConnectedDead(nodeIdxArray []int) []int {
+	changed := make([]int, 0)
+
+	for _, nodeIdx := range nodeIdxArray {
+		state := t.nodes[nodeIdx].electricalState
+		isConnectedDead := !IsEnergized(state) && t.currentGraph.Degree(nodeIdx) != 0
+
+		if isConnectedDead == IsConnectedDead(state) {
+			continue
+		}
+
+		t.nodes[nodeIdx].electricalState ^= StateConnectedDead
+		changed = append(changed, nodeIdx)
+	}
+
+	return changed
+}
+
+// equipmentHasState returns true if a node of the equipment has the state, or an edge of the equipment
+// is closed and a terminal has the state, or both terminals of the edge have the state
+func (t *TopologyGridStruct) equipmentHasState(equipmentId int, state uint8) bool {
+	for _, nodeId := range t.nodeIdArrayFromEquipmentId[equipmentId] {
+		if t.nodeElectricalState(nodeId)&state == state {
+			return true
+		}
+	}
+
+	for _, edgeId := range t.edgeIdArrayFromEquipmentId[equipmentId] {
+		edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+
+		node1HasState := t.nodeElectricalState(edge.terminal.node1Id)&state == state
+		node2HasState := t.nodeElectricalState(edge.terminal.node2Id)&state == state
+
+		if node1HasState && node2HasState || t.isEdgeClosed(edge) && (node1HasState || node2HasState) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// outOfServiceArcs returns arcs of the current topology graph built from closed edges of equipment out of service only,
+// keyed by the ordered pair of node indexes
+func (t *TopologyGridStruct) outOfServiceArcs() map[[2]int]bool {
+	blockedArcs := make(map[[2]int]bool)
+
+	for equipmentId, equipment := range t.equipment {
+		if equipment.outOfService {
+			t.setOutOfServiceArcs(blockedArcs, equipmentId)
+		}
+	}
+
+	return blockedArcs
+}
+
+// updateOutOfServiceArcs updates the arcs of equipment out of service the source reaches were calculated with
+// after the switch state of the equipment has changed. Only arcs between the terminals of the equipment can change
+func (t *TopologyGridStruct) updateOutOfServiceArcs(equipmentId int) {
+	t.setOutOfServiceArcs(t.blockedArcs, equipmentId)
+}
+
+// setOutOfServiceArcs adds the arcs between the terminals of the equipment edges to the blocked arcs if all closed edges
+// between the terminals are of equipment out of service, and removes them otherwise
+func (t *TopologyGridStruct) setOutOfServiceArcs(blockedArcs map[[2]int]bool, equipmentId int) {
+	for _, edgeId := range t.edgeIdArrayFromEquipmentId[equipmentId] {
+		edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+
+		node1Idx, existsNode1 := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx, existsNode2 := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+		if !existsNode1 || !existsNode2 {
+			continue
+		}
+
+		arc := [2]int{min(node1Idx, node2Idx), max(node1Idx, node2Idx)}
+
+		if t.isArcOutOfService(edge.terminal.node1Id, edge.terminal.node2Id) {
+			blockedArcs[arc] = true
+		} else {
+			delete(blockedArcs, arc)
+		}
+	}
+}
+
+// isArcOutOfService returns true if edges between the nodes include edges of equipment out of service
+// and no closed edges of equipment in service
+func (t *TopologyGridStruct) isArcOutOfService(node1Id int, node2Id int) bool {
+	outOfService := false
+
+	for _, edgeId := range t.edgeIdsBetweenNodes(node1Id, node2Id) {
+		edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+
+		if t.equipment[edge.equipmentId].outOfService {
+			outOfService = true
+		} else if t.isEdgeClosed(edge) {
+			return false
+		}
+	}
+
+	return outOfService
+}
@@ -0,0 +1,126 @@
+package topogrid
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// electricalSnapshot returns electrical states of all nodes and equipment with the power nodes the equipment is powered by
+func electricalSnapshot(t testing.TB, topology *TopologyGridStruct) string {
+	t.Helper()
+
+	var snapshot strings.Builder
+
+	for _, nodeId := range topology.NodeIds() {
+		state, err := topology.NodeElectricalState(nodeId)
+		mustSucceed(t, err)
+		_, _ = fmt.Fprintf(&snapshot, "node %d: %#02x\n", nodeId, state)
+	}
+
+	for _, equipmentId := range topology.EquipmentIds() {
+		state, err := topology.EquipmentElectricalStateById(equipmentId)
+		mustSucceed(t, err)
+
+		poweredBy, err := topology.EquipmentPoweredBy(equipmentId)
+		mustSucceed(t, err)
+
+		_, _ = fmt.Fprintf(&snapshot, "equipment %d: %#02x", equipmentId, state)
+		for _, powerNodeId := range slices.Sorted(maps.Keys(poweredBy)) {
+			_, _ = fmt.Fprintf(&snapshot, " %d/%d", powerNodeId, poweredBy[powerNodeId])
+		}
+		snapshot.WriteString("\n")
+	}
+
+	return snapshot.String()
+}
+
+func TestUpdateElectricalStateAfterSwitchMatchesFullRecompute(t *testing.T) {
+	states := []int{SwitchStateOpen, SwitchStateClose, SwitchStateUnknown}
+
+	for seed := int64(0); seed < 50; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		topology := newRandomGrid(t, r)
+		equipmentIds := switchEquipmentIds(topology)
+
+		topology.SetEquipmentElectricalState()
+
+		for step := 0; step < 40; step++ {
+			equipmentId := equipmentIds[r.Intn(len(equipmentIds))]
+
+			if r.Intn(10) == 0 {
+				// Out of service equipment invalidates the source reaches, the next update recalculates in full
+				mustSucceed(t, topology.SetEquipmentOutOfService(equipmentId, r.Intn(2) == 0))
+			}
+
+			_, err := topology.UpdateElectricalStateAfterSwitch(equipmentId, states[r.Intn(len(states))])
+			mustSucceed(t, err)
+
+			if topology.sourceReaches == nil || topology.reachVersion != topology.graphVersion {
+				t.Fatalf("seed %d step %d: source reaches are stale after the update", seed, step)
+			}
+
+			recomputed := topology.Clone()
+			recomputed.SetEquipmentElectricalState()
+
+			if got, want := electricalSnapshot(t, topology), electricalSnapshot(t, recomputed); got != want {
+				t.Fatalf("seed %d step %d: switching equipment %d\nincremental:\n%s\nfull recompute:\n%s", seed, step, equipmentId, got, want)
+			}
+		}
+	}
+}
+
+func TestUpdateElectricalStateAfterSwitchChanges(t *testing.T) {
+	topology := newTestGrid(t)
+	topology.SetEquipmentElectricalState()
+
+	changes, err := topology.UpdateElectricalStateAfterSwitch(120, SwitchStateOpen)
+	mustSucceed(t, err)
+
+	changed := make([]int, 0, len(changes))
+	for _, change := range changes {
+		changed = append(changed, change.EquipmentId)
+	}
+
+	// DS20, L1, CB30 and C1 lose power, the open CB50 between the live node 6 and C1 is not energized any more
+	if want := []int{103, 104, 120, 130, 150}; !slices.Equal(changed, want) {
+		t.Fatalf("changed equipment %v, want %v", changed, want)
+	}
+
+	if _, err := topology.UpdateElectricalStateAfterSwitch(999, SwitchStateOpen); err == nil {
+		t.Fatal("switching unknown equipment succeeded")
+	}
+}
+
+func BenchmarkSetEquipmentElectricalState(b *testing.B) {
+	topology := newLargeGrid(b, 50000, 14)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		topology.SetEquipmentElectricalState()
+	}
+}
+
+func BenchmarkUpdateElectricalStateAfterSwitch(b *testing.B) {
+	topology := newLargeGrid(b, 50000, 14)
+	topology.SetEquipmentElectricalState()
+
+	breakers := make([]int, 0)
+	for _, equipmentId := range topology.EquipmentIdsByType(TypeCircuitBreaker) {
+		if state, _ := topology.EquipmentSwitchStateByEquipmentId(equipmentId); state == SwitchStateClose {
+			breakers = append(breakers, equipmentId)
+		}
+	}
+	states := []int{SwitchStateOpen, SwitchStateClose}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Every breaker is opened and closed again, so every update changes the switch state
+		if _, err := topology.UpdateElectricalStateAfterSwitch(breakers[i/2%len(breakers)], states[i%2]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
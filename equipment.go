@@ -1,14 +1,41 @@
 package topogrid
 
-// Equipment electrical states
+import (
+	"sort"
+)
+
+// Equipment and node electrical states. A state is a combination of bits, StateIsolated means no bits are set
 const (
-	StateIsolated    uint8 = 0x00
-	StateEnergized   uint8 = 0x01
-	StateGrounded    uint8 = 0x02
-	StateOvercurrent uint8 = 0x04
-	StateFault       uint8 = 0x08
+	StateIsolated      uint8 = 0x00 // Not connected to a power source nor to other equipment in the current topology
+	StateEnergized     uint8 = 0x01 // Connected to a power source in the current topology
+	StateGrounded      uint8 = 0x02 // Connected to a ground or to a closed ground switch
+	StateOvercurrent   uint8 = 0x04 // Reserved for overcurrent indication
+	StateFault         uint8 = 0x08 // Faulted by SetEquipmentFault
+	StateOutOfService  uint8 = 0x10 // Taken out of service by SetEquipmentOutOfService, set instead of StateEnergized
+	StateConnectedDead uint8 = 0x20 // Connected to other equipment in the current topology, but not to a power source
 )
 
+// IsEnergized returns true if the electrical state includes StateEnergized
+func IsEnergized(state uint8) bool {
+	return state&StateEnergized == StateEnergized
+}
+
+// IsConnectedDead returns true if the electrical state includes StateConnectedDead
+func IsConnectedDead(state uint8) bool {
+	return state&StateConnectedDead == StateConnectedDead
+}
+
+// IsIsolated returns true if the electrical state includes neither StateEnergized nor StateConnectedDead,
+// i.e. the equipment is cut off by open switches from everything else
+func IsIsolated(state uint8) bool {
+	return state&(StateEnergized|StateConnectedDead) == 0
+}
+
+// IsGrounded returns true if the electrical state includes StateGrounded
+func IsGrounded(state uint8) bool {
+	return state&StateGrounded == StateGrounded
+}
+
 // Equipment Types
 const (
 	TypeAllEquipment     = 0
@@ -18,4 +45,202 @@ const (
 	TypeConsumer         = 4
 	TypeGround           = 5
 	TypeLine             = 6
+	TypeGroundSwitch     = 7
+	TypeTransformer      = 8
+	TypeFuse             = 9
 )
+
+// isSwitchType returns true if the equipment type can change the switch state
+func isSwitchType(typeId int) bool {
+	return typeId == TypeCircuitBreaker || typeId == TypeDisconnectSwitch || typeId == TypeGroundSwitch
+}
+
+// Equipment is a copy of everything the topology knows about an equipment
+type Equipment struct {
+	Id              int
+	TypeId          int
+	Name            string
+	SwitchState     int
+	ElectricalState uint8
+	Priority        int
+	LoadKw          float64
+	NodeIds         []int         // Sorted ids of the equipment nodes and of the terminals of its edges, see EquipmentTerminalNodeIds
+	PoweredBy       map[int]int64 // PowerNodeId -> number of switches, see SetEquipmentElectricalState
+}
+
+// GetEquipment returns a copy of the equipment or ErrEquipmentNotFound if there is no such equipment
+func (t *TopologyGridStruct) GetEquipment(equipmentId int) (Equipment, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	if _, exists := t.equipment[equipmentId]; !exists {
+		return Equipment{}, equipmentNotFound(equipmentId)
+	}
+
+	return t.equipmentCopy(equipmentId), nil
+}
+
+// AllEquipment returns copies of all equipment sorted by id
+func (t *TopologyGridStruct) AllEquipment() []Equipment {
+	t.RLock()
+	defer t.RUnlock()
+
+	equipmentIds := make([]int, 0, len(t.equipment))
+	for id := range t.equipment {
+		equipmentIds = append(equipmentIds, id)
+	}
+	sort.Ints(equipmentIds)
+
+	equipment := make([]Equipment, 0, len(equipmentIds))
+	for _, id := range equipmentIds {
+		equipment = append(equipment, t.equipmentCopy(id))
+	}
+
+	return equipment
+}
+
+// SetEquipmentName sets the name of the equipment
+func (t *TopologyGridStruct) SetEquipmentName(equipmentId int, name string) error {
+	t.Lock()
+	defer t.Unlock()
+
+	equipment, exists := t.equipment[equipmentId]
+	if !exists {
+		return equipmentNotFound(equipmentId)
+	}
+
+	equipment.name = name
+	t.equipment[equipmentId] = equipment
+	t.recordChange(ChangeEquipment, equipmentId)
+
+	return nil
+}
+
+// SetEquipmentType sets the type of the equipment, e.g. a manual disconnector replaced by a circuit breaker.
+// Nodes and edges of the equipment are moved to the new type in the lookups by type, and the arcs of the equipment
+// edges are rebuilt, so costs of circuit breakers and fuses in the topology graphs follow the new type
+func (t *TopologyGridStruct) SetEquipmentType(equipmentId int, typeId int) error {
+	t.Lock()
+	defer t.Unlock()
+
+	equipment, exists := t.equipment[equipmentId]
+	if !exists {
+		return equipmentNotFound(equipmentId)
+	}
+
+	if equipment.typeId == typeId {
+		return nil
+	}
+
+	equipment.typeId = typeId
+	t.equipment[equipmentId] = equipment
+
+	for _, nodeId := range t.nodeIdArrayFromEquipmentId[equipmentId] {
+		node := &t.nodes[t.nodeIdxFromNodeId[nodeId]]
+
+		removeIdFromArrayMap(t.nodeIdArrayFromEquipmentTypeId, node.typeId, nodeId)
+		t.nodeIdArrayFromEquipmentTypeId[typeId] = append(t.nodeIdArrayFromEquipmentTypeId[typeId], nodeId)
+		node.typeId = typeId
+	}
+
+	for _, edgeId := range t.edgeIdArrayFromEquipmentId[equipmentId] {
+		edge := &t.edges[t.edgeIdxFromEdgeId[edgeId]]
+
+		removeIdFromArrayMap(t.edgeIdArrayFromEquipmentTypeId, edge.typeId, edgeId)
+		t.edgeIdArrayFromEquipmentTypeId[typeId] = append(t.edgeIdArrayFromEquipmentTypeId[typeId], edgeId)
+		edge.typeId = typeId
+
+		t.updateArcs(edge.terminal.node1Id, edge.terminal.node2Id)
+	}
+
+	t.graphVersion++
+	t.recordChange(ChangeEquipment, equipmentId)
+
+	return nil
+}
+
+// SetEquipmentPriority sets the restoration priority of the equipment, e.g. hospitals and pumping stations.
+// Equipment is added with priority 0
+func (t *TopologyGridStruct) SetEquipmentPriority(equipmentId int, priority int) error {
+	t.Lock()
+	defer t.Unlock()
+
+	equipment, exists := t.equipment[equipmentId]
+	if !exists {
+		return equipmentNotFound(equipmentId)
+	}
+
+	equipment.priority = priority
+	t.equipment[equipmentId] = equipment
+	t.recordChange(ChangeEquipment, equipmentId)
+
+	return nil
+}
+
+// EquipmentPriority returns the restoration priority of the equipment, see SetEquipmentPriority
+func (t *TopologyGridStruct) EquipmentPriority(equipmentId int) (int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	equipment, exists := t.equipment[equipmentId]
+	if !exists {
+		return 0, equipmentNotFound(equipmentId)
+	}
+
+	return equipment.priority, nil
+}
+
+// EquipmentTerminalNodeIds returns sorted distinct node ids the equipment is connected to.
+// For equipment added with nodes these are the nodes themselves, for equipment added with edges
+// these are the terminals of its edges. Returns an empty array if there is no such equipment
+func (t *TopologyGridStruct) EquipmentTerminalNodeIds(equipmentId int) []int {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIds := t.equipmentTerminalNodeIds(equipmentId)
+	sort.Ints(nodeIds)
+
+	return nodeIds
+}
+
+// equipmentTerminalNodeIds returns distinct ids of the equipment nodes followed by the terminals of the equipment edges
+// in the order they were added
+func (t *TopologyGridStruct) equipmentTerminalNodeIds(equipmentId int) []int {
+	nodeIds := make([]int, 0, len(t.nodeIdArrayFromEquipmentId[equipmentId])+len(t.terminalNodeIdsFromEdgeEquipmentId[equipmentId]))
+	seen := make(map[int]bool)
+
+	for _, nodeIdArray := range [][]int{t.nodeIdArrayFromEquipmentId[equipmentId], t.terminalNodeIdsFromEdgeEquipmentId[equipmentId]} {
+		for _, nodeId := range nodeIdArray {
+			if !seen[nodeId] {
+				seen[nodeId] = true
+				nodeIds = append(nodeIds, nodeId)
+			}
+		}
+	}
+
+	return nodeIds
+}
+
+func (t *TopologyGridStruct) equipmentCopy(equipmentId int) Equipment {
+	equipment := t.equipment[equipmentId]
+
+	nodeIds := t.equipmentTerminalNodeIds(equipmentId)
+	sort.Ints(nodeIds)
+
+	poweredBy := make(map[int]int64, len(equipment.poweredBy))
+	for powerNodeId, numberOfSwitches := range equipment.poweredBy {
+		poweredBy[powerNodeId] = numberOfSwitches
+	}
+
+	return Equipment{
+		Id:              equipment.id,
+		TypeId:          equipment.typeId,
+		Name:            equipment.name,
+		SwitchState:     equipment.switchState,
+		ElectricalState: equipment.electricalState,
+		Priority:        equipment.priority,
+		LoadKw:          equipment.loadKw,
+		NodeIds:         nodeIds,
+		PoweredBy:       poweredBy,
+	}
+}
@@ -0,0 +1,108 @@
+package topogrid
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSetEquipmentTypeMovesNodesAndEdges(t *testing.T) {
+	topology := newTestGrid(t)
+
+	mustSucceed(t, topology.SetEquipmentType(120, TypeCircuitBreaker))
+	mustSucceed(t, topology.SetEquipmentType(103, TypeConsumer))
+
+	for _, test := range []struct {
+		name     string
+		got      []int
+		expected []int
+	}{
+		{"edges of disconnect switches", topology.EdgeIdsByType(TypeDisconnectSwitch), []int{}},
+		{"edges of circuit breakers", topology.EdgeIdsByType(TypeCircuitBreaker), []int{10, 20, 30, 40, 50}},
+		{"nodes of lines", topology.NodeIdsByType(TypeLine), []int{}},
+		{"nodes of consumers", topology.NodeIdsByType(TypeConsumer), []int{3, 4}},
+		{"equipment of circuit breakers", topology.EquipmentIdsByType(TypeCircuitBreaker), []int{110, 120, 130, 140, 150}},
+	} {
+		if !slices.Equal(test.got, test.expected) {
+			t.Errorf("%s %v, want %v", test.name, test.got, test.expected)
+		}
+	}
+
+	if edgeIds := topology.edgeIdArrayFromEquipmentTypeId[TypeDisconnectSwitch]; len(edgeIds) != 0 {
+		t.Errorf("edge ids %v are left under the old type", edgeIds)
+	}
+
+	if nodeIds := topology.nodeIdArrayFromEquipmentTypeId[TypeLine]; len(nodeIds) != 0 {
+		t.Errorf("node ids %v are left under the old type", nodeIds)
+	}
+
+	if typeId, _ := topology.edgeState(topology.edges[topology.edgeIdxFromEdgeId[20]]); typeId != TypeCircuitBreaker {
+		t.Errorf("edge 20 has the type %d", typeId)
+	}
+
+	if err := topology.SetEquipmentType(999, TypeCircuitBreaker); !errors.Is(err, ErrEquipmentNotFound) {
+		t.Errorf("setting the type of unknown equipment returns %v", err)
+	}
+}
+
+func TestSetEquipmentTypeChangesArcCosts(t *testing.T) {
+	topology := newTestGrid(t)
+	topology.SetEquipmentElectricalState()
+
+	node2Idx, node3Idx := topology.nodeIdxFromNodeId[2], topology.nodeIdxFromNodeId[3]
+
+	for _, g := range []struct {
+		name string
+		cost int64
+	}{
+		{"current", topology.currentGraph.Cost(node2Idx, node3Idx)},
+		{"full", topology.fullGraph.Cost(node2Idx, node3Idx)},
+	} {
+		if g.cost != 0 {
+			t.Fatalf("disconnect switch costs %d in the %s graph", g.cost, g.name)
+		}
+	}
+
+	version := topology.graphVersion
+
+	mustSucceed(t, topology.SetEquipmentType(120, TypeCircuitBreaker))
+
+	if topology.graphVersion == version {
+		t.Fatal("graph version is not changed")
+	}
+
+	for _, g := range []struct {
+		name string
+		cost int64
+	}{
+		{"current", topology.currentGraph.Cost(node2Idx, node3Idx)},
+		{"full", topology.fullGraph.Cost(node2Idx, node3Idx)},
+	} {
+		if g.cost != 1 {
+			t.Fatalf("disconnect switch turned into a circuit breaker costs %d in the %s graph", g.cost, g.name)
+		}
+	}
+
+	switches, err := topology.SwitchDistance(1, 4, false)
+	mustSucceed(t, err)
+
+	if switches != 3 {
+		t.Fatalf("%d circuit breakers between P1 and C1, want 3", switches)
+	}
+
+	topology.SetEquipmentElectricalState()
+
+	poweredBy, err := topology.EquipmentPoweredBy(104)
+	mustSucceed(t, err)
+
+	if poweredBy[1] != 3 {
+		t.Fatalf("C1 is powered by P1 through %d circuit breakers, want 3", poweredBy[1])
+	}
+
+	// Back to a disconnect switch the arc is free again
+	mustSucceed(t, topology.SetEquipmentType(120, TypeDisconnectSwitch))
+
+	if cost := topology.currentGraph.Cost(node2Idx, node3Idx); cost != 0 {
+		t.Fatalf("disconnect switch costs %d in the current graph", cost)
+	}
+}
@@ -0,0 +1,86 @@
+package topogrid
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrBothAreEnergized = errors.New("both segments are already energized")
+var ErrEnergizedWillBeGrounded = errors.New("energized segment will be grounded")
+var ErrSwitchIsAlreadyClosed = errors.New("switch is already closed")
+var ErrEquipmentNotFound = errors.New("equipment not found")
+var ErrNodeNotFound = errors.New("node not found")
+var ErrEdgeNotFound = errors.New("edge not found")
+var ErrEquipmentHasNoEdges = errors.New("equipment has no edges")
+var ErrEquipmentIsNotSwitch = errors.New("equipment is not a switch")
+var ErrEquipmentIsNotDisconnector = errors.New("equipment is not a disconnect switch")
+var ErrNodeHasEdges = errors.New("node is referenced by edges")
+var ErrInvalidSwitchState = errors.New("invalid switch state")
+var ErrDuplicateNodeId = errors.New("duplicate node id")
+var ErrDuplicateEdgeId = errors.New("duplicate edge id")
+var ErrNoCoordinates = errors.New("node has no coordinates")
+var ErrNoPath = errors.New("no path")
+var ErrCannotBeIsolated = errors.New("equipment cannot be isolated by switches")
+var ErrCannotBeRestored = errors.New("consumer cannot be restored")
+var ErrLoopDetected = errors.New("loop detected")
+var ErrNodeHasNoEdges = errors.New("node without equipment has no edges")
+var ErrDuplicateEquipmentId = errors.New("equipment id is shared by a node and an edge")
+var ErrUnusedNodeSlots = errors.New("unused preallocated node slots")
+var ErrNegativeWeight = errors.New("negative edge weight")
+var ErrNegativeLength = errors.New("negative edge length")
+var ErrNoProtectiveDevice = errors.New("no protective device")
+var ErrSectionBoundary = errors.New("equipment is a section boundary")
+var ErrEquipmentIsNotConsumer = errors.New("equipment is not a consumer")
+var ErrNegativeLoad = errors.New("negative load")
+var ErrNoRing = errors.New("no ring with switches")
+var ErrNotInFeeder = errors.New("equipment is not fed by a feeder")
+var ErrMultiFed = errors.New("equipment is fed by several feeders")
+var ErrNodeIsPowerNode = errors.New("node is a power node")
+var ErrNoSwitchOnPath = errors.New("path without switches")
+var ErrNoPoweredEquipment = errors.New("no powered equipment")
+var ErrPointNotBound = errors.New("point is not bound to equipment")
+var ErrChangesTruncated = errors.New("changes are not kept anymore")
+var ErrUnsupportedFormat = errors.New("unsupported format")
+var ErrTxDone = errors.New("transaction is already committed or rolled back")
+
+// IdError wraps one of the package errors together with the offending node, edge or equipment id.
+// Use errors.Is to check the kind of error and errors.As to get the id
+type IdError struct {
+	Err error
+	Id  int
+}
+
+func (e *IdError) Error() string {
+	return fmt.Sprintf("%v: %d", e.Err, e.Id)
+}
+
+func (e *IdError) Unwrap() error {
+	return e.Err
+}
+
+// PathError wraps ErrNoPath, ErrNoRing or ErrNoSwitchOnPath together with the node ids at both ends of the missing path
+type PathError struct {
+	Err        error
+	FromNodeId int
+	ToNodeId   int
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("%v: from node %d to node %d", e.Err, e.FromNodeId, e.ToNodeId)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+func nodeNotFound(nodeId int) error {
+	return &IdError{Err: ErrNodeNotFound, Id: nodeId}
+}
+
+func edgeNotFound(edgeId int) error {
+	return &IdError{Err: ErrEdgeNotFound, Id: edgeId}
+}
+
+func equipmentNotFound(equipmentId int) error {
+	return &IdError{Err: ErrEquipmentNotFound, Id: equipmentId}
+}
@@ -0,0 +1,25 @@
+package topogrid
+
+import (
+	"bufio"
+	"io"
+)
+
+// exportWriter is the writer of exports: strings.Builder or bytes.Buffer for the GetAs functions
+// and bufio.Writer for the Write functions. Errors of bufio.Writer are sticky and returned by Flush
+type exportWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+// writeBuffered streams the export written by write into w through a bufio.Writer
+func writeBuffered(w io.Writer, write func(writer exportWriter) error) error {
+	writer := bufio.NewWriter(w)
+
+	if err := write(writer); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
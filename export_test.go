@@ -0,0 +1,126 @@
+package topogrid
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// export is a GetAs function with the corresponding Write function
+type export struct {
+	name  string
+	get   func(topology *TopologyGridStruct) ([]byte, error)
+	write func(topology *TopologyGridStruct, w io.Writer) error
+}
+
+// appendNewline appends the newline the JSON writers write after the document
+func appendNewline(get func(topology *TopologyGridStruct) ([]byte, error)) func(topology *TopologyGridStruct) ([]byte, error) {
+	return func(topology *TopologyGridStruct) ([]byte, error) {
+		output, err := get(topology)
+		return append(output, '\n'), err
+	}
+}
+
+var exports = []export{
+	{"GraphMl",
+		func(topology *TopologyGridStruct) ([]byte, error) { return []byte(topology.GetAsGraphMl()), nil },
+		func(topology *TopologyGridStruct, w io.Writer) error { return topology.WriteGraphMl(w) }},
+	{"GraphMlWithState",
+		func(topology *TopologyGridStruct) ([]byte, error) {
+			return []byte(topology.GetAsGraphMlWithState()), nil
+		},
+		func(topology *TopologyGridStruct, w io.Writer) error { return topology.WriteGraphMlWithState(w) }},
+	{"GraphMlByFeeder",
+		func(topology *TopologyGridStruct) ([]byte, error) {
+			return []byte(topology.GetAsGraphMlByFeeder()), nil
+		},
+		func(topology *TopologyGridStruct, w io.Writer) error { return topology.WriteGraphMlByFeeder(w) }},
+	{"GraphML",
+		func(topology *TopologyGridStruct) ([]byte, error) {
+			graphMl, err := topology.GetAsGraphML()
+			return []byte(graphMl), err
+		},
+		func(topology *TopologyGridStruct, w io.Writer) error { return topology.WriteGraphML(w) }},
+	{"YedGraphML",
+		func(topology *TopologyGridStruct) ([]byte, error) { return []byte(topology.GetAsYedGraphML()), nil },
+		func(topology *TopologyGridStruct, w io.Writer) error { return topology.WriteYedGraphML(w) }},
+	{"Dot",
+		func(topology *TopologyGridStruct) ([]byte, error) { return []byte(topology.GetAsDot()), nil },
+		func(topology *TopologyGridStruct, w io.Writer) error { return topology.WriteDot(w) }},
+	{"GeoJSON",
+		appendNewline((*TopologyGridStruct).GetAsGeoJSON),
+		func(topology *TopologyGridStruct, w io.Writer) error { return topology.WriteGeoJSON(w) }},
+	{"NodeLinkJSON",
+		appendNewline((*TopologyGridStruct).GetAsNodeLinkJSON),
+		func(topology *TopologyGridStruct, w io.Writer) error { return topology.WriteNodeLinkJSON(w) }},
+	{"ElectricalStateJSON",
+		(*TopologyGridStruct).ElectricalStateJSON,
+		func(topology *TopologyGridStruct, w io.Writer) error { return topology.WriteElectricalStateJSON(w) }},
+}
+
+// setCoordinates sets coordinates of all nodes, so the topology can be exported as GeoJSON
+func setCoordinates(t testing.TB, topology *TopologyGridStruct) {
+	t.Helper()
+
+	for _, nodeId := range topology.NodeIds() {
+		mustSucceed(t, topology.SetNodeCoordinates(nodeId, float64(nodeId%100), float64(nodeId/100)))
+	}
+}
+
+func TestWriteMatchesGetAs(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		topology := newRandomGrid(t, r)
+		decorateRandomGrid(t, topology, r)
+		setCoordinates(t, topology)
+
+		for _, e := range exports {
+			want, err := e.get(topology)
+			mustSucceed(t, err)
+
+			var written bytes.Buffer
+			mustSucceed(t, e.write(topology, &written))
+
+			if !bytes.Equal(written.Bytes(), want) {
+				t.Fatalf("seed %d: Write%s differs from GetAs%s\n%s\nwant\n%s", seed, e.name, e.name, written.String(), want)
+			}
+		}
+	}
+}
+
+func TestWriteGeoJSONWithoutCoordinates(t *testing.T) {
+	topology := newTestGrid(t)
+	mustSucceed(t, topology.SetNodeCoordinates(1, 0, 0))
+
+	var written bytes.Buffer
+	if err := topology.WriteGeoJSON(&written); err == nil || written.Len() != 0 {
+		t.Fatalf("WriteGeoJSON returns %v and writes %q", err, written.String())
+	}
+}
+
+func BenchmarkExport(b *testing.B) {
+	topology := newLargeGrid(b, 50000, 14)
+	setCoordinates(b, topology)
+	topology.SetEquipmentElectricalState()
+
+	for _, e := range exports {
+		b.Run("GetAs"+e.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := e.get(topology); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run("Write"+e.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := e.write(topology, io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
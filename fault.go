@@ -0,0 +1,73 @@
+package topogrid
+
+// SetEquipmentFault sets or clears the fault of the equipment. Edges of faulted equipment are excluded from both
+// topology graphs, so path finding, power source queries and restoration never use them. StateFault is kept
+// in the equipment electrical state until the fault is cleared
+func (t *TopologyGridStruct) SetEquipmentFault(equipmentId int, faulted bool) error {
+	t.Lock()
+	defer t.Unlock()
+
+	equipment, exists := t.equipment[equipmentId]
+	if !exists {
+		return equipmentNotFound(equipmentId)
+	}
+
+	if equipment.faulted == faulted {
+		return nil
+	}
+
+	equipment.faulted = faulted
+	if faulted {
+		equipment.electricalState |= StateFault
+	} else {
+		equipment.electricalState &^= StateFault
+	}
+	t.equipment[equipmentId] = equipment
+	t.recordChange(ChangeEquipment, equipmentId)
+
+	for _, edgeId := range t.edgeIdArrayFromEquipmentId[equipmentId] {
+		edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+		t.updateArcs(edge.terminal.node1Id, edge.terminal.node2Id)
+	}
+
+	return nil
+}
+
+// IsEquipmentFaulted returns true if the equipment is faulted
+func (t *TopologyGridStruct) IsEquipmentFaulted(equipmentId int) (bool, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	equipment, exists := t.equipment[equipmentId]
+	if !exists {
+		return false, equipmentNotFound(equipmentId)
+	}
+
+	return equipment.faulted, nil
+}
+
+// SetEquipmentOutOfService declares the equipment out of service or returns it to service without changing
+// the topology and the switch states. SetEquipmentElectricalState does not traverse edges of equipment out of service
+// and sets StateOutOfService instead of StateEnergized, so such equipment can be told apart from isolated equipment
+func (t *TopologyGridStruct) SetEquipmentOutOfService(equipmentId int, outOfService bool) error {
+	t.Lock()
+	defer t.Unlock()
+
+	equipment, exists := t.equipment[equipmentId]
+	if !exists {
+		return equipmentNotFound(equipmentId)
+	}
+
+	if equipment.outOfService == outOfService {
+		return nil
+	}
+
+	equipment.outOfService = outOfService
+	t.equipment[equipmentId] = equipment
+	t.recordChange(ChangeEquipment, equipmentId)
+
+	// Electrical states calculated from the power nodes are stale now
+	t.sourceReaches = nil
+
+	return nil
+}
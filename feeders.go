@@ -0,0 +1,202 @@
+package topogrid
+
+import (
+	"sort"
+)
+
+// feederCache is the assignment of equipment to feeders of the current topology graph
+type feederCache struct {
+	version                uint64
+	headEdgeIds            []int         // Sorted edge ids of the feeder head circuit breakers
+	feedersFromEquipmentId map[int][]int // EquipmentId -> sorted edge ids of the feeder heads the equipment is fed by
+	feedersFromNodeIdx     [][]int       // NodeIdx -> sorted edge ids of the feeder heads the node is fed by
+}
+
+// ComputeFeeders returns equipment of every feeder: the edge id of the feeder head circuit breaker -> sorted equipment ids.
+// Feeder heads are the first closed circuit breakers on every path out of a power node in the current topology graph.
+// A feeder contains the head and everything reachable from it by closed edges away from the power node including
+// open switches at its end. Equipment reachable from several feeder heads, e.g. through a closed loop, is multi-fed
+// and is not assigned to any feeder, see MultiFedEquipment. Feeders are recalculated only when the topology changes
+func (t *TopologyGridStruct) ComputeFeeders() map[int][]int {
+	t.RLock()
+	defer t.RUnlock()
+
+	feeders := t.feederCache()
+
+	equipmentIdsFromHeadEdgeId := make(map[int][]int, len(feeders.headEdgeIds))
+	for _, headEdgeId := range feeders.headEdgeIds {
+		equipmentIdsFromHeadEdgeId[headEdgeId] = make([]int, 0)
+	}
+
+	for equipmentId, headEdgeIds := range feeders.feedersFromEquipmentId {
+		if len(headEdgeIds) == 1 {
+			equipmentIdsFromHeadEdgeId[headEdgeIds[0]] = append(equipmentIdsFromHeadEdgeId[headEdgeIds[0]], equipmentId)
+		}
+	}
+
+	for _, equipmentIds := range equipmentIdsFromHeadEdgeId {
+		sort.Ints(equipmentIds)
+	}
+
+	return equipmentIdsFromHeadEdgeId
+}
+
+// MultiFedEquipment returns sorted ids of equipment reachable from more than one feeder head, see ComputeFeeders
+func (t *TopologyGridStruct) MultiFedEquipment() []int {
+	t.RLock()
+	defer t.RUnlock()
+
+	equipmentIds := make([]int, 0)
+	for equipmentId, headEdgeIds := range t.feederCache().feedersFromEquipmentId {
+		if len(headEdgeIds) > 1 {
+			equipmentIds = append(equipmentIds, equipmentId)
+		}
+	}
+
+	sort.Ints(equipmentIds)
+
+	return equipmentIds
+}
+
+// FeederOfEquipment returns the edge id of the feeder head circuit breaker the equipment is fed by, see ComputeFeeders.
+// Returns ErrNotInFeeder if the equipment is not fed by any feeder head and ErrMultiFed if it is fed by several
+func (t *TopologyGridStruct) FeederOfEquipment(equipmentId int) (int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	if _, exists := t.equipment[equipmentId]; !exists {
+		return 0, equipmentNotFound(equipmentId)
+	}
+
+	switch headEdgeIds := t.feederCache().feedersFromEquipmentId[equipmentId]; len(headEdgeIds) {
+	case 0:
+		return 0, &IdError{Err: ErrNotInFeeder, Id: equipmentId}
+	case 1:
+		return headEdgeIds[0], nil
+	default:
+		return 0, &IdError{Err: ErrMultiFed, Id: equipmentId}
+	}
+}
+
+// feederCache returns feeders of the current topology, building them if the topology has changed since the last call.
+// Must be called with at least the read lock held
+func (t *TopologyGridStruct) feederCache() *feederCache {
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+
+	if t.feeders == nil || t.feeders.version != t.graphVersion {
+		t.feeders = t.buildFeeders()
+	}
+
+	return t.feeders
+}
+
+// buildFeeders finds nodes supplied from the power nodes without passing a circuit breaker, the closed circuit breakers
+// leaving them are feeder heads. Every feeder is then traversed from its head away from the power nodes
+func (t *TopologyGridStruct) buildFeeders() *feederCache {
+	isCircuitBreaker := func(edge EdgeStruct) bool {
+		typeId, _ := t.edgeState(edge)
+		return typeId == TypeCircuitBreaker
+	}
+
+	closedAdjacency := t.edgeAdjacency(t.isEdgeClosed)
+
+	// Nodes supplied from the power nodes without passing a circuit breaker
+	isSource := make([]bool, t.nodeIdx)
+	queue := make([]int, 0)
+
+	for _, nodeIdOfPowerNode := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		if nodeIdx, exists := t.nodeIdxFromNodeId[nodeIdOfPowerNode]; exists && !isSource[nodeIdx] {
+			isSource[nodeIdx] = true
+			queue = append(queue, nodeIdx)
+		}
+	}
+
+	headEdgeIdxFromEdgeId := make(map[int]int)
+
+	for ; len(queue) > 0; queue = queue[1:] {
+		v := queue[0]
+
+		for _, edgeIdx := range closedAdjacency[v] {
+			edge := t.edges[edgeIdx]
+			w := t.otherTerminalIdx(edge, v)
+
+			if isCircuitBreaker(edge) {
+				headEdgeIdxFromEdgeId[edge.id] = edgeIdx
+			} else if !isSource[w] {
+				isSource[w] = true
+				queue = append(queue, w)
+			}
+		}
+	}
+
+	feeders := &feederCache{
+		version:                t.graphVersion,
+		headEdgeIds:            make([]int, 0, len(headEdgeIdxFromEdgeId)),
+		feedersFromEquipmentId: make(map[int][]int),
+		feedersFromNodeIdx:     make([][]int, t.nodeIdx),
+	}
+
+	for headEdgeId, headEdgeIdx := range headEdgeIdxFromEdgeId {
+		head := t.edges[headEdgeIdx]
+
+		// Circuit breakers between two parts supplied directly from the power nodes are bus couplers
+		if isSource[t.nodeIdxFromNodeId[head.terminal.node1Id]] && isSource[t.nodeIdxFromNodeId[head.terminal.node2Id]] {
+			continue
+		}
+
+		feeders.headEdgeIds = append(feeders.headEdgeIds, headEdgeId)
+	}
+
+	sort.Ints(feeders.headEdgeIds)
+
+	for _, headEdgeId := range feeders.headEdgeIds {
+		head := t.edges[headEdgeIdxFromEdgeId[headEdgeId]]
+		equipmentIds := map[int]bool{head.equipmentId: true}
+
+		root := t.nodeIdxFromNodeId[head.terminal.node1Id]
+		if isSource[root] {
+			root = t.nodeIdxFromNodeId[head.terminal.node2Id]
+		}
+
+		visited := map[int]bool{root: true}
+
+		for queue := []int{root}; len(queue) > 0; queue = queue[1:] {
+			v := queue[0]
+			node := t.nodes[v]
+
+			feeders.feedersFromNodeIdx[v] = append(feeders.feedersFromNodeIdx[v], headEdgeId)
+
+			if node.equipmentId != 0 {
+				equipmentIds[node.equipmentId] = true
+			}
+
+			// Edges of the node including open switches at the end of the feeder, but not edges back to the power nodes
+			for _, edgeId := range t.edgeIdArrayFromNodeId[node.id] {
+				edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+
+				_, existsNode1 := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+				_, existsNode2 := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+
+				if existsNode1 && existsNode2 && !isSource[t.otherTerminalIdx(edge, v)] {
+					equipmentIds[edge.equipmentId] = true
+				}
+			}
+
+			for _, edgeIdx := range closedAdjacency[v] {
+				if w := t.otherTerminalIdx(t.edges[edgeIdx], v); !isSource[w] && !visited[w] {
+					visited[w] = true
+					queue = append(queue, w)
+				}
+			}
+		}
+
+		for equipmentId := range equipmentIds {
+			if equipmentId != 0 {
+				feeders.feedersFromEquipmentId[equipmentId] = append(feeders.feedersFromEquipmentId[equipmentId], headEdgeId)
+			}
+		}
+	}
+
+	return feeders
+}
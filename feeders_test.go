@@ -0,0 +1,110 @@
+package topogrid
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestComputeFeeders(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		topology  func(t testing.TB) *TopologyGridStruct
+		closed    []int
+		opened    []int
+		feeders   map[int][]int
+		multiFed  []int
+		equipment map[int]int // EquipmentId -> edge id of the feeder head
+	}{
+		{
+			name:      "radial feeder",
+			topology:  newRadialGrid,
+			feeders:   map[int][]int{10: {104, 105, 110, 120, 130, 140}},
+			multiFed:  []int{},
+			equipment: map[int]int{104: 10, 130: 10},
+		},
+		{
+			// The normally open CB40 is at the end of both feeders
+			name:      "ring with an open point",
+			topology:  newRingGrid,
+			feeders:   map[int][]int{10: {103, 104, 110, 120, 130}, 60: {105, 150, 160}},
+			multiFed:  []int{140},
+			equipment: map[int]int{104: 10, 105: 60},
+		},
+		{
+			name:      "closed ring",
+			topology:  newRingGrid,
+			closed:    []int{140},
+			feeders:   map[int][]int{10: {110}, 60: {160}},
+			multiFed:  []int{103, 104, 105, 120, 130, 140, 150},
+			equipment: map[int]int{110: 10, 160: 60},
+		},
+		{
+			name:      "parallel lines",
+			topology:  newParallelGrid,
+			feeders:   map[int][]int{10: {110}, 20: {120}},
+			multiFed:  []int{105, 130, 140, 150},
+			equipment: map[int]int{120: 20},
+		},
+		{
+			name:      "parallel lines with one line open",
+			topology:  newParallelGrid,
+			opened:    []int{120},
+			feeders:   map[int][]int{10: {105, 110, 130, 140, 150}},
+			multiFed:  []int{},
+			equipment: map[int]int{105: 10, 140: 10},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			topology := test.topology(t)
+			for _, equipmentId := range test.closed {
+				mustSucceed(t, topology.SetSwitchState(equipmentId, SwitchStateClose))
+			}
+			for _, equipmentId := range test.opened {
+				mustSucceed(t, topology.SetSwitchState(equipmentId, SwitchStateOpen))
+			}
+
+			if feeders := topology.ComputeFeeders(); !maps.EqualFunc(feeders, test.feeders, slices.Equal) {
+				t.Fatalf("ComputeFeeders = %v, want %v", feeders, test.feeders)
+			}
+
+			if multiFed := topology.MultiFedEquipment(); !slices.Equal(multiFed, test.multiFed) {
+				t.Fatalf("MultiFedEquipment = %v, want %v", multiFed, test.multiFed)
+			}
+
+			for equipmentId, want := range test.equipment {
+				if headEdgeId, err := topology.FeederOfEquipment(equipmentId); err != nil || headEdgeId != want {
+					t.Fatalf("FeederOfEquipment(%d) = %d, %v, want %d", equipmentId, headEdgeId, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFeederOfEquipmentErrors(t *testing.T) {
+	topology := newRingGrid(t)
+
+	if _, err := topology.FeederOfEquipment(140); !errors.Is(err, ErrMultiFed) {
+		t.Fatalf("FeederOfEquipment of the open point returns %v, want ErrMultiFed", err)
+	}
+
+	if _, err := topology.FeederOfEquipment(101); !errors.Is(err, ErrNotInFeeder) {
+		t.Fatalf("FeederOfEquipment of a power node returns %v, want ErrNotInFeeder", err)
+	}
+
+	if _, err := topology.FeederOfEquipment(999); !errors.Is(err, ErrEquipmentNotFound) {
+		t.Fatalf("FeederOfEquipment of an unknown equipment returns %v, want ErrEquipmentNotFound", err)
+	}
+
+	// Feeders are recalculated when the topology changes
+	mustSucceed(t, topology.SetSwitchState(130, SwitchStateOpen))
+
+	if headEdgeId, err := topology.FeederOfEquipment(140); err != nil || headEdgeId != 60 {
+		t.Fatalf("FeederOfEquipment(140) with open DS30 = %d, %v, want 60", headEdgeId, err)
+	}
+
+	if _, err := topology.FeederOfEquipment(104); !errors.Is(err, ErrNotInFeeder) {
+		t.Fatalf("FeederOfEquipment of a consumer between open switches returns %v, want ErrNotInFeeder", err)
+	}
+}
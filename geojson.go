@@ -0,0 +1,142 @@
+package topogrid
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+type geoJsonFeature struct {
+	Type       string            `json:"type"`
+	Geometry   geoJsonGeometry   `json:"geometry"`
+	Properties geoJsonProperties `json:"properties"`
+}
+
+type geoJsonGeometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+type geoJsonProperties struct {
+	NodeId          int    `json:"nodeId,omitempty"`
+	EdgeId          int    `json:"edgeId,omitempty"`
+	EquipmentId     int    `json:"equipmentId"`
+	Name            string `json:"name"`
+	TypeId          int    `json:"typeId"`
+	SwitchState     *int   `json:"switchState,omitempty"`
+	ElectricalState uint8  `json:"electricalState"`
+}
+
+// GetAsGeoJSON returns the topology as a GeoJSON feature collection: nodes as Point features and edges
+// as LineString features between terminal coordinates. Nodes without coordinates that are not referenced
+// by edges are skipped, an edge with a terminal without coordinates returns ErrNoCoordinates
+func (t *TopologyGridStruct) GetAsGeoJSON() ([]byte, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	var collection bytes.Buffer
+
+	if err := t.writeGeoJSON(&collection); err != nil {
+		return nil, err
+	}
+
+	return collection.Bytes(), nil
+}
+
+// WriteGeoJSON writes the feature collection returned by GetAsGeoJSON into w followed by a newline.
+// Features are encoded one by one, nothing is written if an edge terminal has no coordinates
+func (t *TopologyGridStruct) WriteGeoJSON(w io.Writer) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	return writeBuffered(w, func(writer exportWriter) error {
+		if err := t.writeGeoJSON(writer); err != nil {
+			return err
+		}
+
+		return writer.WriteByte('\n')
+	})
+}
+
+func (t *TopologyGridStruct) writeGeoJSON(collection exportWriter) error {
+	for _, edge := range t.edges {
+		for _, nodeId := range []int{edge.terminal.node1Id, edge.terminal.node2Id} {
+			if _, exists := t.coordinatesFromNodeId[nodeId]; !exists {
+				return &IdError{Err: ErrNoCoordinates, Id: nodeId}
+			}
+		}
+	}
+
+	_, _ = collection.WriteString(`{"type":"FeatureCollection","features":[`)
+
+	isFirst := true
+	writeFeature := func(feature geoJsonFeature) error {
+		encoded, err := json.Marshal(feature)
+		if err != nil {
+			return err
+		}
+
+		if !isFirst {
+			_ = collection.WriteByte(',')
+		}
+		isFirst = false
+
+		_, err = collection.Write(encoded)
+
+		return err
+	}
+
+	for _, node := range t.nodes[:t.nodeIdx] {
+		coordinates, exists := t.coordinatesFromNodeId[node.id]
+		if !exists {
+			continue
+		}
+
+		err := writeFeature(geoJsonFeature{
+			Type: "Feature",
+			Geometry: geoJsonGeometry{
+				Type:        "Point",
+				Coordinates: [2]float64{coordinates.x, coordinates.y},
+			},
+			Properties: geoJsonProperties{
+				NodeId:          node.id,
+				EquipmentId:     node.equipmentId,
+				Name:            t.equipment[node.equipmentId].name,
+				TypeId:          t.nodeTypeId(node),
+				ElectricalState: node.electricalState,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, edge := range t.edges {
+		coordinates1 := t.coordinatesFromNodeId[edge.terminal.node1Id]
+		coordinates2 := t.coordinatesFromNodeId[edge.terminal.node2Id]
+		typeId, state := t.edgeState(edge)
+
+		err := writeFeature(geoJsonFeature{
+			Type: "Feature",
+			Geometry: geoJsonGeometry{
+				Type:        "LineString",
+				Coordinates: [][2]float64{{coordinates1.x, coordinates1.y}, {coordinates2.x, coordinates2.y}},
+			},
+			Properties: geoJsonProperties{
+				EdgeId:          edge.id,
+				EquipmentId:     edge.equipmentId,
+				Name:            t.equipment[edge.equipmentId].name,
+				TypeId:          typeId,
+				SwitchState:     &state,
+				ElectricalState: t.equipment[edge.equipmentId].electricalState,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := collection.WriteString("]}")
+
+	return err
+}
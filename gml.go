@@ -0,0 +1,308 @@
+package topogrid
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// GML node and edge fill colors, the colors of equipment types are registered in types.go
+const (
+	gmlFillJoin      = "#808080"
+	gmlFillStateOff  = "#000000"
+	gmlFillEnergized = "#00FF00"
+	gmlFillIsolated  = "#808080"
+)
+
+// GML fill colors of feeders, see GetAsGraphMlByFeeder
+var gmlFillFeeders = []string{"#1F77B4", "#FF7F0E", "#2CA02C", "#D62728", "#9467BD", "#8C564B", "#E377C2", "#BCBD22", "#17BECF"}
+
+// gmlNodeShape is a node shape of the GML graphics: type and optional size
+type gmlNodeShape struct {
+	shapeType string
+	width     float64
+	height    float64
+}
+
+var gmlShapeJoin = gmlNodeShape{shapeType: "ellipse", width: 5.0, height: 5.0}
+
+// GmlOption adds debugging information to the GML output, options can be combined
+type GmlOption int
+
+const (
+	GmlWithIds       GmlOption = 1 << iota // Node or edge id and equipment id in labels, e.g. "CB-10 (edge 10, equipment 110)"
+	GmlWithState                           // electricalState attributes of nodes and edges, and switchState attributes of edges
+	GmlWithPoweredBy                       // Power node ids with the number of switches in labels, e.g. "powered by 1 (2 switches)"
+)
+
+// GetAsGraphMl returns a string with a graph represented by the graph modeling language
+// drawn with the graphics of the registered equipment types, see GetAsGraphMlStyled.
+// Node slots preallocated by New and not used by AddNode are written as join nodes with id 0
+func (t *TopologyGridStruct) GetAsGraphMl(options ...GmlOption) string {
+	return t.GetAsGraphMlStyled(DefaultGraphMlStyle(), options...)
+}
+
+// WriteGraphMl writes the graph returned by GetAsGraphMl into w
+func (t *TopologyGridStruct) WriteGraphMl(w io.Writer, options ...GmlOption) error {
+	return t.WriteGraphMlStyled(w, DefaultGraphMlStyle(), options...)
+}
+
+// GetAsGraphMlWithState returns a string with a graph represented by the graph modeling language,
+// colored by the electrical state calculated by SetEquipmentElectricalState: power sources are red,
+// energized equipment is green and isolated equipment is grey. Open switches are dotted
+func (t *TopologyGridStruct) GetAsGraphMlWithState(options ...GmlOption) string {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.getAsGraphMl(t.nodes[:t.nodeIdx], t.gmlNodeGraphicsByElectricalState, t.gmlEdgeGraphicsByElectricalState, options)
+}
+
+// WriteGraphMlWithState writes the graph returned by GetAsGraphMlWithState into w
+func (t *TopologyGridStruct) WriteGraphMlWithState(w io.Writer, options ...GmlOption) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.writeGraphMlBuffered(w, t.nodes[:t.nodeIdx], t.gmlNodeGraphicsByElectricalState, t.gmlEdgeGraphicsByElectricalState, options)
+}
+
+// GetAsGraphMlByFeeder returns a string with a graph represented by the graph modeling language,
+// colored by feeders found by ComputeFeeders: every feeder has its own color, power sources are red,
+// and equipment that is multi-fed or not fed by any feeder is grey. Open switches are dotted
+func (t *TopologyGridStruct) GetAsGraphMlByFeeder(options ...GmlOption) string {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeGraphics, edgeGraphics := t.gmlGraphicsByFeeder()
+
+	return t.getAsGraphMl(t.nodes[:t.nodeIdx], nodeGraphics, edgeGraphics, options)
+}
+
+// WriteGraphMlByFeeder writes the graph returned by GetAsGraphMlByFeeder into w
+func (t *TopologyGridStruct) WriteGraphMlByFeeder(w io.Writer, options ...GmlOption) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeGraphics, edgeGraphics := t.gmlGraphicsByFeeder()
+
+	return t.writeGraphMlBuffered(w, t.nodes[:t.nodeIdx], nodeGraphics, edgeGraphics, options)
+}
+
+// gmlGraphicsByFeeder returns the node and edge graphics colored by feeders
+func (t *TopologyGridStruct) gmlGraphicsByFeeder() (func(node NodeStruct) string, func(edge EdgeStruct) string) {
+	feeders := t.feederCache()
+
+	nodeGraphics := func(node NodeStruct) string {
+		shape, fill := t.gmlNodeShapeByType(node)
+
+		if t.equipment[node.equipmentId].typeId != TypePower {
+			fill = feeders.fill(feeders.feedersFromNodeIdx[t.nodeIdxFromNodeId[node.id]])
+		}
+
+		return t.gmlNodeGraphics(node, shape, fill)
+	}
+
+	edgeGraphics := func(edge EdgeStruct) string {
+		var style string
+
+		if _, state := t.edgeState(edge); state != SwitchStateClose {
+			style = "dotted"
+		}
+
+		return gmlEdgeGraphics(style, feeders.fill(feeders.feedersFromEquipmentId[edge.equipmentId]))
+	}
+
+	return nodeGraphics, edgeGraphics
+}
+
+func (t *TopologyGridStruct) getAsGraphMl(nodes []NodeStruct, nodeGraphics func(node NodeStruct) string, edgeGraphics func(edge EdgeStruct) string, options []GmlOption) string {
+	var graphMl strings.Builder
+
+	t.writeGraphMl(&graphMl, nodes, nodeGraphics, edgeGraphics, options)
+
+	return graphMl.String()
+}
+
+func (t *TopologyGridStruct) writeGraphMlBuffered(w io.Writer, nodes []NodeStruct, nodeGraphics func(node NodeStruct) string, edgeGraphics func(edge EdgeStruct) string, options []GmlOption) error {
+	return writeBuffered(w, func(writer exportWriter) error {
+		t.writeGraphMl(writer, nodes, nodeGraphics, edgeGraphics, options)
+		return nil
+	})
+}
+
+func (t *TopologyGridStruct) writeGraphMl(graphMl exportWriter, nodes []NodeStruct, nodeGraphics func(node NodeStruct) string, edgeGraphics func(edge EdgeStruct) string, options []GmlOption) {
+	var option GmlOption
+	for _, o := range options {
+		option |= o
+	}
+
+	_, _ = graphMl.WriteString("graph [\n")
+
+	for _, node := range nodes {
+		label := t.gmlNodeLabel(node)
+
+		if option&GmlWithIds != 0 {
+			label = exportLabel(label, "node", node.id, node.equipmentId, LabelId|LabelEquipmentId)
+		}
+
+		_, _ = fmt.Fprintf(graphMl, "  node [%s\n    id %d\n    label \"%s\"\n",
+			nodeGraphics(node), node.id, gmlEscape(t.gmlPoweredByLabel(label, node.equipmentId, option)))
+
+		if option&GmlWithState != 0 {
+			_, _ = fmt.Fprintf(graphMl, "    electricalState %d\n", node.electricalState)
+		}
+
+		_, _ = graphMl.WriteString("  ]\n")
+	}
+
+	for _, edge := range t.edges {
+		equipment := t.equipment[edge.equipmentId]
+		label := equipment.name
+
+		if option&GmlWithIds != 0 {
+			label = exportLabel(label, "edge", edge.id, edge.equipmentId, LabelId|LabelEquipmentId)
+		}
+
+		_, _ = fmt.Fprintf(graphMl, "  edge [%s\n    source %d\n    target %d\n    label \"%s\"\n",
+			edgeGraphics(edge), edge.terminal.node1Id, edge.terminal.node2Id, gmlEscape(t.gmlPoweredByLabel(label, edge.equipmentId, option)))
+
+		if option&GmlWithState != 0 {
+			_, _ = fmt.Fprintf(graphMl, "    electricalState %d\n    switchState %d\n", equipment.electricalState, equipment.switchState)
+		}
+
+		_, _ = graphMl.WriteString("  ]\n")
+	}
+
+	_, _ = graphMl.WriteString("]\n")
+}
+
+// gmlPoweredByLabel returns the label followed by the power node ids of the equipment with the number of switches
+// between the power node and the equipment if GmlWithPoweredBy is set, e.g. "CB-10 powered by 1 (2 switches), 5 (1 switches)"
+func (t *TopologyGridStruct) gmlPoweredByLabel(label string, equipmentId int, option GmlOption) string {
+	if option&GmlWithPoweredBy == 0 || equipmentId == 0 {
+		return label
+	}
+
+	poweredBy := t.equipment[equipmentId].poweredBy
+	if len(poweredBy) == 0 {
+		return strings.TrimSpace(label + " not powered")
+	}
+
+	powerNodeIds := make([]int, 0, len(poweredBy))
+	for powerNodeId := range poweredBy {
+		powerNodeIds = append(powerNodeIds, powerNodeId)
+	}
+	sort.Ints(powerNodeIds)
+
+	sources := make([]string, 0, len(powerNodeIds))
+	for _, powerNodeId := range powerNodeIds {
+		sources = append(sources, fmt.Sprintf("%d (%d switches)", powerNodeId, poweredBy[powerNodeId]))
+	}
+
+	return strings.TrimSpace(label + " powered by " + strings.Join(sources, ", "))
+}
+
+// gmlNodeLabel returns the equipment name of the node annotated with the voltage level if it is set
+func (t *TopologyGridStruct) gmlNodeLabel(node NodeStruct) string {
+	label := t.equipment[node.equipmentId].name
+
+	if kv, exists := t.voltageLevelFromNodeId[node.id]; exists {
+		label = fmt.Sprintf("%s %g kV", label, kv)
+	}
+
+	return label
+}
+
+// gmlNodeShapeByType returns the node shape and the fill color registered for the equipment type
+func (t *TopologyGridStruct) gmlNodeShapeByType(node NodeStruct) (gmlNodeShape, string) {
+	if registered := registeredEquipmentType(t.equipment[node.equipmentId].typeId); registered.nodeGraphics() {
+		return registered.gmlShape, registered.fill
+	}
+
+	return gmlShapeJoin, gmlFillJoin
+}
+
+func (t *TopologyGridStruct) gmlNodeGraphicsByElectricalState(node NodeStruct) string {
+	shape, fill := t.gmlNodeShapeByType(node)
+
+	if t.equipment[node.equipmentId].typeId != TypePower {
+		fill = gmlFillByElectricalState(node.electricalState)
+	}
+
+	return t.gmlNodeGraphics(node, shape, fill)
+}
+
+func (t *TopologyGridStruct) gmlEdgeGraphicsByElectricalState(edge EdgeStruct) string {
+	var style string
+
+	if _, state := t.edgeState(edge); state != SwitchStateClose {
+		style = "dotted"
+	}
+
+	return gmlEdgeGraphics(style, gmlFillByElectricalState(t.equipment[edge.equipmentId].electricalState))
+}
+
+// fill returns the color of the feeder with the head edge ids, or grey if there is not exactly one feeder
+func (f *feederCache) fill(headEdgeIds []int) string {
+	if len(headEdgeIds) != 1 {
+		return gmlFillIsolated
+	}
+	return gmlFillFeeders[sort.SearchInts(f.headEdgeIds, headEdgeIds[0])%len(gmlFillFeeders)]
+}
+
+func gmlFillByElectricalState(electricalState uint8) string {
+	if electricalState&StateEnergized == StateEnergized {
+		return gmlFillEnergized
+	}
+	return gmlFillIsolated
+}
+
+// gmlNodeGraphics returns the GML graphics section of a node with the node coordinates if they were set
+func (t *TopologyGridStruct) gmlNodeGraphics(node NodeStruct, shape gmlNodeShape, fill string) string {
+	graphics := "\n    graphics\n    ["
+
+	if coordinates, exists := t.coordinatesFromNodeId[node.id]; exists {
+		graphics += fmt.Sprintf("\n      x %f\n      y %f", coordinates.x, coordinates.y)
+	}
+
+	graphics += fmt.Sprintf("\n      type \"%s\"\n      fill \"%s\"", shape.shapeType, fill)
+
+	if shape.width != 0 || shape.height != 0 {
+		graphics += fmt.Sprintf("\n      w %.1f\n      h %.1f", shape.width, shape.height)
+	}
+
+	return graphics + "\n    ]"
+}
+
+// gmlEdgeGraphics returns the GML graphics section of an edge with an optional line style
+func gmlEdgeGraphics(style string, fill string) string {
+	if style == "" {
+		return fmt.Sprintf("\n    graphics\n    [\n    fill \"%s\"\n    ]", fill)
+	}
+	return fmt.Sprintf("\n    graphics\n    [\n    style \"%s\"\n      fill \"%s\"\n    ]", style, fill)
+}
+
+// gmlEscape returns a string that can be used as a GML string value. GML strings are ISO-8859-1 without
+// escape sequences, so quotes, ampersands, backslashes and non-ASCII characters are replaced by HTML entities
+// and control characters are removed
+func gmlEscape(s string) string {
+	var escaped strings.Builder
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			escaped.WriteString("&quot;")
+		case r == '&':
+			escaped.WriteString("&amp;")
+		case r == '\\':
+			escaped.WriteString("&#92;")
+		case r < 0x20 || r == 0x7f:
+		case r > 0x7f:
+			_, _ = fmt.Fprintf(&escaped, "&#%d;", r)
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+
+	return escaped.String()
+}
@@ -0,0 +1,124 @@
+package topogrid
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// newUnusedSlotsGrid returns the topology of testdata/graphml_unused_slots.gml with 7 nodes in 10 preallocated node slots
+func newUnusedSlotsGrid(t testing.TB) *TopologyGridStruct {
+	t.Helper()
+
+	topology := New(10)
+
+	for _, node := range []struct {
+		id, equipmentId, typeId int
+		name                    string
+	}{
+		{1, 100, TypePower, "P1"},
+		{2, 0, TypeAllEquipment, ""},
+		{3, 103, TypeLine, "L1"},
+		{4, 104, TypeConsumer, "C1"},
+		{5, 105, TypePower, "P2"},
+		{6, 0, TypeAllEquipment, ""},
+		{7, 107, TypeGround, "G1"},
+	} {
+		mustSucceed(t, topology.AddNode(node.id, node.equipmentId, node.typeId, node.name))
+	}
+
+	for _, edge := range []struct {
+		id, terminal1, terminal2, state, equipmentId, typeId int
+		name                                                 string
+	}{
+		{10, 1, 2, SwitchStateClose, 110, TypeCircuitBreaker, "CB10"},
+		{20, 2, 3, SwitchStateClose, 120, TypeDisconnectSwitch, "DS20"},
+		{30, 3, 4, SwitchStateClose, 130, TypeCircuitBreaker, "CB30"},
+		{40, 5, 6, SwitchStateClose, 140, TypeCircuitBreaker, "CB40"},
+		{50, 6, 4, SwitchStateOpen, 150, TypeCircuitBreaker, "CB50"},
+		{60, 6, 3, SwitchStateOpen, 160, TypeDisconnectSwitch, "DS60"},
+		{70, 3, 7, SwitchStateOpen, 170, TypeAllEquipment, "B70"},
+		{80, 2, 6, SwitchStateClose, 180, TypeAllEquipment, "B80"},
+	} {
+		mustSucceed(t, topology.AddEdge(edge.id, edge.terminal1, edge.terminal2, edge.state, edge.equipmentId, edge.typeId, edge.name))
+	}
+
+	return topology
+}
+
+func TestGetAsGraphMlGolden(t *testing.T) {
+	golden, err := os.ReadFile("testdata/graphml_unused_slots.gml")
+	mustSucceed(t, err)
+
+	topology := newUnusedSlotsGrid(t)
+
+	if got := topology.GetAsGraphMl(); got != string(golden) {
+		t.Fatalf("GetAsGraphMl differs from the golden file:\n%s", got)
+	}
+
+	if got := topology.GetAsGraphMlStyled(DefaultGraphMlStyle()); got != string(golden) {
+		t.Fatalf("GetAsGraphMlStyled with the default style differs from the golden file:\n%s", got)
+	}
+
+	var written bytes.Buffer
+	mustSucceed(t, topology.WriteGraphMl(&written))
+
+	if !bytes.Equal(written.Bytes(), golden) {
+		t.Fatalf("WriteGraphMl differs from the golden file:\n%s", written.String())
+	}
+}
+
+func TestGmlEscape(t *testing.T) {
+	for _, test := range []struct {
+		name, want string
+	}{
+		{`CB "Main"`, "CB &quot;Main&quot;"},
+		{"TP-1 & TP-2", "TP-1 &amp; TP-2"},
+		{"&quot;", "&amp;quot;"},
+		{"U < 0.4 kV", "U < 0.4 kV"},
+		{`C:\grid`, "C:&#92;grid"},
+		{"line\n2\ttab\x7f", "line2tab"},
+		{"ПС-110 «Южная»", "&#1055;&#1057;-110 &#171;&#1070;&#1078;&#1085;&#1072;&#1103;&#187;"},
+	} {
+		if got := gmlEscape(test.name); got != test.want {
+			t.Errorf("gmlEscape(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestGetAsGraphMlEscapesLabels(t *testing.T) {
+	names := []string{`P "1"`, "L<1> & \"2\"", `C\1`, "ТП\n«3»", `CB "10"`, "DS & <20>", "CB\t30\\", `"`}
+
+	topology := New(4)
+	for i, name := range names[:4] {
+		mustSucceed(t, topology.AddNode(i+1, 100+i+1, []int{TypePower, TypeLine, TypeConsumer, TypeConsumer}[i], name))
+	}
+	for i, name := range names[4:] {
+		mustSucceed(t, topology.AddEdge(10*(i+1), i%3+1, i%3+2, SwitchStateClose, 110+10*i, TypeCircuitBreaker, name))
+	}
+
+	for _, option := range []GmlOption{0, GmlWithIds | GmlWithPoweredBy} {
+		graphMl := topology.GetAsGraphMl(option)
+
+		// Every label is a single line of printable ASCII with exactly two quotes
+		labels := 0
+		for _, line := range bytes.Split([]byte(graphMl), []byte("\n")) {
+			for _, c := range line {
+				if c < 0x20 || c > 0x7e {
+					t.Fatalf("line %q contains the character %#x", line, c)
+				}
+			}
+
+			if trimmed := bytes.TrimSpace(line); bytes.HasPrefix(trimmed, []byte("label ")) {
+				labels++
+				if bytes.Count(trimmed, []byte(`"`)) != 2 || !bytes.HasSuffix(trimmed, []byte(`"`)) {
+					t.Fatalf("label line %q is not a single GML string", trimmed)
+				}
+			}
+		}
+
+		if labels != len(names) {
+			t.Fatalf("%d labels, want %d", labels, len(names))
+		}
+	}
+}
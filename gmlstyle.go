@@ -0,0 +1,117 @@
+package topogrid
+
+import "io"
+
+// GraphMlNodeStyle is the GML graphics of nodes: the node type, e.g. "ellipse", "triangle" or "star6",
+// the fill color "#RRGGBB" and an optional size
+type GraphMlNodeStyle struct {
+	Shape  string
+	Fill   string
+	Width  float64
+	Height float64
+}
+
+// GraphMlEdgeStyle is the GML graphics of edges: the line style, "" for a solid line or e.g. "dotted",
+// and the fill color "#RRGGBB". Edges without a fill color have no graphics
+type GraphMlEdgeStyle struct {
+	Line string
+	Fill string
+}
+
+// GraphMlStyle is the GML graphics used by GetAsGraphMlStyled
+type GraphMlStyle struct {
+	NodeByType        map[int]GraphMlNodeStyle // Graphics of nodes by the equipment type id
+	Node              GraphMlNodeStyle         // Graphics of nodes of types without their own graphics
+	EdgeBySwitchState map[int]GraphMlEdgeStyle // Graphics of edges by the switch state
+	Edge              GraphMlEdgeStyle         // Graphics of edges with switch states without their own graphics
+	EdgeFillByType    map[int]string           // Fill colors of edges by the equipment type id, override the switch state fill
+}
+
+// DefaultGraphMlStyle returns the style of GetAsGraphMl with the graphics of the registered equipment types:
+// open switches are dotted black, and edges of types with their own color are solid if closed and dotted otherwise
+func DefaultGraphMlStyle() GraphMlStyle {
+	style := GraphMlStyle{
+		NodeByType: make(map[int]GraphMlNodeStyle),
+		Node:       GraphMlNodeStyle{Shape: gmlShapeJoin.shapeType, Fill: gmlFillJoin, Width: gmlShapeJoin.width, Height: gmlShapeJoin.height},
+		EdgeBySwitchState: map[int]GraphMlEdgeStyle{
+			SwitchStateOpen:  {Line: "dotted", Fill: gmlFillStateOff},
+			SwitchStateClose: {},
+		},
+		Edge:           GraphMlEdgeStyle{Line: "dotted"},
+		EdgeFillByType: make(map[int]string),
+	}
+
+	equipmentTypesMutex.RLock()
+	defer equipmentTypesMutex.RUnlock()
+
+	for typeId, registered := range equipmentTypes {
+		if registered.nodeGraphics() {
+			style.NodeByType[typeId] = GraphMlNodeStyle{
+				Shape:  registered.gmlShape.shapeType,
+				Fill:   registered.fill,
+				Width:  registered.gmlShape.width,
+				Height: registered.gmlShape.height,
+			}
+		}
+
+		if registered.edgeGraphics() {
+			style.EdgeFillByType[typeId] = registered.fill
+		}
+	}
+
+	return style
+}
+
+// GetAsGraphMlStyled returns a string with a graph represented by the graph modeling language drawn with the style.
+// GetAsGraphMlStyled(DefaultGraphMlStyle()) returns the same graph as GetAsGraphMl
+func (t *TopologyGridStruct) GetAsGraphMlStyled(style GraphMlStyle, options ...GmlOption) string {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeGraphics, edgeGraphics := t.gmlGraphicsStyled(style)
+
+	return t.getAsGraphMl(t.nodes, nodeGraphics, edgeGraphics, options)
+}
+
+// WriteGraphMlStyled writes the graph returned by GetAsGraphMlStyled into w
+func (t *TopologyGridStruct) WriteGraphMlStyled(w io.Writer, style GraphMlStyle, options ...GmlOption) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeGraphics, edgeGraphics := t.gmlGraphicsStyled(style)
+
+	return t.writeGraphMlBuffered(w, t.nodes, nodeGraphics, edgeGraphics, options)
+}
+
+// gmlGraphicsStyled returns the node and edge graphics drawn with the style
+func (t *TopologyGridStruct) gmlGraphicsStyled(style GraphMlStyle) (func(node NodeStruct) string, func(edge EdgeStruct) string) {
+	nodeGraphics := func(node NodeStruct) string {
+		nodeStyle, exists := style.NodeByType[t.equipment[node.equipmentId].typeId]
+		if !exists {
+			nodeStyle = style.Node
+		}
+
+		return t.gmlNodeGraphics(node, gmlNodeShape{shapeType: nodeStyle.Shape, width: nodeStyle.Width, height: nodeStyle.Height}, nodeStyle.Fill)
+	}
+
+	edgeGraphics := func(edge EdgeStruct) string {
+		equipment := t.equipment[edge.equipmentId]
+
+		edgeStyle, exists := style.EdgeBySwitchState[equipment.switchState]
+		if !exists {
+			edgeStyle = style.Edge
+		}
+
+		if fill, exists := style.EdgeFillByType[equipment.typeId]; exists {
+			edgeStyle.Fill = fill
+		}
+
+		if edgeStyle.Fill == "" {
+			return ""
+		}
+
+		return gmlEdgeGraphics(edgeStyle.Line, edgeStyle.Fill)
+	}
+
+	return nodeGraphics, edgeGraphics
+}
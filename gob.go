@@ -0,0 +1,220 @@
+package topogrid
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+)
+
+// gobFormat is the version of the binary format written by Encode
+const gobFormat = 1
+
+// gobTopology is the binary form of the topology written by Encode
+type gobTopology struct {
+	Format        int
+	NumberOfNodes int // Node slots including unused preallocated ones
+	UnusedSlots   int // Node slots preallocated by New and never used
+	Nodes         []gobNode
+	Edges         []gobEdge
+	Equipment     []gobEquipment
+	Coordinates   map[int][2]float64
+	VoltageLevels map[int]float64
+	Attributes    map[int]map[string]string
+	Points        map[uint64]int
+	Version       uint64
+	Changes       []ChangeRecord // Change log from the oldest record
+}
+
+type gobNode struct {
+	Id              int
+	EquipmentId     int
+	TypeId          int
+	ElectricalState uint8
+}
+
+type gobEdge struct {
+	Id           int
+	Terminal1    int
+	Terminal2    int
+	EquipmentId  int
+	TypeId       int
+	NormalState  int
+	Weight       int64
+	LengthMeters float64
+}
+
+type gobEquipment struct {
+	Id              int
+	TypeId          int
+	Name            string
+	ElectricalState uint8
+	PoweredBy       map[int]int64
+	SwitchState     int
+	Faulted         bool
+	OutOfService    bool
+	Priority        int
+	LoadKw          float64
+}
+
+// Encode writes the topology in a binary format read by Decode: nodes and edges in the order of their indexes,
+// equipment with switch and electrical states, coordinates, voltage levels, attributes, SCADA point bindings
+// and the change log. It is much faster to read than a JSON document or a CIM model
+func (t *TopologyGridStruct) Encode(w io.Writer) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	encoded := gobTopology{
+		Format:        gobFormat,
+		NumberOfNodes: len(t.nodes),
+		UnusedSlots:   t.unusedNodeSlots,
+		Nodes:         make([]gobNode, 0, t.nodeIdx),
+		Edges:         make([]gobEdge, 0, len(t.edges)),
+		Equipment:     make([]gobEquipment, 0, len(t.equipment)),
+		Coordinates:   make(map[int][2]float64, len(t.coordinatesFromNodeId)),
+		VoltageLevels: t.voltageLevelFromNodeId,
+		Attributes:    t.attributesFromEquipmentId,
+		Points:        t.equipmentIdFromPointId,
+		Version:       t.version,
+		Changes:       append(slices.Clone(t.changeLog[t.changeLogStart:]), t.changeLog[:t.changeLogStart]...),
+	}
+
+	for _, node := range t.nodes[:t.nodeIdx] {
+		encoded.Nodes = append(encoded.Nodes, gobNode{
+			Id:              node.id,
+			EquipmentId:     node.equipmentId,
+			TypeId:          node.typeId,
+			ElectricalState: node.electricalState,
+		})
+	}
+
+	for _, edge := range t.edges {
+		encoded.Edges = append(encoded.Edges, gobEdge{
+			Id:           edge.id,
+			Terminal1:    edge.terminal.node1Id,
+			Terminal2:    edge.terminal.node2Id,
+			EquipmentId:  edge.equipmentId,
+			TypeId:       edge.typeId,
+			NormalState:  edge.normalState,
+			Weight:       edge.weight,
+			LengthMeters: edge.lengthMeters,
+		})
+	}
+
+	for _, equipment := range t.equipment {
+		encoded.Equipment = append(encoded.Equipment, gobEquipment{
+			Id:              equipment.id,
+			TypeId:          equipment.typeId,
+			Name:            equipment.name,
+			ElectricalState: equipment.electricalState,
+			PoweredBy:       equipment.poweredBy,
+			SwitchState:     equipment.switchState,
+			Faulted:         equipment.faulted,
+			OutOfService:    equipment.outOfService,
+			Priority:        equipment.priority,
+			LoadKw:          equipment.loadKw,
+		})
+	}
+
+	sort.Slice(encoded.Equipment, func(i, j int) bool { return encoded.Equipment[i].Id < encoded.Equipment[j].Id })
+
+	for nodeId, coordinates := range t.coordinatesFromNodeId {
+		encoded.Coordinates[nodeId] = [2]float64{coordinates.x, coordinates.y}
+	}
+
+	return gob.NewEncoder(w).Encode(encoded)
+}
+
+// Decode reads the topology written by Encode and rebuilds the topology graphs and the lookups.
+// Returns ErrUnsupportedFormat if the stream was written in another format, and the gob error
+// if the stream is truncated or corrupted
+func Decode(r io.Reader) (*TopologyGridStruct, error) {
+	var decoded gobTopology
+
+	if err := gob.NewDecoder(r).Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	if decoded.Format != gobFormat {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedFormat, decoded.Format)
+	}
+
+	if decoded.NumberOfNodes < len(decoded.Nodes) {
+		return nil, fmt.Errorf("%w: %d node slots for %d nodes", ErrUnsupportedFormat, decoded.NumberOfNodes, len(decoded.Nodes))
+	}
+
+	t := New(decoded.NumberOfNodes)
+
+	for i, node := range decoded.Nodes {
+		if err := t.addNode(node.Id, node.EquipmentId, node.TypeId, ""); err != nil {
+			return nil, fmt.Errorf("nodes[%d]: %w", i, err)
+		}
+		t.nodes[i].electricalState = node.ElectricalState
+	}
+
+	t.unusedNodeSlots = min(decoded.UnusedSlots, t.unusedNodeSlots)
+
+	for i, edge := range decoded.Edges {
+		// Edges referencing missing nodes are kept like AddEdge does, Validate reports them
+		err := t.addEdge(edge.Id, edge.Terminal1, edge.Terminal2, edge.NormalState, edge.EquipmentId, edge.TypeId, "")
+		if err != nil && !errors.Is(err, ErrNodeNotFound) {
+			return nil, fmt.Errorf("edges[%d]: %w", i, err)
+		}
+
+		t.edges[i].weight = edge.Weight
+		t.edges[i].lengthMeters = edge.LengthMeters
+	}
+
+	t.equipment = make(map[int]EquipmentStruct, len(decoded.Equipment))
+
+	for _, equipment := range decoded.Equipment {
+		poweredBy := equipment.PoweredBy
+		if poweredBy == nil {
+			poweredBy = make(map[int]int64)
+		}
+
+		t.equipment[equipment.Id] = EquipmentStruct{
+			id:              equipment.Id,
+			typeId:          equipment.TypeId,
+			name:            equipment.Name,
+			electricalState: equipment.ElectricalState,
+			poweredBy:       poweredBy,
+			switchState:     equipment.SwitchState,
+			faulted:         equipment.Faulted,
+			outOfService:    equipment.OutOfService,
+			priority:        equipment.Priority,
+			loadKw:          equipment.LoadKw,
+		}
+	}
+
+	for nodeId, coordinates := range decoded.Coordinates {
+		t.coordinatesFromNodeId[nodeId] = CoordinatesStruct{x: coordinates[0], y: coordinates[1]}
+	}
+
+	for nodeId, kv := range decoded.VoltageLevels {
+		t.voltageLevelFromNodeId[nodeId] = kv
+	}
+
+	for equipmentId, attributes := range decoded.Attributes {
+		for key, value := range attributes {
+			t.setEquipmentAttribute(equipmentId, key, value)
+		}
+	}
+
+	for pointId, equipmentId := range decoded.Points {
+		t.equipmentIdFromPointId[pointId] = equipmentId
+	}
+
+	// Arcs are rebuilt after switch states and faults of the equipment are restored
+	for _, edge := range t.edges {
+		t.updateArcs(edge.terminal.node1Id, edge.terminal.node2Id)
+	}
+
+	t.version = decoded.Version
+	t.changeLog = decoded.Changes[max(len(decoded.Changes)-changeLogSize, 0):]
+	t.changeLogStart = 0
+
+	return t, nil
+}
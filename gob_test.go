@@ -0,0 +1,128 @@
+package topogrid
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// decorateRandomGrid sets attributes, point bindings, coordinates, voltage levels, faults and switch states
+// of the random topology and calculates electrical states
+func decorateRandomGrid(t testing.TB, topology *TopologyGridStruct, r *rand.Rand) {
+	t.Helper()
+
+	for _, nodeId := range topology.NodeIds() {
+		if r.Intn(2) == 0 {
+			mustSucceed(t, topology.SetNodeCoordinates(nodeId, float64(r.Intn(1000)), float64(r.Intn(1000))))
+		}
+		if r.Intn(3) == 0 {
+			mustSucceed(t, topology.SetNodeVoltageLevel(nodeId, 10))
+		}
+	}
+
+	for i, equipmentId := range topology.EquipmentIds() {
+		if r.Intn(3) == 0 {
+			mustSucceed(t, topology.SetEquipmentAttribute(equipmentId, "substation", fmt.Sprintf("S%d", r.Intn(3))))
+		}
+		if r.Intn(3) == 0 {
+			mustSucceed(t, topology.BindPoint(uint64(i), equipmentId))
+		}
+	}
+
+	equipmentIds := switchEquipmentIds(topology)
+	for i := 0; i < 5; i++ {
+		mustSucceed(t, topology.SetSwitchState(equipmentIds[r.Intn(len(equipmentIds))], SwitchStateOpen))
+	}
+	mustSucceed(t, topology.SetEquipmentFault(equipmentIds[r.Intn(len(equipmentIds))], true))
+
+	topology.SetEquipmentElectricalState()
+}
+
+// queryResults returns results of queries that must be the same for a topology and its decoded copy
+func queryResults(t testing.TB, topology *TopologyGridStruct) string {
+	t.Helper()
+
+	var results bytes.Buffer
+
+	results.WriteString(topology.GetAsGraphMl())
+	results.WriteString(topology.GetAsGraphMlWithState())
+	results.WriteString(electricalSnapshot(t, topology))
+
+	for _, nodeId := range topology.NodeIds() {
+		poweredBy, err := topology.NodeIsPoweredBy(nodeId)
+		_, _ = fmt.Fprintf(&results, "node %d powered by %v %v\n", nodeId, poweredBy, err)
+	}
+
+	for _, equipmentId := range topology.EquipmentIds() {
+		attributes := topology.EquipmentAttributes(equipmentId)
+		for _, key := range slices.Sorted(maps.Keys(attributes)) {
+			_, _ = fmt.Fprintf(&results, "equipment %d %s=%s\n", equipmentId, key, attributes[key])
+		}
+	}
+
+	for pointId := uint64(0); pointId < uint64(len(topology.EquipmentIds())); pointId++ {
+		equipmentId, exists := topology.EquipmentIdByPoint(pointId)
+		_, _ = fmt.Fprintf(&results, "point %d: %d %v\n", pointId, equipmentId, exists)
+	}
+
+	changes, err := topology.Changes(0)
+	_, _ = fmt.Fprintf(&results, "version %d changes %v %v\n", topology.Version(), changes, err)
+
+	return results.String()
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	for seed := int64(0); seed < 30; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		topology := newRandomGrid(t, r)
+		decorateRandomGrid(t, topology, r)
+
+		var encoded bytes.Buffer
+		mustSucceed(t, topology.Encode(&encoded))
+
+		decoded, err := Decode(&encoded)
+		mustSucceed(t, err)
+
+		if got, want := queryResults(t, decoded), queryResults(t, topology); got != want {
+			t.Fatalf("seed %d: decoded topology differs\ndecoded:\n%s\noriginal:\n%s", seed, got, want)
+		}
+
+		changes, err := decoded.Changes(0)
+		mustSucceed(t, err)
+
+		originalChanges, err := topology.Changes(0)
+		mustSucceed(t, err)
+
+		if !reflect.DeepEqual(changes, originalChanges) {
+			t.Fatalf("seed %d: changes %v, want %v", seed, changes, originalChanges)
+		}
+	}
+}
+
+func TestDecodeTruncatedStream(t *testing.T) {
+	topology := newTestGrid(t)
+	mustSucceed(t, topology.SetEquipmentAttribute(103, "substation", "S1"))
+	mustSucceed(t, topology.BindPoint(1, 110))
+	topology.SetEquipmentElectricalState()
+
+	var encoded bytes.Buffer
+	mustSucceed(t, topology.Encode(&encoded))
+
+	for length := 0; length < encoded.Len(); length++ {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("decoding %d of %d bytes panics: %v", length, encoded.Len(), r)
+				}
+			}()
+
+			if decoded, err := Decode(bytes.NewReader(encoded.Bytes()[:length])); err == nil || decoded != nil {
+				t.Fatalf("decoding %d of %d bytes returns %v, %v", length, encoded.Len(), decoded, err)
+			}
+		}()
+	}
+}
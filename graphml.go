@@ -0,0 +1,168 @@
+package topogrid
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const graphMlNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+// GraphML attribute keys
+const (
+	graphMlKeyEquipmentId     = "equipmentId"
+	graphMlKeyName            = "name"
+	graphMlKeyTypeId          = "typeId"
+	graphMlKeySwitchState     = "switchState"
+	graphMlKeyElectricalState = "electricalState"
+)
+
+type graphMlKey struct {
+	Id   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMlNode struct {
+	Id   string        `xml:"id,attr"`
+	Data []graphMlData `xml:"data"`
+}
+
+type graphMlEdge struct {
+	Id     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMlData `xml:"data"`
+}
+
+type graphMlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// graphMlKeys are the attribute keys of GraphML documents
+var graphMlKeys = []graphMlKey{
+	{Id: graphMlKeyEquipmentId, For: "all", Name: graphMlKeyEquipmentId, Type: "int"},
+	{Id: graphMlKeyName, For: "all", Name: graphMlKeyName, Type: "string"},
+	{Id: graphMlKeyTypeId, For: "all", Name: graphMlKeyTypeId, Type: "int"},
+	{Id: graphMlKeySwitchState, For: "edge", Name: graphMlKeySwitchState, Type: "int"},
+	{Id: graphMlKeyElectricalState, For: "all", Name: graphMlKeyElectricalState, Type: "int"},
+}
+
+// GetAsGraphML returns a string with a graph represented by the GraphML XML format
+// with equipment id, name, type id, switch state and electrical state attributes
+func (t *TopologyGridStruct) GetAsGraphML() (string, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	var graphMl strings.Builder
+
+	if err := t.writeGraphML(&graphMl); err != nil {
+		return "", err
+	}
+
+	return graphMl.String(), nil
+}
+
+// WriteGraphML writes the graph returned by GetAsGraphML into w. Nodes and edges are encoded one by one
+func (t *TopologyGridStruct) WriteGraphML(w io.Writer) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	return writeBuffered(w, func(writer exportWriter) error {
+		return t.writeGraphML(writer)
+	})
+}
+
+func (t *TopologyGridStruct) writeGraphML(graphMl exportWriter) error {
+	_, _ = graphMl.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(graphMl)
+	encoder.Indent("", "  ")
+
+	root := xml.StartElement{Name: xml.Name{Local: "graphml"}, Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: graphMlNamespace}}}
+	if err := encoder.EncodeToken(root); err != nil {
+		return err
+	}
+
+	for _, key := range graphMlKeys {
+		if err := encoder.EncodeElement(key, xml.StartElement{Name: xml.Name{Local: "key"}}); err != nil {
+			return err
+		}
+	}
+
+	graphElement := xml.StartElement{Name: xml.Name{Local: "graph"}, Attr: []xml.Attr{
+		{Name: xml.Name{Local: "id"}, Value: "G"},
+		{Name: xml.Name{Local: "edgedefault"}, Value: "undirected"},
+	}}
+	if err := encoder.EncodeToken(graphElement); err != nil {
+		return err
+	}
+
+	nodeElement := xml.StartElement{Name: xml.Name{Local: "node"}}
+	for _, node := range t.nodes[:t.nodeIdx] {
+		if err := encoder.EncodeElement(t.graphMlNode(node), nodeElement); err != nil {
+			return err
+		}
+	}
+
+	edgeElement := xml.StartElement{Name: xml.Name{Local: "edge"}}
+	for _, edge := range t.edges {
+		if err := encoder.EncodeElement(t.graphMlEdge(edge), edgeElement); err != nil {
+			return err
+		}
+	}
+
+	if err := encoder.EncodeToken(graphElement.End()); err != nil {
+		return err
+	}
+
+	if err := encoder.EncodeToken(root.End()); err != nil {
+		return err
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return err
+	}
+
+	return graphMl.WriteByte('\n')
+}
+
+// graphMlNode returns the GraphML element of the node
+func (t *TopologyGridStruct) graphMlNode(node NodeStruct) graphMlNode {
+	return graphMlNode{
+		Id: graphMlNodeId(node.id),
+		Data: []graphMlData{
+			{Key: graphMlKeyEquipmentId, Value: strconv.Itoa(node.equipmentId)},
+			{Key: graphMlKeyName, Value: t.equipment[node.equipmentId].name},
+			{Key: graphMlKeyTypeId, Value: strconv.Itoa(t.nodeTypeId(node))},
+			{Key: graphMlKeyElectricalState, Value: strconv.Itoa(int(node.electricalState))},
+		},
+	}
+}
+
+// graphMlEdge returns the GraphML element of the edge
+func (t *TopologyGridStruct) graphMlEdge(edge EdgeStruct) graphMlEdge {
+	equipment := t.equipment[edge.equipmentId]
+	typeId, state := t.edgeState(edge)
+
+	return graphMlEdge{
+		Id:     fmt.Sprintf("e%d", edge.id),
+		Source: graphMlNodeId(edge.terminal.node1Id),
+		Target: graphMlNodeId(edge.terminal.node2Id),
+		Data: []graphMlData{
+			{Key: graphMlKeyEquipmentId, Value: strconv.Itoa(edge.equipmentId)},
+			{Key: graphMlKeyName, Value: equipment.name},
+			{Key: graphMlKeyTypeId, Value: strconv.Itoa(typeId)},
+			{Key: graphMlKeySwitchState, Value: strconv.Itoa(state)},
+			{Key: graphMlKeyElectricalState, Value: strconv.Itoa(int(equipment.electricalState))},
+		},
+	}
+}
+
+func graphMlNodeId(nodeId int) string {
+	return fmt.Sprintf("n%d", nodeId)
+}
@@ -0,0 +1,76 @@
+package topogrid
+
+import (
+	"sort"
+
+	"github.com/yourbasic/graph"
+)
+
+// FindGroundedAndEnergized returns sorted ids of nodes that are grounded and energized at the same time,
+// i.e. a switching error. The states are set by SetEquipmentElectricalState
+func (t *TopologyGridStruct) FindGroundedAndEnergized() []int {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIds := make([]int, 0)
+
+	for _, node := range t.nodes[:t.nodeIdx] {
+		if node.electricalState&(StateGrounded|StateEnergized) == StateGrounded|StateEnergized {
+			nodeIds = append(nodeIds, node.id)
+		}
+	}
+
+	sort.Ints(nodeIds)
+
+	return nodeIds
+}
+
+// propagateGrounding sets the grounded state of nodes galvanically connected in the current topology graph
+// to the grounding points: nodes with the type of equipment "TypeGround" and the first terminals of closed ground switches.
+// The nodes must make up whole islands of the current topology graph, only their grounded states are set.
+// Returns indexes of nodes whose grounded state has changed
+func (t *TopologyGridStruct) propagateGrounding(nodeIdxArray []int) []int {
+	grounded := make(map[int]bool)
+
+	ground := func(nodeIdx int) {
+		if grounded[nodeIdx] {
+			return
+		}
+
+		grounded[nodeIdx] = true
+		graph.BFS(t.currentGraph, nodeIdx, func(v, w int, c int64) {
+			grounded[w] = true
+		})
+	}
+
+	for _, nodeIdx := range nodeIdxArray {
+		node := t.nodes[nodeIdx]
+
+		if t.nodeTypeId(node) == TypeGround {
+			ground(nodeIdx)
+			continue
+		}
+
+		for _, edgeId := range t.edgeIdArrayFromNodeId[node.id] {
+			edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+
+			if typeId, _ := t.edgeState(edge); typeId == TypeGroundSwitch && edge.terminal.node1Id == node.id && t.isEdgeClosed(edge) {
+				ground(nodeIdx)
+				break
+			}
+		}
+	}
+
+	changed := make([]int, 0)
+
+	for _, nodeIdx := range nodeIdxArray {
+		if grounded[nodeIdx] == (t.nodes[nodeIdx].electricalState&StateGrounded == StateGrounded) {
+			continue
+		}
+
+		t.nodes[nodeIdx].electricalState ^= StateGrounded
+		changed = append(changed, nodeIdx)
+	}
+
+	return changed
+}
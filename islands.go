@@ -0,0 +1,120 @@
+package topogrid
+
+import (
+	"github.com/yourbasic/graph"
+	"sort"
+)
+
+// Island is a group of node ids galvanically connected in the topology graph
+type Island struct {
+	NodeIds []int // Sorted node ids of the island
+	Powered bool  // True if the island contains at least one node with the type of equipment "TypePower"
+}
+
+// GetIslands returns groups of node ids galvanically connected in the current topology graph and whether each
+// group is powered. Node ids within an island are sorted, islands are sorted by the first node id
+func (t *TopologyGridStruct) GetIslands() []Island {
+	t.RLock()
+	defer t.RUnlock()
+
+	powerNodes := t.powerNodeIdSet()
+	nodeIdsOfIslands := t.islands(t.currentGraph)
+	islands := make([]Island, 0, len(nodeIdsOfIslands))
+
+	for _, nodeIds := range nodeIdsOfIslands {
+		islands = append(islands, Island{NodeIds: nodeIds, Powered: isPowered(nodeIds, powerNodes)})
+	}
+
+	return islands
+}
+
+// GetIslandOfNode returns the island of node ids galvanically connected to the node in the current topology graph
+func (t *TopologyGridStruct) GetIslandOfNode(nodeId int) (Island, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
+	if !exists {
+		return Island{}, nodeNotFound(nodeId)
+	}
+
+	nodeIds := []int{nodeId}
+
+	graph.BFS(t.currentGraph, nodeIdx, func(v, w int, c int64) {
+		nodeIds = append(nodeIds, t.nodes[w].id)
+	})
+
+	sort.Ints(nodeIds)
+
+	return Island{NodeIds: nodeIds, Powered: isPowered(nodeIds, t.powerNodeIdSet())}, nil
+}
+
+// isPowered returns true if at least one of the node ids is in the set of power nodes
+func isPowered(nodeIds []int, powerNodes map[int]bool) bool {
+	for _, nodeId := range nodeIds {
+		if powerNodes[nodeId] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// islands returns groups of node ids connected in the graph, skipping unused node slots
+func (t *TopologyGridStruct) islands(g graph.Iterator) [][]int {
+	components := graph.Components(g)
+	islands := make([][]int, 0, len(components))
+
+	for _, component := range components {
+		island := make([]int, 0, len(component))
+		for _, nodeIdx := range component {
+			if nodeIdx < t.nodeIdx {
+				island = append(island, t.nodes[nodeIdx].id)
+			}
+		}
+
+		if len(island) == 0 {
+			continue
+		}
+
+		sort.Ints(island)
+		islands = append(islands, island)
+	}
+
+	sort.Slice(islands, func(i, j int) bool { return islands[i][0] < islands[j][0] })
+
+	return islands
+}
+
+// islandLabels returns the island number for every used node index and the number of islands in the graph
+func (t *TopologyGridStruct) islandLabels(g graph.Iterator) ([]int, int) {
+	labels := make([]int, t.nodeIdx)
+	numberOfIslands := 0
+
+	for _, component := range graph.Components(g) {
+		used := false
+		for _, nodeIdx := range component {
+			if nodeIdx < t.nodeIdx {
+				labels[nodeIdx] = numberOfIslands
+				used = true
+			}
+		}
+
+		if used {
+			numberOfIslands++
+		}
+	}
+
+	return labels, numberOfIslands
+}
+
+// powerNodeIdSet returns a set of node ids with the type of equipment "TypePower"
+func (t *TopologyGridStruct) powerNodeIdSet() map[int]bool {
+	powerNodes := make(map[int]bool, len(t.nodeIdArrayFromEquipmentTypeId[TypePower]))
+
+	for _, nodeId := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		powerNodes[nodeId] = true
+	}
+
+	return powerNodes
+}
@@ -0,0 +1,255 @@
+package topogrid
+
+import (
+	"sort"
+
+	"github.com/yourbasic/graph"
+)
+
+// SwitchesToIsolateEquipment returns a sorted array of switch equipment ids whose opening disconnects the equipment
+// from every power node regardless of the current switch states. The switches on the boundary of the switchable
+// section containing the equipment are used, skipping those that lead to areas without power nodes.
+// Returns ErrCannotBeIsolated if a power node can reach the equipment without passing a switch
+func (t *TopologyGridStruct) SwitchesToIsolateEquipment(equipmentId int) ([]int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	if _, exists := t.equipment[equipmentId]; !exists {
+		return nil, equipmentNotFound(equipmentId)
+	}
+
+	isOwnEdge := func(edge EdgeStruct) bool {
+		return equipmentId != 0 && edge.equipmentId == equipmentId
+	}
+
+	isSwitchEdge := func(edge EdgeStruct) bool {
+		typeId, _ := t.edgeState(edge)
+		return isSwitchType(typeId)
+	}
+
+	// The section is a set of nodes connected to the equipment terminals by non-switch edges
+	section := make([]bool, t.nodeIdx)
+	queue := make([]int, 0)
+
+	for _, nodeIdx := range t.equipmentTerminalIdxArray(equipmentId) {
+		if !section[nodeIdx] {
+			section[nodeIdx] = true
+			queue = append(queue, nodeIdx)
+		}
+	}
+
+	adjacency := t.edgeAdjacency(func(edge EdgeStruct) bool { return true })
+	boundary := make([]int, 0)
+
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+
+		if t.nodeTypeId(t.nodes[v]) == TypePower {
+			return nil, &IdError{Err: ErrCannotBeIsolated, Id: equipmentId}
+		}
+
+		for _, edgeIdx := range adjacency[v] {
+			edge := t.edges[edgeIdx]
+			if isOwnEdge(edge) {
+				continue
+			}
+
+			if isSwitchEdge(edge) {
+				boundary = append(boundary, edgeIdx)
+				continue
+			}
+
+			if w := t.otherTerminalIdx(edge, v); !section[w] {
+				section[w] = true
+				queue = append(queue, w)
+			}
+		}
+	}
+
+	// Nodes outside the section that can be powered by closing or keeping closed switches
+	powered := make([]bool, t.nodeIdx)
+	queue = queue[:0]
+
+	for _, nodeIdOfPowerNode := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		if nodeIdx, exists := t.nodeIdxFromNodeId[nodeIdOfPowerNode]; exists && !powered[nodeIdx] {
+			powered[nodeIdx] = true
+			queue = append(queue, nodeIdx)
+		}
+	}
+
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+
+		for _, edgeIdx := range adjacency[v] {
+			if w := t.otherTerminalIdx(t.edges[edgeIdx], v); !section[w] && !powered[w] {
+				powered[w] = true
+				queue = append(queue, w)
+			}
+		}
+	}
+
+	switches := make(map[int]bool)
+
+	for _, edgeIdx := range boundary {
+		edge := t.edges[edgeIdx]
+		node1Idx := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+
+		if (section[node1Idx] && powered[node2Idx]) || (section[node2Idx] && powered[node1Idx]) {
+			switches[edge.equipmentId] = true
+		}
+	}
+
+	switchIds := make([]int, 0, len(switches))
+	for switchId := range switches {
+		switchIds = append(switchIds, switchId)
+	}
+	sort.Ints(switchIds)
+
+	return switchIds, nil
+}
+
+// equipmentTerminalIdxArray returns indexes of the equipment nodes and of the terminals of the equipment edges
+func (t *TopologyGridStruct) equipmentTerminalIdxArray(equipmentId int) []int {
+	idxArray := make([]int, 0)
+
+	for _, nodeId := range t.nodeIdArrayFromEquipmentId[equipmentId] {
+		if nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]; exists {
+			idxArray = append(idxArray, nodeIdx)
+		}
+	}
+
+	for _, edgeId := range t.edgeIdArrayFromEquipmentId[equipmentId] {
+		edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+
+		for _, nodeId := range []int{edge.terminal.node1Id, edge.terminal.node2Id} {
+			if nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]; exists {
+				idxArray = append(idxArray, nodeIdx)
+			}
+		}
+	}
+
+	return idxArray
+}
+
+// MinimalSwitchCut returns sorted equipment ids of switches with the smallest number of edges whose opening disconnects
+// the node from every power node in the full topology graph, regardless of the current switch states. The cut is found
+// as the minimum cut with switch edges of capacity 1 and other edges of unlimited capacity, so unlike
+// SwitchesToIsolateEquipment it is not limited to the boundary of the switchable section. Returns PathError
+// with ErrNoSwitchOnPath if a power node can reach the node without passing a switch
+func (t *TopologyGridStruct) MinimalSwitchCut(nodeId int) ([]int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
+	if !exists {
+		return nil, nodeNotFound(nodeId)
+	}
+
+	if t.powerNodeIdSet()[nodeId] {
+		return nil, &IdError{Err: ErrNodeIsPowerNode, Id: nodeId}
+	}
+
+	unlimited := int64(len(t.edges) + 1)
+	source := t.fullGraph.Order()
+	capacity := graph.New(source + 1)
+
+	for _, edge := range t.fullGraphEdges() {
+		node1Idx := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+
+		edgeCapacity := unlimited
+		if typeId, _ := t.edgeState(edge); isSwitchType(typeId) {
+			edgeCapacity = 1
+		}
+
+		capacity.AddBothCost(node1Idx, node2Idx, min(capacity.Cost(node1Idx, node2Idx)+edgeCapacity, unlimited))
+	}
+
+	for _, nodeIdOfPowerNode := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		if powerNodeIdx, exists := t.nodeIdxFromNodeId[nodeIdOfPowerNode]; exists {
+			capacity.AddCost(source, powerNodeIdx, unlimited)
+		}
+	}
+
+	flowValue, flowGraph := graph.MaxFlow(capacity, source, nodeIdx)
+	if flowValue >= unlimited {
+		return nil, &PathError{Err: ErrNoSwitchOnPath, FromNodeId: t.powerNodeIdWithoutSwitches(nodeIdx), ToNodeId: nodeId}
+	}
+
+	// Nodes reachable from the source in the residual graph
+	flow := graph.Copy(flowGraph)
+	residual := func(v, w int) int64 {
+		return capacity.Cost(v, w) - flow.Cost(v, w) + flow.Cost(w, v)
+	}
+
+	reachable := make([]bool, source+1)
+	reachable[source] = true
+
+	for queue := []int{source}; len(queue) > 0; queue = queue[1:] {
+		v := queue[0]
+		capacity.Visit(v, func(w int, c int64) (skip bool) {
+			if !reachable[w] && residual(v, w) > 0 {
+				reachable[w] = true
+				queue = append(queue, w)
+			}
+			return
+		})
+	}
+
+	switches := make(map[int]bool)
+
+	for _, edge := range t.fullGraphEdges() {
+		node1Idx := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+
+		if reachable[node1Idx] != reachable[node2Idx] {
+			switches[edge.equipmentId] = true
+		}
+	}
+
+	switchIds := make([]int, 0, len(switches))
+	for switchId := range switches {
+		switchIds = append(switchIds, switchId)
+	}
+	sort.Ints(switchIds)
+
+	return switchIds, nil
+}
+
+// powerNodeIdWithoutSwitches returns the id of a power node connected to the node by edges of the full topology graph
+// that are not switches
+func (t *TopologyGridStruct) powerNodeIdWithoutSwitches(nodeIdx int) int {
+	powerNodes := t.powerNodeIdSet()
+
+	adjacency := make([][]EdgeStruct, t.nodeIdx)
+	for _, edge := range t.fullGraphEdges() {
+		if typeId, _ := t.edgeState(edge); !isSwitchType(typeId) {
+			node1Idx := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+			node2Idx := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+
+			adjacency[node1Idx] = append(adjacency[node1Idx], edge)
+			adjacency[node2Idx] = append(adjacency[node2Idx], edge)
+		}
+	}
+
+	visited := map[int]bool{nodeIdx: true}
+
+	for queue := []int{nodeIdx}; len(queue) > 0; queue = queue[1:] {
+		v := queue[0]
+		if powerNodes[t.nodes[v].id] {
+			return t.nodes[v].id
+		}
+
+		for _, edge := range adjacency[v] {
+			if w := t.otherTerminalIdx(edge, v); !visited[w] {
+				visited[w] = true
+				queue = append(queue, w)
+			}
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,84 @@
+package topogrid
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestMinimalSwitchCut(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		topology func(t testing.TB) *TopologyGridStruct
+		nodeId   int
+		want     []int
+	}{
+		// Both sides of the ring are cut next to the power nodes, regardless of the open CB40
+		{"ring consumer", newRingGrid, 4, []int{110, 160}},
+		{"ring join node", newRingGrid, 6, []int{110, 160}},
+		// Fuses are not switches, the feeder head is the only switch
+		{"radial consumer behind a fuse", newRadialGrid, 4, []int{110}},
+		{"parallel lines", newParallelGrid, 4, []int{110, 120}},
+		{"consumer of the parallel lines", newParallelGrid, 5, []int{150}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			switchIds, err := test.topology(t).MinimalSwitchCut(test.nodeId)
+			mustSucceed(t, err)
+
+			if !slices.Equal(switchIds, test.want) {
+				t.Fatalf("MinimalSwitchCut(%d) = %v, want %v", test.nodeId, switchIds, test.want)
+			}
+		})
+	}
+}
+
+func TestMinimalSwitchCutIsNotLimitedToSection(t *testing.T) {
+	topology := newRingGrid(t)
+
+	// The boundary of the section of C2 has the same number of switches as the cut next to the power nodes
+	isolation, err := topology.SwitchesToIsolateEquipment(104)
+	mustSucceed(t, err)
+
+	if !slices.Equal(isolation, []int{130, 140}) {
+		t.Fatalf("SwitchesToIsolateEquipment(104) = %v, want [130 140]", isolation)
+	}
+
+	// A second disconnect switch in parallel with DS30 makes the section boundary larger than the minimal cut
+	mustSucceed(t, topology.AddEdge(70, 3, 4, SwitchStateClose, 170, TypeDisconnectSwitch, "DS70"))
+
+	isolation, err = topology.SwitchesToIsolateEquipment(104)
+	mustSucceed(t, err)
+
+	if !slices.Equal(isolation, []int{130, 140, 170}) {
+		t.Fatalf("SwitchesToIsolateEquipment(104) with parallel disconnect switches = %v, want [130 140 170]", isolation)
+	}
+
+	switchIds, err := topology.MinimalSwitchCut(4)
+	mustSucceed(t, err)
+
+	if !slices.Equal(switchIds, []int{110, 160}) {
+		t.Fatalf("MinimalSwitchCut(4) with parallel disconnect switches = %v, want [110 160]", switchIds)
+	}
+}
+
+func TestMinimalSwitchCutErrors(t *testing.T) {
+	// The consumer is supplied through a line without switches
+	topology := NewDynamic()
+	mustSucceed(t, topology.AddNode(1, 101, TypePower, "P1"))
+	mustSucceed(t, topology.AddNode(2, 102, TypeConsumer, "C1"))
+	mustSucceed(t, topology.AddEdge(10, 1, 2, SwitchStateClose, 110, TypeLine, "LN10"))
+
+	var pathErr *PathError
+	if _, err := topology.MinimalSwitchCut(2); !errors.As(err, &pathErr) || !errors.Is(err, ErrNoSwitchOnPath) ||
+		pathErr.FromNodeId != 1 || pathErr.ToNodeId != 2 {
+		t.Fatalf("MinimalSwitchCut of a node supplied without switches returns %v, want ErrNoSwitchOnPath from 1 to 2", err)
+	}
+
+	if _, err := topology.MinimalSwitchCut(1); !errors.Is(err, ErrNodeIsPowerNode) {
+		t.Fatalf("MinimalSwitchCut of a power node returns %v, want ErrNodeIsPowerNode", err)
+	}
+
+	if _, err := topology.MinimalSwitchCut(99); !errors.Is(err, ErrNodeNotFound) {
+		t.Fatalf("MinimalSwitchCut of an unknown node returns %v, want ErrNodeNotFound", err)
+	}
+}
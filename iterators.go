@@ -0,0 +1,39 @@
+package topogrid
+
+// GraphIterator is a read-only view of a topology graph. Vertices are node indexes, see NodeIdByIdx,
+// arc costs are the number of circuit breakers
+type GraphIterator interface {
+	// Order returns the number of vertices including preallocated node slots without arcs
+	Order() int
+	// Visit calls the do function for each neighbor w of vertex v with c equal to the cost of the arc.
+	// If do returns true, Visit returns immediately skipping any remaining neighbors and returns true
+	Visit(v int, do func(w int, c int64) (skip bool)) (aborted bool)
+}
+
+// CurrentGraphIterator returns an immutable snapshot of the current topology graph
+func (t *TopologyGridStruct) CurrentGraphIterator() GraphIterator {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.sortedGraph(false)
+}
+
+// FullGraphIterator returns an immutable snapshot of the full topology graph
+func (t *TopologyGridStruct) FullGraphIterator() GraphIterator {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.sortedGraph(true)
+}
+
+// NodeIdByIdx returns the node id by the vertex of graph iterators
+func (t *TopologyGridStruct) NodeIdByIdx(idx int) (int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	if idx < 0 || idx >= t.nodeIdx {
+		return 0, &IdError{Err: ErrNodeNotFound, Id: idx}
+	}
+
+	return t.nodes[idx].id, nil
+}
@@ -0,0 +1,192 @@
+package topogrid
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// TopologyModel is a grid model that can be stored as a JSON document
+type TopologyModel struct {
+	Nodes []NodeModel `json:"nodes"`
+	Edges []EdgeModel `json:"edges"`
+}
+
+// NodeModel describes a node of the grid model, the fields match the AddNode arguments
+type NodeModel struct {
+	Id              int    `json:"id"`
+	EquipmentId     int    `json:"equipmentId"`
+	EquipmentTypeId int    `json:"equipmentTypeId"`
+	EquipmentName   string `json:"equipmentName"`
+
+	Attributes map[string]string `json:"attributes,omitempty"` // Equipment attributes, see SetEquipmentAttribute
+}
+
+// EdgeModel describes an edge of the grid model, the fields match the AddEdge arguments.
+// NormalState is set only if the current switch state differs from the state the edge was added with
+type EdgeModel struct {
+	Id              int    `json:"id"`
+	Terminal1       int    `json:"terminal1"`
+	Terminal2       int    `json:"terminal2"`
+	State           int    `json:"state"`
+	NormalState     *int   `json:"normalState,omitempty"`
+	EquipmentId     int    `json:"equipmentId"`
+	EquipmentTypeId int    `json:"equipmentTypeId"`
+	EquipmentName   string `json:"equipmentName"`
+
+	Attributes map[string]string `json:"attributes,omitempty"` // Equipment attributes, see SetEquipmentAttribute
+}
+
+// NewFromModel creates a topology from the grid model. All nodes are added before edges
+func NewFromModel(model TopologyModel) (*TopologyGridStruct, error) {
+	t := New(len(model.Nodes))
+
+	for i, node := range model.Nodes {
+		if err := t.AddNode(node.Id, node.EquipmentId, node.EquipmentTypeId, node.EquipmentName); err != nil {
+			return nil, fmt.Errorf("nodes[%d]: %w", i, err)
+		}
+	}
+
+	states := make(map[int]int)
+
+	for i, edge := range model.Edges {
+		state := edge.State
+		if edge.NormalState != nil && *edge.NormalState != edge.State && edge.EquipmentId != 0 {
+			state = *edge.NormalState
+			states[edge.EquipmentId] = edge.State
+		}
+
+		if err := t.AddEdge(edge.Id, edge.Terminal1, edge.Terminal2, state, edge.EquipmentId, edge.EquipmentTypeId, edge.EquipmentName); err != nil {
+			return nil, fmt.Errorf("edges[%d]: %w", i, err)
+		}
+	}
+
+	if _, err := t.ApplySwitchStates(states); err != nil {
+		return nil, err
+	}
+
+	for i, node := range model.Nodes {
+		for key, value := range node.Attributes {
+			if err := t.SetEquipmentAttribute(node.EquipmentId, key, value); err != nil {
+				return nil, fmt.Errorf("nodes[%d]: %w", i, err)
+			}
+		}
+	}
+
+	for i, edge := range model.Edges {
+		for key, value := range edge.Attributes {
+			if err := t.SetEquipmentAttribute(edge.EquipmentId, key, value); err != nil {
+				return nil, fmt.Errorf("edges[%d]: %w", i, err)
+			}
+		}
+	}
+
+	return t, nil
+}
+
+// Model returns the grid model with the current switch states. Nodes and edges are sorted by id
+func (t *TopologyGridStruct) Model() TopologyModel {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.model()
+}
+
+func (t *TopologyGridStruct) model() TopologyModel {
+	model := TopologyModel{
+		Nodes: make([]NodeModel, 0, t.nodeIdx),
+		Edges: make([]EdgeModel, 0, len(t.edges)),
+	}
+
+	for _, node := range t.nodes[:t.nodeIdx] {
+		model.Nodes = append(model.Nodes, NodeModel{
+			Id:              node.id,
+			EquipmentId:     node.equipmentId,
+			EquipmentTypeId: t.nodeTypeId(node),
+			EquipmentName:   t.equipment[node.equipmentId].name,
+			Attributes:      t.equipmentAttributes(node.equipmentId),
+		})
+	}
+
+	for _, edge := range t.edges {
+		typeId, state := t.edgeState(edge)
+
+		edgeModel := EdgeModel{
+			Id:              edge.id,
+			Terminal1:       edge.terminal.node1Id,
+			Terminal2:       edge.terminal.node2Id,
+			State:           state,
+			EquipmentId:     edge.equipmentId,
+			EquipmentTypeId: typeId,
+			EquipmentName:   t.equipment[edge.equipmentId].name,
+			Attributes:      t.equipmentAttributes(edge.equipmentId),
+		}
+
+		if state != edge.normalState {
+			normalState := edge.normalState
+			edgeModel.NormalState = &normalState
+		}
+
+		model.Edges = append(model.Edges, edgeModel)
+	}
+
+	sort.Slice(model.Nodes, func(i, j int) bool { return model.Nodes[i].Id < model.Nodes[j].Id })
+	sort.Slice(model.Edges, func(i, j int) bool { return model.Edges[i].Id < model.Edges[j].Id })
+
+	return model
+}
+
+// ToJSON writes the grid model with the current switch states as a JSON document.
+// Nodes and edges are sorted by id, so the output is diffable
+func (t *TopologyGridStruct) ToJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(t.Model())
+}
+
+// NewFromJSON creates a topology from the JSON document with the TopologyModel schema.
+// Malformed documents are reported with the line and column, malformed records with the record index
+func NewFromJSON(r io.Reader) (*TopologyGridStruct, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var model TopologyModel
+
+	if err = json.Unmarshal(data, &model); err != nil {
+		var syntaxError *json.SyntaxError
+		var typeError *json.UnmarshalTypeError
+
+		if errors.As(err, &syntaxError) {
+			line, column := jsonPosition(data, syntaxError.Offset)
+			return nil, fmt.Errorf("line %d, column %d: %w", line, column, err)
+		}
+
+		if errors.As(err, &typeError) {
+			line, column := jsonPosition(data, typeError.Offset)
+			return nil, fmt.Errorf("line %d, column %d, field %s: %w", line, column, typeError.Field, err)
+		}
+
+		return nil, err
+	}
+
+	return NewFromModel(model)
+}
+
+// jsonPosition converts the byte offset in the JSON document to the line and column numbers
+func jsonPosition(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+
+	return line, column
+}
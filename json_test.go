@@ -0,0 +1,64 @@
+package topogrid
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// edgeSwitchStates returns the normal and current switch states of all edges
+func edgeSwitchStates(topology *TopologyGridStruct) string {
+	var states bytes.Buffer
+
+	for _, edgeId := range topology.EdgeIds() {
+		edge := topology.edges[topology.edgeIdxFromEdgeId[edgeId]]
+		_, state := topology.edgeState(edge)
+		_, _ = fmt.Fprintf(&states, "edge %d: normal %d, current %d\n", edgeId, edge.normalState, state)
+	}
+
+	return states.String()
+}
+
+func TestToJSONRoundTrip(t *testing.T) {
+	states := []int{SwitchStateOpen, SwitchStateClose, SwitchStateUnknown}
+
+	for seed := int64(0); seed < 30; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		topology := newRandomGrid(t, r)
+
+		equipmentIds := switchEquipmentIds(topology)
+		for i := 0; i < 5; i++ {
+			mustSucceed(t, topology.SetSwitchState(equipmentIds[r.Intn(len(equipmentIds))], states[r.Intn(len(states))]))
+		}
+
+		var document bytes.Buffer
+		mustSucceed(t, topology.ToJSON(&document))
+
+		loaded, err := NewFromJSON(bytes.NewReader(document.Bytes()))
+		mustSucceed(t, err)
+
+		for _, nodeId := range topology.NodeIds() {
+			poweredBy, err := loaded.NodeIsPoweredBy(nodeId)
+			mustSucceed(t, err)
+
+			want, err := topology.NodeIsPoweredBy(nodeId)
+			mustSucceed(t, err)
+
+			if fmt.Sprint(poweredBy) != fmt.Sprint(want) {
+				t.Fatalf("seed %d: node %d is powered by %v, want %v", seed, nodeId, poweredBy, want)
+			}
+		}
+
+		if got, want := edgeSwitchStates(loaded), edgeSwitchStates(topology); got != want {
+			t.Fatalf("seed %d: switch states differ\nloaded:\n%s\noriginal:\n%s", seed, got, want)
+		}
+
+		var saved bytes.Buffer
+		mustSucceed(t, loaded.ToJSON(&saved))
+
+		if saved.String() != document.String() {
+			t.Fatalf("seed %d: saving the loaded topology changes the document\n%s\nwant\n%s", seed, saved.String(), document.String())
+		}
+	}
+}
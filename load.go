@@ -0,0 +1,172 @@
+package topogrid
+
+import (
+	"fmt"
+)
+
+// SetEquipmentLoad sets the load of the consumer in kW. Consumers are added with zero load.
+// Returns ErrNegativeLoad if the load is negative
+func (t *TopologyGridStruct) SetEquipmentLoad(equipmentId int, kw float64) error {
+	t.Lock()
+	defer t.Unlock()
+
+	equipment, exists := t.equipment[equipmentId]
+	if !exists {
+		return equipmentNotFound(equipmentId)
+	}
+
+	if equipment.typeId != TypeConsumer {
+		return &IdError{Err: ErrEquipmentIsNotConsumer, Id: equipmentId}
+	}
+
+	if kw < 0 {
+		return fmt.Errorf("%w: %g for equipment id %d", ErrNegativeLoad, kw, equipmentId)
+	}
+
+	equipment.loadKw = kw
+	t.equipment[equipmentId] = equipment
+	t.recordChange(ChangeEquipment, equipmentId)
+
+	return nil
+}
+
+// EquipmentLoad returns the load of the equipment in kW, see SetEquipmentLoad
+func (t *TopologyGridStruct) EquipmentLoad(equipmentId int) (float64, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	equipment, exists := t.equipment[equipmentId]
+	if !exists {
+		return 0, equipmentNotFound(equipmentId)
+	}
+
+	return equipment.loadKw, nil
+}
+
+// LoadSuppliedBy returns the total load in kW of consumers reachable from the power node in the current topology graph
+// found by a single breadth-first search. Every consumer is counted once. A consumer fed from several power nodes
+// through a closed loop is attributed in full to each of them, so loads of paralleled power nodes do not add up
+func (t *TopologyGridStruct) LoadSuppliedBy(powerNodeId int) (float64, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.loadSuppliedBy(powerNodeId)
+}
+
+func (t *TopologyGridStruct) loadSuppliedBy(powerNodeId int) (float64, error) {
+	consumers, err := t.consumerIdSetPoweredBy(powerNodeId)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.totalLoad(consumers), nil
+}
+
+// LoadDownstreamOfSwitch returns the total load in kW of consumers that would lose supply if the switch edge opened,
+// see EquipmentDownstreamOfSwitch. Every consumer is counted once
+func (t *TopologyGridStruct) LoadDownstreamOfSwitch(edgeId int) (float64, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	downstream, err := t.equipmentDownstreamOfSwitch(edgeId)
+	if err != nil {
+		return 0, err
+	}
+
+	consumers := make(map[int]bool)
+	for _, equipmentId := range downstream {
+		if t.equipment[equipmentId].typeId == TypeConsumer {
+			consumers[equipmentId] = true
+		}
+	}
+
+	return t.totalLoad(consumers), nil
+}
+
+// CanTransferLoad opens one switch and closes another on a copy of the topology and checks that no power node
+// exceeds its capacity in kW (PowerNodeId -> kW), power nodes missing in the capacities are not checked.
+// Returns the loading of every checked power node with a positive capacity as a fraction of the capacity
+// (PowerNodeId -> loading), see LoadPerSourceAfterTransfer for the load in kW. The topology is not changed
+func (t *TopologyGridStruct) CanTransferLoad(openSwitchId, closeSwitchId int, sourceCapacityKw map[int]float64) (bool, map[int]float64, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	loads, err := t.loadPerSourceAfterTransfer(openSwitchId, closeSwitchId)
+	if err != nil {
+		return false, nil, err
+	}
+
+	canTransfer := true
+	loadings := make(map[int]float64)
+
+	for powerNodeId, capacityKw := range sourceCapacityKw {
+		kw, exists := loads[powerNodeId]
+		if !exists {
+			continue
+		}
+
+		if kw > capacityKw {
+			canTransfer = false
+		}
+
+		if capacityKw > 0 {
+			loadings[powerNodeId] = kw / capacityKw
+		}
+	}
+
+	return canTransfer, loadings, nil
+}
+
+// LoadPerSourceAfterTransfer returns the load in kW supplied by every power node (PowerNodeId -> kW), see LoadSuppliedBy,
+// after opening one switch and closing another on a copy of the topology. The topology is not changed
+func (t *TopologyGridStruct) LoadPerSourceAfterTransfer(openSwitchId, closeSwitchId int) (map[int]float64, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.loadPerSourceAfterTransfer(openSwitchId, closeSwitchId)
+}
+
+func (t *TopologyGridStruct) loadPerSourceAfterTransfer(openSwitchId, closeSwitchId int) (map[int]float64, error) {
+	for _, equipmentId := range []int{openSwitchId, closeSwitchId} {
+		equipment, exists := t.equipment[equipmentId]
+		if !exists {
+			return nil, equipmentNotFound(equipmentId)
+		}
+
+		if !isSwitchType(equipment.typeId) {
+			return nil, &IdError{Err: ErrEquipmentIsNotSwitch, Id: equipmentId}
+		}
+	}
+
+	transfer := t.clone()
+
+	if err := transfer.setSwitchState(openSwitchId, SwitchStateOpen); err != nil {
+		return nil, err
+	}
+
+	if err := transfer.setSwitchState(closeSwitchId, SwitchStateClose); err != nil {
+		return nil, err
+	}
+
+	loads := make(map[int]float64, len(transfer.nodeIdArrayFromEquipmentTypeId[TypePower]))
+	for _, nodeIdOfPowerNode := range transfer.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		kw, err := transfer.loadSuppliedBy(nodeIdOfPowerNode)
+		if err != nil {
+			return nil, err
+		}
+
+		loads[nodeIdOfPowerNode] = kw
+	}
+
+	return loads, nil
+}
+
+// totalLoad returns the sum of loads of the equipment
+func (t *TopologyGridStruct) totalLoad(equipmentIds map[int]bool) float64 {
+	var kw float64
+	for equipmentId := range equipmentIds {
+		kw += t.equipment[equipmentId].loadKw
+	}
+
+	return kw
+}
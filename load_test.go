@@ -0,0 +1,126 @@
+package topogrid
+
+import (
+	"errors"
+	"maps"
+	"testing"
+)
+
+// setRingLoads sets the loads of consumers C1, C2 and C3 of newRingGrid in kW
+func setRingLoads(t testing.TB, topology *TopologyGridStruct, c1, c2, c3 float64) {
+	t.Helper()
+
+	mustSucceed(t, topology.SetEquipmentLoad(103, c1))
+	mustSucceed(t, topology.SetEquipmentLoad(104, c2))
+	mustSucceed(t, topology.SetEquipmentLoad(105, c3))
+}
+
+func TestEquipmentLoad(t *testing.T) {
+	topology := newRingGrid(t)
+
+	if kw, err := topology.EquipmentLoad(104); err != nil || kw != 0 {
+		t.Fatalf("EquipmentLoad of a consumer without load = %g, %v, want 0", kw, err)
+	}
+
+	mustSucceed(t, topology.SetEquipmentLoad(104, 50))
+
+	if kw, err := topology.EquipmentLoad(104); err != nil || kw != 50 {
+		t.Fatalf("EquipmentLoad(104) = %g, %v, want 50", kw, err)
+	}
+
+	if err := topology.SetEquipmentLoad(104, -1); !errors.Is(err, ErrNegativeLoad) {
+		t.Fatalf("SetEquipmentLoad with a negative load returns %v, want ErrNegativeLoad", err)
+	}
+
+	if err := topology.SetEquipmentLoad(101, 10); !errors.Is(err, ErrEquipmentIsNotConsumer) {
+		t.Fatalf("SetEquipmentLoad of a power node returns %v, want ErrEquipmentIsNotConsumer", err)
+	}
+
+	if _, err := topology.EquipmentLoad(999); !errors.Is(err, ErrEquipmentNotFound) {
+		t.Fatalf("EquipmentLoad of an unknown equipment returns %v, want ErrEquipmentNotFound", err)
+	}
+}
+
+func TestLoadSuppliedBy(t *testing.T) {
+	topology := newRingGrid(t)
+	setRingLoads(t, topology, 100, 50, 30)
+
+	for powerNodeId, want := range map[int]float64{1: 150, 7: 30} {
+		if kw, err := topology.LoadSuppliedBy(powerNodeId); err != nil || kw != want {
+			t.Fatalf("LoadSuppliedBy(%d) = %g, %v, want %g", powerNodeId, kw, err, want)
+		}
+	}
+
+	// With the ring closed every consumer is attributed in full to both power nodes
+	mustSucceed(t, topology.SetSwitchState(140, SwitchStateClose))
+
+	for _, powerNodeId := range []int{1, 7} {
+		if kw, err := topology.LoadSuppliedBy(powerNodeId); err != nil || kw != 180 {
+			t.Fatalf("LoadSuppliedBy(%d) of a closed ring = %g, %v, want 180", powerNodeId, kw, err)
+		}
+	}
+}
+
+func TestLoadDownstreamOfSwitch(t *testing.T) {
+	topology := newRingGrid(t)
+	setRingLoads(t, topology, 100, 50, 30)
+
+	for edgeId, want := range map[int]float64{10: 150, 30: 50, 60: 30} {
+		if kw, err := topology.LoadDownstreamOfSwitch(edgeId); err != nil || kw != want {
+			t.Fatalf("LoadDownstreamOfSwitch(%d) = %g, %v, want %g", edgeId, kw, err, want)
+		}
+	}
+
+	// Consumers of the parallel lines keep supply if one of the line circuit breakers opens
+	parallel := newParallelGrid(t)
+	mustSucceed(t, parallel.SetEquipmentLoad(105, 40))
+
+	for edgeId, want := range map[int]float64{10: 0, 20: 0, 50: 40} {
+		if kw, err := parallel.LoadDownstreamOfSwitch(edgeId); err != nil || kw != want {
+			t.Fatalf("LoadDownstreamOfSwitch(%d) of the parallel lines = %g, %v, want %g", edgeId, kw, err, want)
+		}
+	}
+}
+
+func TestCanTransferLoad(t *testing.T) {
+	topology := newRingGrid(t)
+	setRingLoads(t, topology, 100, 50, 30)
+
+	// Moving the open point from CB40 to DS30 transfers C2 to P2
+	loads, err := topology.LoadPerSourceAfterTransfer(130, 140)
+	mustSucceed(t, err)
+
+	if want := map[int]float64{1: 100, 7: 80}; !maps.Equal(loads, want) {
+		t.Fatalf("LoadPerSourceAfterTransfer = %v, want %v", loads, want)
+	}
+
+	for _, test := range []struct {
+		name             string
+		sourceCapacityKw map[int]float64
+		canTransfer      bool
+		loadings         map[int]float64
+	}{
+		{"both power nodes within capacity", map[int]float64{1: 200, 7: 100}, true, map[int]float64{1: 0.5, 7: 0.8}},
+		{"overloaded power node", map[int]float64{1: 200, 7: 50}, false, map[int]float64{1: 0.5, 7: 1.6}},
+		{"power node without capacity is not checked", map[int]float64{7: 160}, true, map[int]float64{7: 0.5}},
+		{"zero capacity has no loading", map[int]float64{1: 0, 7: 100}, false, map[int]float64{7: 0.8}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			canTransfer, loadings, err := topology.CanTransferLoad(130, 140, test.sourceCapacityKw)
+			mustSucceed(t, err)
+
+			if canTransfer != test.canTransfer || !maps.Equal(loadings, test.loadings) {
+				t.Fatalf("CanTransferLoad = %v, %v, want %v, %v", canTransfer, loadings, test.canTransfer, test.loadings)
+			}
+		})
+	}
+
+	// The topology is not changed
+	if kw, err := topology.LoadSuppliedBy(7); err != nil || kw != 30 {
+		t.Fatalf("LoadSuppliedBy(7) after CanTransferLoad = %g, %v, want 30", kw, err)
+	}
+
+	if _, _, err := topology.CanTransferLoad(120, 140, nil); !errors.Is(err, ErrEquipmentIsNotSwitch) {
+		t.Fatalf("CanTransferLoad opening a line returns %v, want ErrEquipmentIsNotSwitch", err)
+	}
+}
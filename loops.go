@@ -0,0 +1,186 @@
+package topogrid
+
+import (
+	"sort"
+)
+
+// IsRadial returns true if there are no loops in the current topology graph
+func (t *TopologyGridStruct) IsRadial() bool {
+	return len(t.FindLoops()) == 0
+}
+
+// FindLoops returns independent loops of the current topology graph as sequences of edge ids.
+// Each loop starts with the edge closing it, parallel edges between the same two nodes are reported
+// as a loop of length two
+func (t *TopologyGridStruct) FindLoops() [][]int {
+	t.RLock()
+	defer t.RUnlock()
+
+	adjacency := t.edgeAdjacency(t.isEdgeClosed)
+
+	parentEdgeIdx := make([]int, t.nodeIdx)
+	depth := make([]int, t.nodeIdx)
+	visited := make([]bool, t.nodeIdx)
+	treeEdges := make(map[int]bool)
+
+	for root := 0; root < t.nodeIdx; root++ {
+		if visited[root] {
+			continue
+		}
+
+		visited[root] = true
+		parentEdgeIdx[root] = -1
+		queue := []int{root}
+
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+
+			for _, edgeIdx := range adjacency[v] {
+				w := t.otherTerminalIdx(t.edges[edgeIdx], v)
+				if visited[w] {
+					continue
+				}
+
+				visited[w] = true
+				parentEdgeIdx[w] = edgeIdx
+				depth[w] = depth[v] + 1
+				treeEdges[edgeIdx] = true
+				queue = append(queue, w)
+			}
+		}
+	}
+
+	loops := make([][]int, 0)
+	counted := make(map[int]bool)
+
+	for v := 0; v < t.nodeIdx; v++ {
+		for _, edgeIdx := range adjacency[v] {
+			if treeEdges[edgeIdx] || counted[edgeIdx] {
+				continue
+			}
+			counted[edgeIdx] = true
+
+			edge := t.edges[edgeIdx]
+			u := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+			w := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+
+			// Walk up from both terminals to the common ancestor in the spanning tree
+			var pathU, pathW []int
+			for u != w {
+				if depth[u] >= depth[w] {
+					pathU = append(pathU, parentEdgeIdx[u])
+					u = t.otherTerminalIdx(t.edges[parentEdgeIdx[u]], u)
+				} else {
+					pathW = append(pathW, parentEdgeIdx[w])
+					w = t.otherTerminalIdx(t.edges[parentEdgeIdx[w]], w)
+				}
+			}
+
+			loop := []int{edge.id}
+			for _, idx := range pathW {
+				loop = append(loop, t.edges[idx].id)
+			}
+			for i := len(pathU) - 1; i >= 0; i-- {
+				loop = append(loop, t.edges[pathU[i]].id)
+			}
+
+			loops = append(loops, loop)
+		}
+	}
+
+	return loops
+}
+
+// FindBridges returns sorted edge ids whose opening splits an island of the current topology graph.
+// Parallel edges between the same two nodes are not bridges
+func (t *TopologyGridStruct) FindBridges() []int {
+	t.RLock()
+	defer t.RUnlock()
+
+	bridges, _ := t.bridgesAndArticulationNodes()
+
+	return bridges
+}
+
+// FindArticulationNodes returns sorted node ids whose removal splits an island of the current topology graph
+func (t *TopologyGridStruct) FindArticulationNodes() []int {
+	t.RLock()
+	defer t.RUnlock()
+
+	_, articulationNodes := t.bridgesAndArticulationNodes()
+
+	return articulationNodes
+}
+
+// bridgesAndArticulationNodes finds bridges and articulation nodes of the current topology graph
+// with the lowlink algorithm. The edge the node is entered by is skipped instead of the parent node,
+// so parallel edges form a cycle
+func (t *TopologyGridStruct) bridgesAndArticulationNodes() ([]int, []int) {
+	adjacency := t.edgeAdjacency(t.isEdgeClosed)
+
+	order := make([]int, t.nodeIdx)
+	lowlink := make([]int, t.nodeIdx)
+	counter := 0
+
+	bridges := make([]int, 0)
+	isArticulation := make([]bool, t.nodeIdx)
+
+	var visit func(v int, parentEdgeIdx int)
+	visit = func(v int, parentEdgeIdx int) {
+		counter++
+		order[v] = counter
+		lowlink[v] = counter
+		children := 0
+
+		for _, edgeIdx := range adjacency[v] {
+			if edgeIdx == parentEdgeIdx {
+				continue
+			}
+
+			w := t.otherTerminalIdx(t.edges[edgeIdx], v)
+			if w == v {
+				continue
+			}
+
+			if order[w] != 0 {
+				lowlink[v] = min(lowlink[v], order[w])
+				continue
+			}
+
+			children++
+			visit(w, edgeIdx)
+			lowlink[v] = min(lowlink[v], lowlink[w])
+
+			if lowlink[w] > order[v] {
+				bridges = append(bridges, t.edges[edgeIdx].id)
+			}
+
+			if parentEdgeIdx >= 0 && lowlink[w] >= order[v] {
+				isArticulation[v] = true
+			}
+		}
+
+		if parentEdgeIdx < 0 && children > 1 {
+			isArticulation[v] = true
+		}
+	}
+
+	for v := 0; v < t.nodeIdx; v++ {
+		if order[v] == 0 {
+			visit(v, -1)
+		}
+	}
+
+	articulationNodes := make([]int, 0)
+	for v, articulation := range isArticulation {
+		if articulation {
+			articulationNodes = append(articulationNodes, t.nodes[v].id)
+		}
+	}
+
+	sort.Ints(bridges)
+	sort.Ints(articulationNodes)
+
+	return bridges, articulationNodes
+}
@@ -0,0 +1,77 @@
+package topogrid
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// EdgeList returns arcs of the current or the full topology graph as (node1Idx, node2Idx, cost) triples, where
+// the cost is the number of circuit breakers and fuses. Every pair of connected nodes is listed once with
+// node1Idx <= node2Idx, triples are sorted by node indexes. Use NodeIdsByIdx to map node indexes to node ids
+func (t *TopologyGridStruct) EdgeList(useFullGraph bool) [][3]int64 {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.edgeList(useFullGraph)
+}
+
+func (t *TopologyGridStruct) edgeList(useFullGraph bool) [][3]int64 {
+	g := t.currentGraph
+	if useFullGraph {
+		g = t.fullGraph
+	}
+
+	edgeList := make([][3]int64, 0)
+
+	for v := 0; v < t.nodeIdx; v++ {
+		g.Visit(v, func(w int, c int64) bool {
+			if v <= w {
+				edgeList = append(edgeList, [3]int64{int64(v), int64(w), c})
+			}
+			return false
+		})
+	}
+
+	sort.Slice(edgeList, func(i, j int) bool {
+		if edgeList[i][0] != edgeList[j][0] {
+			return edgeList[i][0] < edgeList[j][0]
+		}
+		return edgeList[i][1] < edgeList[j][1]
+	})
+
+	return edgeList
+}
+
+// NodeIdsByIdx returns node ids by node indexes used by EdgeList and WriteMatrixMarket
+func (t *TopologyGridStruct) NodeIdsByIdx() []int {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIds := make([]int, 0, t.nodeIdx)
+	for _, node := range t.nodes[:t.nodeIdx] {
+		nodeIds = append(nodeIds, node.id)
+	}
+
+	return nodeIds
+}
+
+// WriteMatrixMarket writes the adjacency matrix of the current or the full topology graph in the MatrixMarket
+// coordinate pattern symmetric format: one entry per pair of connected nodes in the lower triangle, rows and
+// columns are node indexes plus one. Use NodeIdsByIdx to map node indexes to node ids, EdgeList for the costs
+func (t *TopologyGridStruct) WriteMatrixMarket(w io.Writer, useFullGraph bool) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	edgeList := t.edgeList(useFullGraph)
+	writer := bufio.NewWriter(w)
+
+	_, _ = fmt.Fprintf(writer, "%%%%MatrixMarket matrix coordinate pattern symmetric\n%d %d %d\n", t.nodeIdx, t.nodeIdx, len(edgeList))
+
+	for _, arc := range edgeList {
+		_, _ = fmt.Fprintf(writer, "%d %d\n", arc[1]+1, arc[0]+1)
+	}
+
+	return writer.Flush()
+}
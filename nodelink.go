@@ -0,0 +1,92 @@
+package topogrid
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+type nodeLinkDocument struct {
+	Directed   bool           `json:"directed"`
+	Multigraph bool           `json:"multigraph"`
+	Nodes      []nodeLinkNode `json:"nodes"`
+	Links      []nodeLinkLink `json:"links"`
+}
+
+type nodeLinkNode struct {
+	Id              int    `json:"id"`
+	EquipmentId     int    `json:"equipmentId"`
+	Name            string `json:"name"`
+	TypeId          int    `json:"typeId"`
+	ElectricalState uint8  `json:"electricalState"`
+}
+
+type nodeLinkLink struct {
+	Id              int    `json:"id"`
+	Source          int    `json:"source"`
+	Target          int    `json:"target"`
+	SwitchState     int    `json:"switchState"`
+	EquipmentId     int    `json:"equipmentId"`
+	Name            string `json:"name"`
+	TypeId          int    `json:"typeId"`
+	ElectricalState uint8  `json:"electricalState"`
+}
+
+// GetAsNodeLinkJSON returns the topology in the node-link JSON format read by D3, Cytoscape.js and NetworkX:
+// nodes with the equipment id, name, type id and electrical state, and links between node ids with the edge id,
+// switch state and the equipment of the edge. Nodes and links are sorted by id
+func (t *TopologyGridStruct) GetAsNodeLinkJSON() ([]byte, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	return json.Marshal(t.nodeLinkDocument())
+}
+
+// WriteNodeLinkJSON writes the document returned by GetAsNodeLinkJSON into w followed by a newline
+func (t *TopologyGridStruct) WriteNodeLinkJSON(w io.Writer) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	return writeBuffered(w, func(writer exportWriter) error {
+		return json.NewEncoder(writer).Encode(t.nodeLinkDocument())
+	})
+}
+
+// nodeLinkDocument returns the node-link document of the graph
+func (t *TopologyGridStruct) nodeLinkDocument() nodeLinkDocument {
+	document := nodeLinkDocument{
+		Multigraph: true,
+		Nodes:      make([]nodeLinkNode, 0, t.nodeIdx),
+		Links:      make([]nodeLinkLink, 0, len(t.edges)),
+	}
+
+	for _, node := range t.nodes[:t.nodeIdx] {
+		document.Nodes = append(document.Nodes, nodeLinkNode{
+			Id:              node.id,
+			EquipmentId:     node.equipmentId,
+			Name:            t.equipment[node.equipmentId].name,
+			TypeId:          t.nodeTypeId(node),
+			ElectricalState: node.electricalState,
+		})
+	}
+
+	for _, edge := range t.edges {
+		typeId, state := t.edgeState(edge)
+
+		document.Links = append(document.Links, nodeLinkLink{
+			Id:              edge.id,
+			Source:          edge.terminal.node1Id,
+			Target:          edge.terminal.node2Id,
+			SwitchState:     state,
+			EquipmentId:     edge.equipmentId,
+			Name:            t.equipment[edge.equipmentId].name,
+			TypeId:          typeId,
+			ElectricalState: t.equipment[edge.equipmentId].electricalState,
+		})
+	}
+
+	sort.Slice(document.Nodes, func(i, j int) bool { return document.Nodes[i].Id < document.Nodes[j].Id })
+	sort.Slice(document.Links, func(i, j int) bool { return document.Links[i].Id < document.Links[j].Id })
+
+	return document
+}
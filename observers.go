@@ -0,0 +1,54 @@
+package topogrid
+
+// EquipmentStateObserver is called with the old and the new electrical state of the equipment
+type EquipmentStateObserver func(equipmentId int, oldState uint8, newState uint8)
+
+type equipmentStateObserverEntry struct {
+	id       int
+	observer EquipmentStateObserver
+}
+
+// OnEquipmentStateChange registers the observer called by SetEquipmentElectricalState for every equipment
+// whose electrical state has changed. Observers are called in the registration order after the topology is unlocked,
+// so they may call any method of the topology. Returns a function that unregisters the observer
+func (t *TopologyGridStruct) OnEquipmentStateChange(observer EquipmentStateObserver) func() {
+	t.observerMutex.Lock()
+	defer t.observerMutex.Unlock()
+
+	t.observerId++
+	id := t.observerId
+	t.observers = append(t.observers, equipmentStateObserverEntry{id: id, observer: observer})
+
+	return func() {
+		t.observerMutex.Lock()
+		defer t.observerMutex.Unlock()
+
+		for i, entry := range t.observers {
+			if entry.id == id {
+				t.observers = append(t.observers[:i:i], t.observers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// notifyObservers calls registered observers for changes of the electrical state. Must be called without the lock held
+func (t *TopologyGridStruct) notifyObservers(changes []EquipmentStateChange) {
+	t.observerMutex.Lock()
+	observers := t.observers
+	t.observerMutex.Unlock()
+
+	if len(observers) == 0 {
+		return
+	}
+
+	for _, change := range changes {
+		if change.OldState == change.NewState {
+			continue
+		}
+
+		for _, entry := range observers {
+			entry.observer(change.EquipmentId, change.OldState, change.NewState)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package topogrid
+
+import (
+	"math"
+
+	"github.com/yourbasic/graph"
+)
+
+// SuggestOpenPoint returns the equipment id of the switch on the ring between two power nodes whose opening best
+// balances the load between them, see SetEquipmentLoad, or the number of consumers if no load is set. The ring is
+// the shortest path between the power nodes in the full topology graph. Every switch on the ring is tried as the only
+// open point with all other switches on the ring closed, ties are broken by the lowest equipment id.
+// Returns PathError with ErrNoRing if the power nodes are not connected by a path with a switch that separates them.
+// The topology is not changed
+func (t *TopologyGridStruct) SuggestOpenPoint(powerNodeId1, powerNodeId2 int) (int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	powerNode1Idx, exists := t.nodeIdxFromNodeId[powerNodeId1]
+	if !exists {
+		return 0, nodeNotFound(powerNodeId1)
+	}
+
+	powerNode2Idx, exists := t.nodeIdxFromNodeId[powerNodeId2]
+	if !exists {
+		return 0, nodeNotFound(powerNodeId2)
+	}
+
+	noRing := &PathError{Err: ErrNoRing, FromNodeId: powerNodeId1, ToNodeId: powerNodeId2}
+
+	path, _ := graph.ShortestPath(t.fullGraph, powerNode1Idx, powerNode2Idx)
+	if len(path) < 2 {
+		return 0, noRing
+	}
+
+	ring := t.clone()
+	switchIds := make([]int, 0)
+	isOnRing := make(map[int]bool)
+
+	for i := 1; i < len(path); i++ {
+		for _, edgeId := range t.edgeIdsBetweenNodes(t.nodes[path[i-1]].id, t.nodes[path[i]].id) {
+			edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+
+			if typeId, _ := t.edgeState(edge); !isSwitchType(typeId) || typeId == TypeGroundSwitch ||
+				edge.equipmentId == 0 || t.isEdgeFaulted(edge) || isOnRing[edge.equipmentId] {
+				continue
+			}
+
+			isOnRing[edge.equipmentId] = true
+			switchIds = append(switchIds, edge.equipmentId)
+
+			if err := ring.setSwitchState(edge.equipmentId, SwitchStateClose); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	useLoad := false
+	for _, equipment := range t.equipment {
+		if equipment.loadKw > 0 {
+			useLoad = true
+			break
+		}
+	}
+
+	openPointId := 0
+	bestImbalance := math.Inf(1)
+
+	for _, switchId := range switchIds {
+		if err := ring.setSwitchState(switchId, SwitchStateOpen); err != nil {
+			return 0, err
+		}
+
+		consumers1, _ := ring.consumerIdSetPoweredBy(powerNodeId1)
+		consumers2, _ := ring.consumerIdSetPoweredBy(powerNodeId2)
+
+		reachable := ring.reachableFromNode(powerNode1Idx)
+
+		if err := ring.setSwitchState(switchId, SwitchStateClose); err != nil {
+			return 0, err
+		}
+
+		if reachable[powerNode2Idx] {
+			continue
+		}
+
+		imbalance := math.Abs(float64(len(consumers1) - len(consumers2)))
+		if useLoad {
+			imbalance = math.Abs(ring.totalLoad(consumers1) - ring.totalLoad(consumers2))
+		}
+
+		if imbalance < bestImbalance || (imbalance == bestImbalance && switchId < openPointId) {
+			openPointId = switchId
+			bestImbalance = imbalance
+		}
+	}
+
+	if openPointId == 0 {
+		return 0, noRing
+	}
+
+	return openPointId, nil
+}
+
+// reachableFromNode returns node indexes reachable from the node in the current topology graph
+func (t *TopologyGridStruct) reachableFromNode(nodeIdx int) []bool {
+	reachable := make([]bool, t.currentGraph.Order())
+	reachable[nodeIdx] = true
+
+	graph.BFS(t.currentGraph, nodeIdx, func(v, w int, c int64) {
+		reachable[w] = true
+	})
+
+	return reachable
+}
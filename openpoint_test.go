@@ -0,0 +1,54 @@
+package topogrid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSuggestOpenPoint(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		c1, c2, c3 float64
+		want       int
+	}{
+		// Without loads DS30 and CB40 both leave one consumer of imbalance, the lowest equipment id wins
+		{"consumers", 0, 0, 0, 130},
+		{"load balanced at DS30", 100, 50, 30, 130},
+		{"load balanced at CB40", 10, 50, 30, 140},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			topology := newRingGrid(t)
+			setRingLoads(t, topology, test.c1, test.c2, test.c3)
+
+			openPointId, err := topology.SuggestOpenPoint(1, 7)
+			mustSucceed(t, err)
+
+			if openPointId != test.want {
+				t.Fatalf("SuggestOpenPoint(1, 7) = %d, want %d", openPointId, test.want)
+			}
+
+			// The topology is not changed
+			if state, _ := topology.EquipmentSwitchStateByEquipmentId(140); state != SwitchStateOpen {
+				t.Fatalf("SuggestOpenPoint changed the state of CB40 to %d", state)
+			}
+		})
+	}
+}
+
+func TestSuggestOpenPointNoRing(t *testing.T) {
+	// Power nodes connected by a line without switches
+	topology := NewDynamic()
+	mustSucceed(t, topology.AddNode(1, 101, TypePower, "P1"))
+	mustSucceed(t, topology.AddNode(2, 102, TypePower, "P2"))
+	mustSucceed(t, topology.AddEdge(10, 1, 2, SwitchStateClose, 110, TypeLine, "LN10"))
+
+	var pathErr *PathError
+	if _, err := topology.SuggestOpenPoint(1, 2); !errors.As(err, &pathErr) || !errors.Is(err, ErrNoRing) ||
+		pathErr.FromNodeId != 1 || pathErr.ToNodeId != 2 {
+		t.Fatalf("SuggestOpenPoint of power nodes without switches between them returns %v, want ErrNoRing", err)
+	}
+
+	if _, err := newRingGrid(t).SuggestOpenPoint(1, 99); !errors.Is(err, ErrNodeNotFound) {
+		t.Fatalf("SuggestOpenPoint to an unknown node returns %v, want ErrNodeNotFound", err)
+	}
+}
@@ -0,0 +1,85 @@
+package topogrid
+
+import (
+	"sort"
+)
+
+// Report is the outcome of switch changes used for outage notifications
+type Report struct {
+	LostSupply        []int   // Sorted ids of consumers that would lose supply
+	RestoredSupply    []int   // Sorted ids of consumers that would regain supply
+	CreatedIslands    [][]int // Node ids of islands split off from a single island, see GetIslands
+	MergedIslands     [][]int // Node ids of islands joining nodes of more than one island, see GetIslands
+	AffectedEquipment int     // Number of equipment with a changed electrical state
+}
+
+// OutageReport returns consumers losing and regaining supply, islands created or merged and the number of affected
+// equipment if the switch changes (EquipmentId -> switch state) were applied. Electrical states are calculated
+// by SetEquipmentElectricalState before and after the changes on a copy of the topology, so the topology is not changed.
+// The changes are validated like ApplySwitchStates
+func (t *TopologyGridStruct) OutageReport(changes map[int]int) (Report, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	outage := t.clone()
+	outage.setEquipmentElectricalState()
+
+	islandsBefore := outage.islands(outage.currentGraph)
+
+	if _, err := outage.applySwitchStates(changes); err != nil {
+		return Report{}, err
+	}
+
+	report := Report{
+		LostSupply:     make([]int, 0),
+		RestoredSupply: make([]int, 0),
+		CreatedIslands: make([][]int, 0),
+		MergedIslands:  make([][]int, 0),
+	}
+
+	for _, change := range outage.setEquipmentElectricalState() {
+		if change.OldState == change.NewState {
+			continue
+		}
+
+		report.AffectedEquipment++
+
+		if outage.equipment[change.EquipmentId].typeId != TypeConsumer {
+			continue
+		}
+
+		wasEnergized := change.OldState&StateEnergized != 0
+		isEnergized := change.NewState&StateEnergized != 0
+
+		if wasEnergized && !isEnergized {
+			report.LostSupply = append(report.LostSupply, change.EquipmentId)
+		} else if !wasEnergized && isEnergized {
+			report.RestoredSupply = append(report.RestoredSupply, change.EquipmentId)
+		}
+	}
+
+	islandFromNodeId := make(map[int]int)
+	for island, nodeIds := range islandsBefore {
+		for _, nodeId := range nodeIds {
+			islandFromNodeId[nodeId] = island
+		}
+	}
+
+	for _, nodeIds := range outage.islands(outage.currentGraph) {
+		joined := make(map[int]bool)
+		for _, nodeId := range nodeIds {
+			joined[islandFromNodeId[nodeId]] = true
+		}
+
+		if len(joined) > 1 {
+			report.MergedIslands = append(report.MergedIslands, nodeIds)
+		} else if len(nodeIds) < len(islandsBefore[islandFromNodeId[nodeIds[0]]]) {
+			report.CreatedIslands = append(report.CreatedIslands, nodeIds)
+		}
+	}
+
+	sort.Ints(report.LostSupply)
+	sort.Ints(report.RestoredSupply)
+
+	return report, nil
+}
@@ -0,0 +1,88 @@
+package topogrid
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOutageReport(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		changes map[int]int
+		want    Report
+	}{
+		{
+			// C2 is cut off from P1, DS30 and the open CB40 lose the energized terminal
+			name:    "open the disconnect switch",
+			changes: map[int]int{130: SwitchStateOpen},
+			want: Report{
+				LostSupply:        []int{104},
+				RestoredSupply:    []int{},
+				CreatedIslands:    [][]int{{1, 2, 3}, {4}},
+				MergedIslands:     [][]int{},
+				AffectedEquipment: 3,
+			},
+		},
+		{
+			// C2 is transferred to P2 without losing supply
+			name:    "move the open point",
+			changes: map[int]int{130: SwitchStateOpen, 140: SwitchStateClose},
+			want: Report{
+				LostSupply:        []int{},
+				RestoredSupply:    []int{},
+				CreatedIslands:    [][]int{{1, 2, 3}},
+				MergedIslands:     [][]int{{4, 5, 6, 7}},
+				AffectedEquipment: 0,
+			},
+		},
+		{
+			name:    "no change",
+			changes: map[int]int{140: SwitchStateOpen},
+			want: Report{
+				LostSupply:     []int{},
+				RestoredSupply: []int{},
+				CreatedIslands: [][]int{},
+				MergedIslands:  [][]int{},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			topology := newRingGrid(t)
+			islands := topology.GetIslands()
+
+			report, err := topology.OutageReport(test.changes)
+			mustSucceed(t, err)
+
+			if !reflect.DeepEqual(report, test.want) {
+				t.Fatalf("OutageReport(%v) = %+v, want %+v", test.changes, report, test.want)
+			}
+
+			if !reflect.DeepEqual(topology.GetIslands(), islands) {
+				t.Fatalf("OutageReport(%v) changed the topology", test.changes)
+			}
+		})
+	}
+}
+
+func TestOutageReportRestoresSupply(t *testing.T) {
+	topology := newRingGrid(t)
+	mustSucceed(t, topology.SetSwitchState(110, SwitchStateOpen))
+	topology.SetEquipmentElectricalState()
+
+	// Closing the normally open point restores C1 and C2 from P2 and joins them with the island of P2
+	report, err := topology.OutageReport(map[int]int{140: SwitchStateClose})
+	mustSucceed(t, err)
+
+	if want := []int{103, 104}; !reflect.DeepEqual(report.RestoredSupply, want) {
+		t.Fatalf("OutageReport RestoredSupply = %v, want %v", report.RestoredSupply, want)
+	}
+
+	if want := [][]int{{2, 3, 4, 5, 6, 7}}; !reflect.DeepEqual(report.MergedIslands, want) {
+		t.Fatalf("OutageReport MergedIslands = %v, want %v", report.MergedIslands, want)
+	}
+
+	if _, err := topology.OutageReport(map[int]int{140: 7}); !errors.Is(err, ErrInvalidSwitchState) {
+		t.Fatalf("OutageReport with an invalid switch state returns %v, want ErrInvalidSwitchState", err)
+	}
+}
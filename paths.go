@@ -0,0 +1,247 @@
+package topogrid
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yourbasic/graph"
+)
+
+// SupplyPath returns the ordered arrays of node ids and edge ids along the shortest path in the current topology graph
+// from the power node to the node. Returns PathError with ErrNoPath if the node is not supplied by the power node
+func (t *TopologyGridStruct) SupplyPath(nodeId int, powerNodeId int) ([]int, []int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.supplyPath(nodeId, powerNodeId)
+}
+
+func (t *TopologyGridStruct) supplyPath(nodeId int, powerNodeId int) ([]int, []int, error) {
+	nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
+	if !exists {
+		return nil, nil, nodeNotFound(nodeId)
+	}
+
+	powerNodeIdx, exists := t.nodeIdxFromNodeId[powerNodeId]
+	if !exists {
+		return nil, nil, nodeNotFound(powerNodeId)
+	}
+
+	path, _ := graph.ShortestPath(t.currentGraph, powerNodeIdx, nodeIdx)
+	if len(path) == 0 {
+		return nil, nil, &PathError{Err: ErrNoPath, FromNodeId: powerNodeId, ToNodeId: nodeId}
+	}
+
+	nodeIds := make([]int, 0, len(path))
+	edgeIds := make([]int, 0, len(path)-1)
+
+	for i, idx := range path {
+		nodeIds = append(nodeIds, t.nodes[idx].id)
+
+		if i > 0 {
+			if edge, exists := t.closedEdgeBetween(t.nodes[path[i-1]].id, t.nodes[idx].id); exists {
+				edgeIds = append(edgeIds, edge.id)
+			}
+		}
+	}
+
+	return nodeIds, edgeIds, nil
+}
+
+// SetEdgeLength sets the line length of the edge in meters. Edges are added with zero length.
+// Returns ErrNegativeLength if the length is negative
+func (t *TopologyGridStruct) SetEdgeLength(edgeId int, meters float64) error {
+	t.Lock()
+	defer t.Unlock()
+
+	edgeIdx, exists := t.edgeIdxFromEdgeId[edgeId]
+	if !exists {
+		return edgeNotFound(edgeId)
+	}
+
+	if meters < 0 {
+		return fmt.Errorf("%w: %g for edge id %d", ErrNegativeLength, meters, edgeId)
+	}
+
+	t.edges[edgeIdx].lengthMeters = meters
+	t.recordChange(ChangeEdgeData, edgeId)
+
+	return nil
+}
+
+// DistanceToSource returns the sum of line lengths in meters along the supply path returned by SupplyPath
+// from the power node to the node. Returns PathError with ErrNoPath if the node is not supplied by the power node
+func (t *TopologyGridStruct) DistanceToSource(nodeId int, powerNodeId int) (float64, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	_, edgeIds, err := t.supplyPath(nodeId, powerNodeId)
+	if err != nil {
+		return 0, err
+	}
+
+	var meters float64
+	for _, edgeId := range edgeIds {
+		meters += t.edges[t.edgeIdxFromEdgeId[edgeId]].lengthMeters
+	}
+
+	return meters, nil
+}
+
+// closedEdgeBetween returns the closed edge with the lowest cost connecting two nodes,
+// i.e. the edge the arc of the current topology graph is built from
+func (t *TopologyGridStruct) closedEdgeBetween(node1Id int, node2Id int) (EdgeStruct, bool) {
+	var closedEdge EdgeStruct
+	var closedEdgeCost int64 = -1
+
+	for _, edgeId := range t.edgeIdsBetweenNodes(node1Id, node2Id) {
+		edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+
+		if !t.isEdgeClosed(edge) {
+			continue
+		}
+
+		typeId, _ := t.edgeState(edge)
+
+		if cost := edgeCost(typeId); closedEdgeCost < 0 || cost < closedEdgeCost {
+			closedEdge = edge
+			closedEdgeCost = cost
+		}
+	}
+
+	return closedEdge, closedEdgeCost >= 0
+}
+
+// SwitchDistance returns the number of circuit breakers on the cheapest path between two nodes in the current
+// or the full topology graph. Returns PathError with ErrNoPath if the nodes are not connected
+func (t *TopologyGridStruct) SwitchDistance(nodeId1 int, nodeId2 int, useFullGraph bool) (int64, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	node1Idx, exists := t.nodeIdxFromNodeId[nodeId1]
+	if !exists {
+		return 0, nodeNotFound(nodeId1)
+	}
+
+	node2Idx, exists := t.nodeIdxFromNodeId[nodeId2]
+	if !exists {
+		return 0, nodeNotFound(nodeId2)
+	}
+
+	g := t.currentGraph
+	if useFullGraph {
+		g = t.fullGraph
+	}
+
+	path, numberOfSwitches := graph.ShortestPath(g, node1Idx, node2Idx)
+	if len(path) == 0 {
+		return 0, &PathError{Err: ErrNoPath, FromNodeId: nodeId1, ToNodeId: nodeId2}
+	}
+
+	return numberOfSwitches, nil
+}
+
+// AllPaths returns simple paths between the power node and the node in the full topology graph as edge id sequences
+// from the power node, parallel edges give different paths. Paths are found by the depth-first search in the order
+// of edge ids. At most maxPaths paths of at most maxDepth edges are returned, a non-positive limit means no limit.
+// The returned flag is true if the search was truncated: the number of paths reached maxPaths
+// or a branch that still leads to the node was cut by maxDepth
+func (t *TopologyGridStruct) AllPaths(nodeId, powerNodeId int, maxPaths int, maxDepth int) ([][]int, bool, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
+	if !exists {
+		return nil, false, nodeNotFound(nodeId)
+	}
+
+	powerNodeIdx, exists := t.nodeIdxFromNodeId[powerNodeId]
+	if !exists {
+		return nil, false, nodeNotFound(powerNodeId)
+	}
+
+	edges := t.fullGraphEdges()
+	sort.Slice(edges, func(i, j int) bool { return edges[i].id < edges[j].id })
+
+	adjacency := make([][]EdgeStruct, t.nodeIdx)
+	for _, edge := range edges {
+		node1Idx := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+
+		adjacency[node1Idx] = append(adjacency[node1Idx], edge)
+		adjacency[node2Idx] = append(adjacency[node2Idx], edge)
+	}
+
+	paths := make([][]int, 0)
+	truncated := false
+
+	if nodeIdx == powerNodeIdx {
+		return paths, false, nil
+	}
+
+	onPath := make([]bool, t.nodeIdx)
+	path := make([]int, 0)
+
+	// reachesTarget returns true if a path from the node to the target node avoids the nodes of the current path,
+	// i.e. a longer path continues through the node cut by maxDepth
+	reachesTarget := func(v int) bool {
+		reached := map[int]bool{v: true}
+
+		for queue := []int{v}; len(queue) > 0; queue = queue[1:] {
+			if queue[0] == nodeIdx {
+				return true
+			}
+
+			for _, edge := range adjacency[queue[0]] {
+				if w := t.otherTerminalIdx(edge, queue[0]); !reached[w] && !onPath[w] {
+					reached[w] = true
+					queue = append(queue, w)
+				}
+			}
+		}
+
+		return false
+	}
+
+	var visit func(v int) bool
+	visit = func(v int) bool {
+		if v == nodeIdx {
+			if maxPaths > 0 && len(paths) == maxPaths {
+				truncated = true
+				return false
+			}
+
+			paths = append(paths, append([]int{}, path...))
+			return true
+		}
+
+		onPath[v] = true
+		defer func() { onPath[v] = false }()
+
+		for _, edge := range adjacency[v] {
+			w := t.otherTerminalIdx(edge, v)
+			if onPath[w] {
+				continue
+			}
+
+			if maxDepth > 0 && len(path) == maxDepth {
+				truncated = truncated || reachesTarget(w)
+				continue
+			}
+
+			path = append(path, edge.id)
+			proceed := visit(w)
+			path = path[:len(path)-1]
+
+			if !proceed {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	visit(powerNodeIdx)
+
+	return paths, truncated, nil
+}
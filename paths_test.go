@@ -0,0 +1,62 @@
+package topogrid
+
+import (
+	"fmt"
+	"testing"
+)
+
+// newDeadEndGrid returns the topology where the branch through the node 4 leads to the dead end 5:
+//
+//	P1(1) -10- 2 -20- 3
+//	           2 -30- 4 -40- 5
+func newDeadEndGrid(t testing.TB) *TopologyGridStruct {
+	t.Helper()
+
+	topology := New(5)
+
+	mustSucceed(t, topology.AddNode(1, 100, TypePower, "P1"))
+	for id := 2; id <= 5; id++ {
+		mustSucceed(t, topology.AddNode(id, 100+id, TypeLine, fmt.Sprintf("L%d", id)))
+	}
+
+	for _, edge := range [][3]int{{10, 1, 2}, {20, 2, 3}, {30, 2, 4}, {40, 4, 5}} {
+		mustSucceed(t, topology.AddEdge(edge[0], edge[1], edge[2], SwitchStateClose, 100+edge[0], TypeCircuitBreaker, fmt.Sprintf("CB%d", edge[0])))
+	}
+
+	return topology
+}
+
+func TestAllPathsTruncatedByMaxDepth(t *testing.T) {
+	topology := newDeadEndGrid(t)
+
+	paths, truncated, err := topology.AllPaths(3, 1, 0, 2)
+	mustSucceed(t, err)
+
+	// The dead end 5 cut by maxDepth does not lead to the node 3
+	if fmt.Sprint(paths) != "[[10 20]]" || truncated {
+		t.Fatalf("paths %v truncated %v, want [[10 20]] not truncated", paths, truncated)
+	}
+
+	mustSucceed(t, topology.AddEdge(50, 5, 3, SwitchStateClose, 150, TypeCircuitBreaker, "CB50"))
+
+	for _, test := range []struct {
+		maxPaths, maxDepth int
+		paths              string
+		truncated          bool
+	}{
+		{0, 2, "[[10 20]]", true},
+		{0, 3, "[[10 20]]", true},
+		{0, 4, "[[10 20] [10 30 40 50]]", false},
+		{0, 0, "[[10 20] [10 30 40 50]]", false},
+		{1, 0, "[[10 20]]", true},
+		{2, 0, "[[10 20] [10 30 40 50]]", false},
+	} {
+		paths, truncated, err := topology.AllPaths(3, 1, test.maxPaths, test.maxDepth)
+		mustSucceed(t, err)
+
+		if fmt.Sprint(paths) != test.paths || truncated != test.truncated {
+			t.Fatalf("maxPaths %d maxDepth %d: paths %v truncated %v, want %s truncated %v",
+				test.maxPaths, test.maxDepth, paths, truncated, test.paths, test.truncated)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package topogrid
+
+import (
+	"fmt"
+)
+
+// BindPoint binds the SCADA point id to the equipment, so telegrams identifying the equipment by the point id
+// can update it. A point bound before is bound to the new equipment, several points can be bound to one equipment,
+// e.g. the position and the command feedback of a breaker
+func (t *TopologyGridStruct) BindPoint(pointId uint64, equipmentId int) error {
+	t.Lock()
+	defer t.Unlock()
+
+	if _, exists := t.equipment[equipmentId]; !exists {
+		return equipmentNotFound(equipmentId)
+	}
+
+	t.equipmentIdFromPointId[pointId] = equipmentId
+	t.recordChange(ChangeEquipment, equipmentId)
+
+	return nil
+}
+
+// EquipmentIdByPoint returns the equipment id the SCADA point id is bound to and true if the point is bound
+func (t *TopologyGridStruct) EquipmentIdByPoint(pointId uint64) (int, bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	equipmentId, exists := t.equipmentIdFromPointId[pointId]
+
+	return equipmentId, exists
+}
+
+// SetSwitchStateByPoint sets the switch state of the equipment the SCADA point id is bound to like SetSwitchState.
+// Returns ErrPointNotBound if the point is not bound to any equipment
+func (t *TopologyGridStruct) SetSwitchStateByPoint(pointId uint64, state int) error {
+	t.Lock()
+	defer t.Unlock()
+
+	equipmentId, exists := t.equipmentIdFromPointId[pointId]
+	if !exists {
+		return fmt.Errorf("%w: %d", ErrPointNotBound, pointId)
+	}
+
+	return t.setSwitchState(equipmentId, state)
+}
+
+// unbindPoints removes bindings of all SCADA point ids bound to the equipment
+func (t *TopologyGridStruct) unbindPoints(equipmentId int) {
+	for pointId, boundEquipmentId := range t.equipmentIdFromPointId {
+		if boundEquipmentId == equipmentId {
+			delete(t.equipmentIdFromPointId, pointId)
+		}
+	}
+}
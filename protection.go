@@ -0,0 +1,87 @@
+package topogrid
+
+import (
+	"errors"
+)
+
+// UpstreamProtectiveDevice returns the equipment id of the circuit breaker or fuse that clears a fault on the equipment
+// fed from the power node under the present topology, i.e. the last protective device on the supply path
+// from the power node to the nearest terminal of the equipment. The equipment itself is skipped.
+// Returns PathError with ErrNoPath if the equipment is not supplied by the power node and ErrNoProtectiveDevice
+// if there is no protective device on the supply path
+func (t *TopologyGridStruct) UpstreamProtectiveDevice(equipmentId int, powerNodeId int) (int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.upstreamProtectiveDevice(equipmentId, powerNodeId)
+}
+
+// UpstreamProtectiveDevices returns equipment ids of upstream protective devices like UpstreamProtectiveDevice
+// for every power node supplying the equipment, keyed by the power node id
+func (t *TopologyGridStruct) UpstreamProtectiveDevices(equipmentId int) (map[int]int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	devices := make(map[int]int)
+
+	for _, powerNodeId := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		deviceId, err := t.upstreamProtectiveDevice(equipmentId, powerNodeId)
+		if errors.Is(err, ErrNoPath) || errors.Is(err, ErrNoProtectiveDevice) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		devices[powerNodeId] = deviceId
+	}
+
+	return devices, nil
+}
+
+func (t *TopologyGridStruct) upstreamProtectiveDevice(equipmentId int, powerNodeId int) (int, error) {
+	if _, exists := t.equipment[equipmentId]; !exists {
+		return 0, equipmentNotFound(equipmentId)
+	}
+
+	if _, exists := t.nodeIdxFromNodeId[powerNodeId]; !exists {
+		return 0, nodeNotFound(powerNodeId)
+	}
+
+	var supplyEdgeIds []int
+	var supplyCost int64 = -1
+	var pathErr error = equipmentNotFound(equipmentId)
+
+	for _, nodeId := range t.equipmentTerminalNodeIds(equipmentId) {
+		_, edgeIds, err := t.supplyPath(nodeId, powerNodeId)
+		if err != nil {
+			pathErr = err
+			continue
+		}
+
+		var cost int64
+		for _, edgeId := range edgeIds {
+			typeId, _ := t.edgeState(t.edges[t.edgeIdxFromEdgeId[edgeId]])
+			cost += edgeCost(typeId)
+		}
+
+		if supplyCost < 0 || cost < supplyCost {
+			supplyEdgeIds = edgeIds
+			supplyCost = cost
+		}
+	}
+
+	if supplyCost < 0 {
+		return 0, pathErr
+	}
+
+	for i := len(supplyEdgeIds) - 1; i >= 0; i-- {
+		edge := t.edges[t.edgeIdxFromEdgeId[supplyEdgeIds[i]]]
+
+		if typeId, _ := t.edgeState(edge); edgeCost(typeId) >= 1 && edge.equipmentId != equipmentId {
+			return edge.equipmentId, nil
+		}
+	}
+
+	return 0, &IdError{Err: ErrNoProtectiveDevice, Id: equipmentId}
+}
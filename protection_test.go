@@ -0,0 +1,76 @@
+package topogrid
+
+import (
+	"errors"
+	"maps"
+	"testing"
+)
+
+func TestUpstreamProtectiveDevice(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		topology    *TopologyGridStruct
+		equipmentId int
+		powerNodeId int
+		want        int
+	}{
+		{"consumer behind a disconnect switch", newRingGrid(t), 104, 1, 110},
+		{"consumer behind a line", newRingGrid(t), 105, 7, 160},
+		{"consumer behind a fuse", newRadialGrid(t), 104, 1, 130},
+		{"line", newRadialGrid(t), 120, 1, 110},
+		{"fuse itself is skipped", newRadialGrid(t), 130, 1, 110},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			deviceId, err := test.topology.UpstreamProtectiveDevice(test.equipmentId, test.powerNodeId)
+			mustSucceed(t, err)
+
+			if deviceId != test.want {
+				t.Fatalf("UpstreamProtectiveDevice(%d, %d) = %d, want %d", test.equipmentId, test.powerNodeId, deviceId, test.want)
+			}
+		})
+	}
+}
+
+func TestUpstreamProtectiveDeviceErrors(t *testing.T) {
+	topology := newRingGrid(t)
+
+	// CB40 is open, so C3 is not supplied by P1
+	var pathErr *PathError
+	if _, err := topology.UpstreamProtectiveDevice(105, 1); !errors.As(err, &pathErr) || !errors.Is(err, ErrNoPath) {
+		t.Fatalf("UpstreamProtectiveDevice of a consumer not supplied by the power node returns %v, want ErrNoPath", err)
+	}
+
+	// The power node side terminal of CB10 has no protective device upstream
+	if _, err := topology.UpstreamProtectiveDevice(110, 1); !errors.Is(err, ErrNoProtectiveDevice) {
+		t.Fatalf("UpstreamProtectiveDevice of the feeder head returns %v, want ErrNoProtectiveDevice", err)
+	}
+
+	if _, err := topology.UpstreamProtectiveDevice(999, 1); !errors.Is(err, ErrEquipmentNotFound) {
+		t.Fatalf("UpstreamProtectiveDevice of an unknown equipment returns %v, want ErrEquipmentNotFound", err)
+	}
+
+	if _, err := topology.UpstreamProtectiveDevice(104, 99); !errors.Is(err, ErrNodeNotFound) {
+		t.Fatalf("UpstreamProtectiveDevice from an unknown power node returns %v, want ErrNodeNotFound", err)
+	}
+}
+
+func TestUpstreamProtectiveDevices(t *testing.T) {
+	topology := newRingGrid(t)
+
+	devices, err := topology.UpstreamProtectiveDevices(104)
+	mustSucceed(t, err)
+
+	if want := map[int]int{1: 110}; !maps.Equal(devices, want) {
+		t.Fatalf("UpstreamProtectiveDevices(104) = %v, want %v", devices, want)
+	}
+
+	// Closing the normally open point feeds C2 from P2 through CB40, the last protective device on that path
+	mustSucceed(t, topology.SetSwitchState(140, SwitchStateClose))
+
+	devices, err = topology.UpstreamProtectiveDevices(104)
+	mustSucceed(t, err)
+
+	if want := map[int]int{1: 110, 7: 140}; !maps.Equal(devices, want) {
+		t.Fatalf("UpstreamProtectiveDevices(104) with closed CB40 = %v, want %v", devices, want)
+	}
+}
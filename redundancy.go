@@ -0,0 +1,252 @@
+package topogrid
+
+import (
+	"github.com/yourbasic/graph"
+)
+
+// SupplyRedundancy returns the number of edge-disjoint paths from the node to the power nodes in the full topology graph,
+// i.e. the maximum flow with unit edge capacities from all power nodes merged into one source. Parallel edges are
+// counted separately. 0 means the node cannot be supplied, 1 means the node is supplied radially without a backup.
+// Returns ErrNodeIsPowerNode for power nodes
+func (t *TopologyGridStruct) SupplyRedundancy(nodeId int) (int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
+	if !exists {
+		return 0, nodeNotFound(nodeId)
+	}
+
+	if t.powerNodeIdSet()[nodeId] {
+		return 0, &IdError{Err: ErrNodeIsPowerNode, Id: nodeId}
+	}
+
+	capacity, source := t.supplyCapacityGraph()
+	flow, _ := graph.MaxFlow(capacity, source, nodeIdx)
+
+	return int(flow), nil
+}
+
+// SupplyRedundancyAll returns SupplyRedundancy for every node except power nodes: NodeId -> number of edge-disjoint paths.
+// Nodes separated from all power nodes by a single edge (a bridge) get 1 without calculating the maximum flow,
+// so radial parts of the network cost nothing
+func (t *TopologyGridStruct) SupplyRedundancyAll() map[int]int {
+	t.RLock()
+	defer t.RUnlock()
+
+	powerNodes := t.powerNodeIdSet()
+	labels, numberOfIslands := t.islandLabels(t.fullGraph)
+
+	isPoweredIsland := make([]bool, numberOfIslands)
+	for nodeIdOfPowerNode := range powerNodes {
+		if nodeIdx, exists := t.nodeIdxFromNodeId[nodeIdOfPowerNode]; exists {
+			isPoweredIsland[labels[nodeIdx]] = true
+		}
+	}
+
+	isRadial := t.radialNodeIdxSet()
+	capacity, source := t.supplyCapacityGraph()
+
+	redundancy := make(map[int]int, t.nodeIdx)
+
+	for nodeIdx, node := range t.nodes[:t.nodeIdx] {
+		switch {
+		case powerNodes[node.id]:
+			continue
+		case !isPoweredIsland[labels[nodeIdx]]:
+			redundancy[node.id] = 0
+		case isRadial[nodeIdx]:
+			redundancy[node.id] = 1
+		default:
+			flow, _ := graph.MaxFlow(capacity, source, nodeIdx)
+			redundancy[node.id] = int(flow)
+		}
+	}
+
+	return redundancy
+}
+
+// supplyCapacityGraph returns the full topology graph with the number of parallel edges as arc capacities
+// and the index of an extra source node connected to every power node
+func (t *TopologyGridStruct) supplyCapacityGraph() (*graph.Mutable, int) {
+	source := t.fullGraph.Order()
+	capacity := graph.New(source + 1)
+
+	for _, edge := range t.fullGraphEdges() {
+		node1Idx := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+
+		capacity.AddBothCost(node1Idx, node2Idx, capacity.Cost(node1Idx, node2Idx)+1)
+	}
+
+	for _, nodeIdOfPowerNode := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		if nodeIdx, exists := t.nodeIdxFromNodeId[nodeIdOfPowerNode]; exists {
+			capacity.AddCost(source, nodeIdx, int64(len(t.edges)+1))
+		}
+	}
+
+	return capacity, source
+}
+
+// fullGraphEdges returns edges of the full topology graph with both terminals found, skipping self-loops
+func (t *TopologyGridStruct) fullGraphEdges() []EdgeStruct {
+	return t.graphEdges(func(edge EdgeStruct) bool {
+		typeId, _ := t.edgeState(edge)
+		return !t.isEdgeFaulted(edge) && isInFullGraph(typeId, edge)
+	})
+}
+
+// graphEdges returns edges accepted by the filter with both terminals found, skipping self-loops
+func (t *TopologyGridStruct) graphEdges(accept func(edge EdgeStruct) bool) []EdgeStruct {
+	edges := make([]EdgeStruct, 0, len(t.edges))
+
+	for _, edge := range t.edges {
+		node1Idx, existsNode1 := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx, existsNode2 := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+
+		if existsNode1 && existsNode2 && node1Idx != node2Idx && accept(edge) {
+			edges = append(edges, edge)
+		}
+	}
+
+	return edges
+}
+
+// radialNodeIdxSet returns indexes of nodes separated from all power nodes of their island of the full topology graph
+// by a bridge. Bridges are found by the depth-first search numbering, then leaves of the tree of 2-edge-connected
+// components without power nodes are pruned one by one
+func (t *TopologyGridStruct) radialNodeIdxSet() []bool {
+	edges := t.fullGraphEdges()
+
+	type arc struct {
+		to      int
+		edgeNum int
+	}
+
+	adjacency := make([][]arc, t.nodeIdx)
+	for edgeNum, edge := range edges {
+		node1Idx := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+
+		adjacency[node1Idx] = append(adjacency[node1Idx], arc{to: node2Idx, edgeNum: edgeNum})
+		adjacency[node2Idx] = append(adjacency[node2Idx], arc{to: node1Idx, edgeNum: edgeNum})
+	}
+
+	// Bridges by the lowest reachable depth-first search number, an arc back over the same edge is not a back edge
+	order := make([]int, t.nodeIdx)
+	low := make([]int, t.nodeIdx)
+	isBridge := make([]bool, len(edges))
+	counter := 0
+
+	var visit func(v int, parentEdgeNum int)
+	visit = func(v int, parentEdgeNum int) {
+		counter++
+		order[v] = counter
+		low[v] = counter
+
+		for _, a := range adjacency[v] {
+			if a.edgeNum == parentEdgeNum {
+				continue
+			}
+
+			if order[a.to] == 0 {
+				visit(a.to, a.edgeNum)
+				low[v] = min(low[v], low[a.to])
+
+				if low[a.to] > order[v] {
+					isBridge[a.edgeNum] = true
+				}
+			} else {
+				low[v] = min(low[v], order[a.to])
+			}
+		}
+	}
+
+	for v := range adjacency {
+		if order[v] == 0 {
+			visit(v, -1)
+		}
+	}
+
+	// 2-edge-connected components
+	component := make([]int, t.nodeIdx)
+	for v := range component {
+		component[v] = -1
+	}
+
+	numberOfComponents := 0
+	for v := range adjacency {
+		if component[v] != -1 {
+			continue
+		}
+
+		component[v] = numberOfComponents
+		for queue := []int{v}; len(queue) > 0; queue = queue[1:] {
+			for _, a := range adjacency[queue[0]] {
+				if !isBridge[a.edgeNum] && component[a.to] == -1 {
+					component[a.to] = numberOfComponents
+					queue = append(queue, a.to)
+				}
+			}
+		}
+
+		numberOfComponents++
+	}
+
+	// Tree of components connected by bridges
+	hasPower := make([]bool, numberOfComponents)
+	for _, nodeIdOfPowerNode := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		if nodeIdx, exists := t.nodeIdxFromNodeId[nodeIdOfPowerNode]; exists {
+			hasPower[component[nodeIdx]] = true
+		}
+	}
+
+	treeAdjacency := make([][]int, numberOfComponents)
+	degree := make([]int, numberOfComponents)
+
+	for edgeNum, edge := range edges {
+		if !isBridge[edgeNum] {
+			continue
+		}
+
+		c1 := component[t.nodeIdxFromNodeId[edge.terminal.node1Id]]
+		c2 := component[t.nodeIdxFromNodeId[edge.terminal.node2Id]]
+
+		treeAdjacency[c1] = append(treeAdjacency[c1], c2)
+		treeAdjacency[c2] = append(treeAdjacency[c2], c1)
+		degree[c1]++
+		degree[c2]++
+	}
+
+	isPruned := make([]bool, numberOfComponents)
+	leaves := make([]int, 0)
+
+	for c := range degree {
+		if degree[c] <= 1 && !hasPower[c] {
+			leaves = append(leaves, c)
+		}
+	}
+
+	for ; len(leaves) > 0; leaves = leaves[1:] {
+		c := leaves[0]
+		isPruned[c] = true
+
+		for _, next := range treeAdjacency[c] {
+			if isPruned[next] {
+				continue
+			}
+
+			degree[next]--
+			if degree[next] == 1 && !hasPower[next] {
+				leaves = append(leaves, next)
+			}
+		}
+	}
+
+	isRadial := make([]bool, t.nodeIdx)
+	for v := range isRadial {
+		isRadial[v] = isPruned[component[v]]
+	}
+
+	return isRadial
+}
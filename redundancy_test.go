@@ -0,0 +1,65 @@
+package topogrid
+
+import (
+	"errors"
+	"maps"
+	"testing"
+)
+
+func TestSupplyRedundancy(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		topology func(t testing.TB) *TopologyGridStruct
+		want     map[int]int
+	}{
+		// Switch states do not matter, the normally open CB40 is in the full topology graph
+		{"ring with two power nodes", newRingGrid, map[int]int{2: 2, 3: 2, 4: 2, 5: 2, 6: 2}},
+		{"radial feeder", newRadialGrid, map[int]int{2: 1, 3: 1, 4: 1, 5: 1}},
+		{"parallel lines", newParallelGrid, map[int]int{2: 2, 3: 2, 4: 2, 5: 1}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			topology := test.topology(t)
+
+			if redundancy := topology.SupplyRedundancyAll(); !maps.Equal(redundancy, test.want) {
+				t.Fatalf("SupplyRedundancyAll = %v, want %v", redundancy, test.want)
+			}
+
+			for nodeId, want := range test.want {
+				if redundancy, err := topology.SupplyRedundancy(nodeId); err != nil || redundancy != want {
+					t.Fatalf("SupplyRedundancy(%d) = %d, %v, want %d", nodeId, redundancy, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSupplyRedundancyParallelEdges(t *testing.T) {
+	// Two circuit breakers in parallel between the same nodes are counted separately
+	topology := newRadialGrid(t)
+	mustSucceed(t, topology.AddEdge(50, 1, 2, SwitchStateClose, 150, TypeCircuitBreaker, "CB50"))
+
+	want := map[int]int{2: 2, 3: 1, 4: 1, 5: 1}
+	if redundancy := topology.SupplyRedundancyAll(); !maps.Equal(redundancy, want) {
+		t.Fatalf("SupplyRedundancyAll with parallel circuit breakers = %v, want %v", redundancy, want)
+	}
+
+	// A normally open disconnect switch is not in the full topology graph, a dead node has no supply path
+	mustSucceed(t, topology.AddNode(6, 106, TypeConsumer, "C3"))
+	mustSucceed(t, topology.AddEdge(60, 5, 6, SwitchStateOpen, 160, TypeDisconnectSwitch, "DS60"))
+
+	if redundancy, err := topology.SupplyRedundancy(6); err != nil || redundancy != 0 {
+		t.Fatalf("SupplyRedundancy behind a normally open disconnect switch = %d, %v, want 0", redundancy, err)
+	}
+
+	if redundancy := topology.SupplyRedundancyAll()[6]; redundancy != 0 {
+		t.Fatalf("SupplyRedundancyAll behind a normally open disconnect switch = %d, want 0", redundancy)
+	}
+
+	if _, err := topology.SupplyRedundancy(1); !errors.Is(err, ErrNodeIsPowerNode) {
+		t.Fatalf("SupplyRedundancy of a power node returns %v, want ErrNodeIsPowerNode", err)
+	}
+
+	if _, err := topology.SupplyRedundancy(99); !errors.Is(err, ErrNodeNotFound) {
+		t.Fatalf("SupplyRedundancy of an unknown node returns %v, want ErrNodeNotFound", err)
+	}
+}
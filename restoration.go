@@ -0,0 +1,154 @@
+package topogrid
+
+import (
+	"sort"
+)
+
+// SwitchingOperation is a switch equipment id and the switch state it has to be set to
+type SwitchingOperation struct {
+	EquipmentId      int
+	State            int
+	ClosesLoop       bool // The switch closes a loop, see SwitchingPlan
+	ParallelsSources bool // The switch connects islands powered by different power nodes, see SwitchingPlan
+}
+
+// SuggestRestoration returns switching operations that restore supply of isolated consumers through open switches.
+// Every isolated island of the current topology graph is energized from exactly one powered island, so sources are never
+// paralleled. Consumers are restored in the order of their priority from the highest, see SetEquipmentPriority,
+// consumers with the same priority needing fewer operations go first. Operations restoring a consumer are ordered
+// from the powered island outwards. Consumers that cannot be reached are skipped,
+// ErrCannotBeRestored is returned if there are isolated consumers but none of them can be restored.
+// The topology is not changed
+func (t *TopologyGridStruct) SuggestRestoration() ([]SwitchingOperation, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	labels, numberOfIslands := t.islandLabels(t.currentGraph)
+
+	const notReached = -1
+
+	// Edge index of the switch the island is reached by, notReached for islands not reached yet
+	reachedBy := make([]int, numberOfIslands)
+	for i := range reachedBy {
+		reachedBy[i] = notReached
+	}
+
+	isPowered := make([]bool, numberOfIslands)
+	queue := make([]int, 0)
+
+	for _, nodeIdOfPowerNode := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		if nodeIdx, exists := t.nodeIdxFromNodeId[nodeIdOfPowerNode]; exists && !isPowered[labels[nodeIdx]] {
+			isPowered[labels[nodeIdx]] = true
+			queue = append(queue, labels[nodeIdx])
+		}
+	}
+
+	// Open switches connecting different islands
+	islandEdges := make([][]int, numberOfIslands)
+
+	for edgeIdx, edge := range t.edges {
+		node1Idx, existsNode1 := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx, existsNode2 := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+
+		if !existsNode1 || !existsNode2 || labels[node1Idx] == labels[node2Idx] {
+			continue
+		}
+
+		if typeId, state := t.edgeState(edge); !isSwitchType(typeId) || typeId == TypeGroundSwitch || state != SwitchStateOpen ||
+			edge.equipmentId == 0 || t.isEdgeFaulted(edge) {
+			continue
+		}
+
+		islandEdges[labels[node1Idx]] = append(islandEdges[labels[node1Idx]], edgeIdx)
+		islandEdges[labels[node2Idx]] = append(islandEdges[labels[node2Idx]], edgeIdx)
+	}
+
+	// Breadth-first search over islands from the powered ones, so each dead island is reached once
+	parent := make([]int, numberOfIslands)
+
+	for len(queue) > 0 {
+		island := queue[0]
+		queue = queue[1:]
+
+		for _, edgeIdx := range islandEdges[island] {
+			edge := t.edges[edgeIdx]
+			next := labels[t.nodeIdxFromNodeId[edge.terminal.node1Id]]
+			if next == island {
+				next = labels[t.nodeIdxFromNodeId[edge.terminal.node2Id]]
+			}
+
+			if isPowered[next] || reachedBy[next] != notReached {
+				continue
+			}
+
+			reachedBy[next] = edgeIdx
+			parent[next] = island
+			queue = append(queue, next)
+		}
+	}
+
+	// Islands on the way to every isolated consumer that can be restored, from the consumer to a powered island
+	type restorableConsumer struct {
+		equipmentId int
+		islands     []int
+	}
+
+	restorable := make([]restorableConsumer, 0)
+	numberOfDeadConsumers := 0
+
+	for _, nodeId := range t.nodeIdArrayFromEquipmentTypeId[TypeConsumer] {
+		nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
+		if !exists || isPowered[labels[nodeIdx]] {
+			continue
+		}
+
+		numberOfDeadConsumers++
+
+		if reachedBy[labels[nodeIdx]] == notReached {
+			continue
+		}
+
+		consumer := restorableConsumer{equipmentId: t.nodes[nodeIdx].equipmentId}
+		for island := labels[nodeIdx]; !isPowered[island]; island = parent[island] {
+			consumer.islands = append(consumer.islands, island)
+		}
+
+		restorable = append(restorable, consumer)
+	}
+
+	if numberOfDeadConsumers > 0 && len(restorable) == 0 {
+		return nil, ErrCannotBeRestored
+	}
+
+	sort.Slice(restorable, func(i, j int) bool {
+		priorityI := t.equipment[restorable[i].equipmentId].priority
+		priorityJ := t.equipment[restorable[j].equipmentId].priority
+
+		if priorityI != priorityJ {
+			return priorityI > priorityJ
+		}
+
+		if len(restorable[i].islands) != len(restorable[j].islands) {
+			return len(restorable[i].islands) < len(restorable[j].islands)
+		}
+
+		return restorable[i].equipmentId < restorable[j].equipmentId
+	})
+
+	operations := make([]SwitchingOperation, 0)
+	closed := make(map[int]bool)
+
+	for _, consumer := range restorable {
+		for i := len(consumer.islands) - 1; i >= 0; i-- {
+			equipmentId := t.edges[reachedBy[consumer.islands[i]]].equipmentId
+			if closed[equipmentId] {
+				continue
+			}
+
+			closed[equipmentId] = true
+			operations = append(operations, SwitchingOperation{EquipmentId: equipmentId, State: SwitchStateClose})
+		}
+	}
+
+	return operations, nil
+}
@@ -0,0 +1,200 @@
+package topogrid
+
+import (
+	"sort"
+)
+
+// sectionCache is a partition of nodes into sections connected by non-switch edges and bounded by switches and fuses
+type sectionCache struct {
+	version               uint64
+	sectionFromNodeIdx    []int   // NodeIdx -> Section
+	equipmentIdsOfSection [][]int // Section -> sorted []EquipmentId of nodes and non-switch edges
+	boundaryOfSection     [][]int // Section -> sorted []EquipmentId of switches and fuses touching the section
+}
+
+// isSectionBoundary returns true if edges of the equipment type bound sections
+func isSectionBoundary(typeId int) bool {
+	return isSwitchType(typeId) || typeId == TypeFuse
+}
+
+// ComputeSections groups nodes into sections: maximal sets of nodes connected only through non-switch edges,
+// bounded by switches and fuses regardless of their state. Sections are numbered from 0 in the order
+// of their smallest node id and are recalculated only when the topology changes. Returns the number of sections
+func (t *TopologyGridStruct) ComputeSections() int {
+	t.RLock()
+	defer t.RUnlock()
+
+	return len(t.sectionCache().equipmentIdsOfSection)
+}
+
+// SectionOfNode returns the section id of the node
+func (t *TopologyGridStruct) SectionOfNode(nodeId int) (int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
+	if !exists {
+		return 0, nodeNotFound(nodeId)
+	}
+
+	return t.sectionCache().sectionFromNodeIdx[nodeIdx], nil
+}
+
+// SectionOfEquipment returns the section id of the equipment of a node or of a non-switch edge.
+// Returns ErrSectionBoundary for switches and fuses, see SectionBoundarySwitches
+func (t *TopologyGridStruct) SectionOfEquipment(equipmentId int) (int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	equipment, exists := t.equipment[equipmentId]
+	if !exists {
+		return 0, equipmentNotFound(equipmentId)
+	}
+
+	if isSectionBoundary(equipment.typeId) && len(t.edgeIdArrayFromEquipmentId[equipmentId]) != 0 {
+		return 0, &IdError{Err: ErrSectionBoundary, Id: equipmentId}
+	}
+
+	nodeIdxArray := t.equipmentTerminalIdxArray(equipmentId)
+	if len(nodeIdxArray) == 0 {
+		return 0, equipmentNotFound(equipmentId)
+	}
+
+	return t.sectionCache().sectionFromNodeIdx[nodeIdxArray[0]], nil
+}
+
+// SectionMembers returns sorted ids of equipment of nodes and non-switch edges in the section
+func (t *TopologyGridStruct) SectionMembers(sectionId int) []int {
+	t.RLock()
+	defer t.RUnlock()
+
+	sections := t.sectionCache()
+	if sectionId < 0 || sectionId >= len(sections.equipmentIdsOfSection) {
+		return []int{}
+	}
+
+	return append([]int{}, sections.equipmentIdsOfSection[sectionId]...)
+}
+
+// SectionBoundarySwitches returns sorted equipment ids of switches and fuses touching the section
+func (t *TopologyGridStruct) SectionBoundarySwitches(sectionId int) []int {
+	t.RLock()
+	defer t.RUnlock()
+
+	sections := t.sectionCache()
+	if sectionId < 0 || sectionId >= len(sections.boundaryOfSection) {
+		return []int{}
+	}
+
+	return append([]int{}, sections.boundaryOfSection[sectionId]...)
+}
+
+// sectionCache returns sections of the topology, building them if the topology has changed since the last call.
+// Must be called with at least the read lock held
+func (t *TopologyGridStruct) sectionCache() *sectionCache {
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+
+	if t.sections == nil || t.sections.version != t.graphVersion {
+		t.sections = t.buildSections()
+	}
+
+	return t.sections
+}
+
+// buildSections labels nodes connected by non-switch edges in the order of node ids
+func (t *TopologyGridStruct) buildSections() *sectionCache {
+	isBoundaryEdge := func(edge EdgeStruct) bool {
+		typeId, _ := t.edgeState(edge)
+		return isSectionBoundary(typeId)
+	}
+
+	adjacency := t.edgeAdjacency(func(edge EdgeStruct) bool { return !isBoundaryEdge(edge) })
+
+	nodeIdxArray := make([]int, t.nodeIdx)
+	for nodeIdx := range nodeIdxArray {
+		nodeIdxArray[nodeIdx] = nodeIdx
+	}
+	sort.Slice(nodeIdxArray, func(i, j int) bool { return t.nodes[nodeIdxArray[i]].id < t.nodes[nodeIdxArray[j]].id })
+
+	const notLabeled = -1
+
+	sections := &sectionCache{
+		version:               t.graphVersion,
+		sectionFromNodeIdx:    make([]int, t.nodeIdx),
+		equipmentIdsOfSection: make([][]int, 0),
+		boundaryOfSection:     make([][]int, 0),
+	}
+
+	for nodeIdx := range sections.sectionFromNodeIdx {
+		sections.sectionFromNodeIdx[nodeIdx] = notLabeled
+	}
+
+	for _, root := range nodeIdxArray {
+		if sections.sectionFromNodeIdx[root] != notLabeled {
+			continue
+		}
+
+		section := len(sections.equipmentIdsOfSection)
+		sections.sectionFromNodeIdx[root] = section
+		members := make(map[int]bool)
+
+		for queue := []int{root}; len(queue) > 0; queue = queue[1:] {
+			v := queue[0]
+
+			if equipmentId := t.nodes[v].equipmentId; equipmentId != 0 {
+				members[equipmentId] = true
+			}
+
+			for _, edgeIdx := range adjacency[v] {
+				edge := t.edges[edgeIdx]
+				if edge.equipmentId != 0 {
+					members[edge.equipmentId] = true
+				}
+
+				if w := t.otherTerminalIdx(edge, v); sections.sectionFromNodeIdx[w] == notLabeled {
+					sections.sectionFromNodeIdx[w] = section
+					queue = append(queue, w)
+				}
+			}
+		}
+
+		sections.equipmentIdsOfSection = append(sections.equipmentIdsOfSection, sortedKeys(members))
+		sections.boundaryOfSection = append(sections.boundaryOfSection, nil)
+	}
+
+	boundaries := make([]map[int]bool, len(sections.boundaryOfSection))
+	for section := range boundaries {
+		boundaries[section] = make(map[int]bool)
+	}
+
+	for _, edge := range t.edges {
+		if !isBoundaryEdge(edge) || edge.equipmentId == 0 {
+			continue
+		}
+
+		for _, nodeId := range []int{edge.terminal.node1Id, edge.terminal.node2Id} {
+			if nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]; exists {
+				boundaries[sections.sectionFromNodeIdx[nodeIdx]][edge.equipmentId] = true
+			}
+		}
+	}
+
+	for section, boundary := range boundaries {
+		sections.boundaryOfSection[section] = sortedKeys(boundary)
+	}
+
+	return sections
+}
+
+// sortedKeys returns sorted keys of the set
+func sortedKeys(set map[int]bool) []int {
+	keys := make([]int, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+
+	sort.Ints(keys)
+
+	return keys
+}
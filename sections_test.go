@@ -0,0 +1,91 @@
+package topogrid
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSectionsOfRing(t *testing.T) {
+	topology := newRingGrid(t)
+
+	if numberOfSections := topology.ComputeSections(); numberOfSections != 5 {
+		t.Fatalf("ComputeSections = %d, want 5", numberOfSections)
+	}
+
+	// Sections are numbered in the order of their smallest node id, the open CB40 bounds sections like closed switches
+	for _, want := range []struct {
+		nodeIds  []int
+		members  []int
+		boundary []int
+	}{
+		{[]int{1}, []int{101}, []int{110}},
+		{[]int{2, 3}, []int{103, 120}, []int{110, 130}},
+		{[]int{4}, []int{104}, []int{130, 140}},
+		{[]int{5, 6}, []int{105, 150}, []int{140, 160}},
+		{[]int{7}, []int{107}, []int{160}},
+	} {
+		sectionId, err := topology.SectionOfNode(want.nodeIds[0])
+		mustSucceed(t, err)
+
+		for _, nodeId := range want.nodeIds {
+			if section, err := topology.SectionOfNode(nodeId); err != nil || section != sectionId {
+				t.Fatalf("SectionOfNode(%d) = %d, %v, want %d", nodeId, section, err, sectionId)
+			}
+		}
+
+		for _, equipmentId := range want.members {
+			if section, err := topology.SectionOfEquipment(equipmentId); err != nil || section != sectionId {
+				t.Fatalf("SectionOfEquipment(%d) = %d, %v, want %d", equipmentId, section, err, sectionId)
+			}
+		}
+
+		if members := topology.SectionMembers(sectionId); !slices.Equal(members, want.members) {
+			t.Fatalf("SectionMembers(%d) = %v, want %v", sectionId, members, want.members)
+		}
+
+		if boundary := topology.SectionBoundarySwitches(sectionId); !slices.Equal(boundary, want.boundary) {
+			t.Fatalf("SectionBoundarySwitches(%d) = %v, want %v", sectionId, boundary, want.boundary)
+		}
+	}
+
+	if _, err := topology.SectionOfEquipment(140); !errors.Is(err, ErrSectionBoundary) {
+		t.Fatalf("SectionOfEquipment of a switch returns %v, want ErrSectionBoundary", err)
+	}
+
+	if members := topology.SectionMembers(5); len(members) != 0 {
+		t.Fatalf("SectionMembers of an unknown section = %v, want empty", members)
+	}
+}
+
+func TestSectionsOfParallelLines(t *testing.T) {
+	topology := newParallelGrid(t)
+
+	if numberOfSections := topology.ComputeSections(); numberOfSections != 3 {
+		t.Fatalf("ComputeSections = %d, want 3", numberOfSections)
+	}
+
+	// Both parallel lines join the nodes between the circuit breakers into one section
+	sectionId, err := topology.SectionOfEquipment(130)
+	mustSucceed(t, err)
+
+	if members := topology.SectionMembers(sectionId); !slices.Equal(members, []int{130, 140}) {
+		t.Fatalf("SectionMembers of the parallel lines = %v, want [130 140]", members)
+	}
+
+	if boundary := topology.SectionBoundarySwitches(sectionId); !slices.Equal(boundary, []int{110, 120, 150}) {
+		t.Fatalf("SectionBoundarySwitches of the parallel lines = %v, want [110 120 150]", boundary)
+	}
+
+	// Sections are recalculated when the topology changes
+	mustSucceed(t, topology.AddNode(6, 106, TypeConsumer, "C2"))
+	mustSucceed(t, topology.AddEdge(60, 4, 6, SwitchStateClose, 160, TypeLine, "LN60"))
+
+	if section, err := topology.SectionOfNode(6); err != nil || section != sectionId {
+		t.Fatalf("SectionOfNode of a node added to the parallel lines = %d, %v, want %d", section, err, sectionId)
+	}
+
+	if members := topology.SectionMembers(sectionId); !slices.Equal(members, []int{106, 130, 140, 160}) {
+		t.Fatalf("SectionMembers after AddEdge = %v, want [106 130 140 160]", members)
+	}
+}
@@ -0,0 +1,19 @@
+package topogrid
+
+// Simulate applies the switch changes (EquipmentId -> switch state) to a copy of the topology, sets electrical states
+// of the copy by SetEquipmentElectricalState and calls fn with the copy for queries. The changes are validated
+// like ApplySwitchStates. The topology is not changed and not locked while fn runs, the copy is discarded after fn returns
+// and observers are not notified. Returns the error of fn
+func (t *TopologyGridStruct) Simulate(changes map[int]int, fn func(sim *TopologyGridStruct) error) error {
+	t.RLock()
+	sim := t.clone()
+	t.RUnlock()
+
+	if _, err := sim.applySwitchStates(changes); err != nil {
+		return err
+	}
+
+	sim.setEquipmentElectricalState()
+
+	return fn(sim)
+}
@@ -0,0 +1,89 @@
+package topogrid
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// ElectricalStateJSON returns the electrical states calculated by SetEquipmentElectricalState as a JSON object
+// keyed by the equipment id, with the state bits, the switch state and the power nodes the equipment is powered by
+// with the number of switches between them. Keys are sorted numerically:
+//
+//	{"100":{"state":1,"switchState":0,"poweredBy":{"1":0}},"110":{"state":1,"switchState":1,"poweredBy":{"1":1,"5":2}}}
+func (t *TopologyGridStruct) ElectricalStateJSON() ([]byte, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	var buffer bytes.Buffer
+	buffer.Grow(64 * len(t.equipment))
+
+	t.writeElectricalStateJSON(&buffer)
+
+	return buffer.Bytes(), nil
+}
+
+// WriteElectricalStateJSON writes the JSON object returned by ElectricalStateJSON into w
+func (t *TopologyGridStruct) WriteElectricalStateJSON(w io.Writer) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	return writeBuffered(w, func(writer exportWriter) error {
+		t.writeElectricalStateJSON(writer)
+		return nil
+	})
+}
+
+func (t *TopologyGridStruct) writeElectricalStateJSON(buffer exportWriter) {
+	equipmentIds := make([]int, 0, len(t.equipment))
+	for id := range t.equipment {
+		equipmentIds = append(equipmentIds, id)
+	}
+	sort.Ints(equipmentIds)
+
+	powerNodeIds := make([]int, 0)
+	number := make([]byte, 0, 20)
+
+	writeKey := func(id int) {
+		buffer.WriteByte('"')
+		buffer.Write(strconv.AppendInt(number[:0], int64(id), 10))
+		buffer.WriteString(`":`)
+	}
+
+	buffer.WriteByte('{')
+
+	for i, id := range equipmentIds {
+		equipment := t.equipment[id]
+
+		if i != 0 {
+			buffer.WriteByte(',')
+		}
+
+		writeKey(id)
+		buffer.WriteString(`{"state":`)
+		buffer.Write(strconv.AppendUint(number[:0], uint64(equipment.electricalState), 10))
+		buffer.WriteString(`,"switchState":`)
+		buffer.Write(strconv.AppendInt(number[:0], int64(equipment.switchState), 10))
+		buffer.WriteString(`,"poweredBy":{`)
+
+		powerNodeIds = powerNodeIds[:0]
+		for powerNodeId := range equipment.poweredBy {
+			powerNodeIds = append(powerNodeIds, powerNodeId)
+		}
+		sort.Ints(powerNodeIds)
+
+		for j, powerNodeId := range powerNodeIds {
+			if j != 0 {
+				buffer.WriteByte(',')
+			}
+
+			writeKey(powerNodeId)
+			buffer.Write(strconv.AppendInt(number[:0], equipment.poweredBy[powerNodeId], 10))
+		}
+
+		buffer.WriteString("}}")
+	}
+
+	buffer.WriteByte('}')
+}
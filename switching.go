@@ -0,0 +1,152 @@
+package topogrid
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SwitchingPlan returns switching operations moving the topology from one switch state snapshot to another,
+// see SnapshotSwitchStates. Switches missing in the first snapshot keep their current state. All switches to be closed
+// are closed before any switch is opened, so consumers are not left dark unnecessarily. Closing operations are marked
+// if they close a loop or parallel power sources at the moment they are performed.
+// Both snapshots are validated like ApplySwitchStates. The topology is not changed
+func (t *TopologyGridStruct) SwitchingPlan(from map[int]int, to map[int]int) ([]SwitchingOperation, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	for _, states := range []map[int]int{from, to} {
+		for equipmentId, state := range states {
+			if err := t.checkSwitchState(equipmentId, state); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for equipmentId, state := range to {
+		if state == SwitchStateUnknown {
+			return nil, fmt.Errorf("%w: %d for equipment id %d", ErrInvalidSwitchState, state, equipmentId)
+		}
+	}
+
+	plan := t.clone()
+
+	for equipmentId, state := range from {
+		if err := plan.setSwitchState(equipmentId, state); err != nil {
+			return nil, err
+		}
+	}
+
+	closes := make([]int, 0)
+	opens := make([]int, 0)
+
+	for equipmentId, state := range to {
+		if plan.equipment[equipmentId].switchState == state {
+			continue
+		}
+
+		if state == SwitchStateClose {
+			closes = append(closes, equipmentId)
+		} else {
+			opens = append(opens, equipmentId)
+		}
+	}
+
+	sort.Ints(closes)
+	sort.Ints(opens)
+
+	operations := make([]SwitchingOperation, 0, len(closes)+len(opens))
+
+	for _, equipmentId := range closes {
+		closesLoop, parallelsSources := plan.closingEffect(equipmentId)
+
+		operations = append(operations, SwitchingOperation{
+			EquipmentId:      equipmentId,
+			State:            SwitchStateClose,
+			ClosesLoop:       closesLoop,
+			ParallelsSources: parallelsSources,
+		})
+
+		if err := plan.setSwitchState(equipmentId, SwitchStateClose); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, equipmentId := range opens {
+		operations = append(operations, SwitchingOperation{EquipmentId: equipmentId, State: to[equipmentId]})
+	}
+
+	return operations, nil
+}
+
+// closingEffect returns whether closing the open switch would close a loop, i.e. its terminals are already connected
+// in the current topology graph, and whether it would connect two islands both containing power nodes
+func (t *TopologyGridStruct) closingEffect(equipmentId int) (bool, bool) {
+	labels, _ := t.islandLabels(t.currentGraph)
+
+	isPowered := make(map[int]bool)
+	for _, nodeIdOfPowerNode := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		if nodeIdx, exists := t.nodeIdxFromNodeId[nodeIdOfPowerNode]; exists {
+			isPowered[labels[nodeIdx]] = true
+		}
+	}
+
+	closesLoop := false
+	parallelsSources := false
+
+	for _, edgeId := range t.edgeIdArrayFromEquipmentId[equipmentId] {
+		edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+
+		node1Idx, existsNode1 := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx, existsNode2 := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+		if !existsNode1 || !existsNode2 || t.isEdgeFaulted(edge) {
+			continue
+		}
+
+		if labels[node1Idx] == labels[node2Idx] {
+			closesLoop = true
+		} else if isPowered[labels[node1Idx]] && isPowered[labels[node2Idx]] {
+			parallelsSources = true
+		}
+	}
+
+	return closesLoop, parallelsSources
+}
+
+// WouldParallelSources returns whether the island formed by closing the switch would contain more than one power node,
+// and sorted ids of the power nodes in that island. The topology is not changed
+func (t *TopologyGridStruct) WouldParallelSources(equipmentId int) (bool, []int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	equipment, exists := t.equipment[equipmentId]
+	if !exists {
+		return false, nil, equipmentNotFound(equipmentId)
+	}
+
+	if !isSwitchType(equipment.typeId) {
+		return false, nil, &IdError{Err: ErrEquipmentIsNotSwitch, Id: equipmentId}
+	}
+
+	if len(t.edgeIdArrayFromEquipmentId[equipmentId]) == 0 {
+		return false, nil, &IdError{Err: ErrEquipmentHasNoEdges, Id: equipmentId}
+	}
+
+	labels, _ := t.islandLabels(t.currentGraph)
+
+	// Islands joined by the closed switch
+	isJoined := make(map[int]bool)
+	for _, nodeIdx := range t.equipmentTerminalIdxArray(equipmentId) {
+		isJoined[labels[nodeIdx]] = true
+	}
+
+	powerNodeIds := make([]int, 0)
+	for _, nodeIdOfPowerNode := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		if nodeIdx, exists := t.nodeIdxFromNodeId[nodeIdOfPowerNode]; exists && isJoined[labels[nodeIdx]] {
+			powerNodeIds = append(powerNodeIds, nodeIdOfPowerNode)
+		}
+	}
+
+	sort.Ints(powerNodeIds)
+
+	return len(powerNodeIds) > 1, powerNodeIds, nil
+}
@@ -0,0 +1,177 @@
+graph [
+  node [
+    graphics
+    [
+      type "star6"
+      fill "#FF0000"
+    ]
+    id 1
+    label "P1"
+  ]
+  node [
+    graphics
+    [
+      type "ellipse"
+      fill "#808080"
+      w 5.0
+      h 5.0
+    ]
+    id 2
+    label ""
+  ]
+  node [
+    graphics
+    [
+      type "rectangle"
+      fill "#FF8080"
+      w 40.0
+      h 10.0
+    ]
+    id 3
+    label "L1"
+  ]
+  node [
+    graphics
+    [
+      type "triangle"
+      fill "#FFCC00"
+    ]
+    id 4
+    label "C1"
+  ]
+  node [
+    graphics
+    [
+      type "star6"
+      fill "#FF0000"
+    ]
+    id 5
+    label "P2"
+  ]
+  node [
+    graphics
+    [
+      type "ellipse"
+      fill "#808080"
+      w 5.0
+      h 5.0
+    ]
+    id 6
+    label ""
+  ]
+  node [
+    graphics
+    [
+      type "ellipse"
+      fill "#808080"
+      w 5.0
+      h 5.0
+    ]
+    id 7
+    label "G1"
+  ]
+  node [
+    graphics
+    [
+      type "ellipse"
+      fill "#808080"
+      w 5.0
+      h 5.0
+    ]
+    id 0
+    label ""
+  ]
+  node [
+    graphics
+    [
+      type "ellipse"
+      fill "#808080"
+      w 5.0
+      h 5.0
+    ]
+    id 0
+    label ""
+  ]
+  node [
+    graphics
+    [
+      type "ellipse"
+      fill "#808080"
+      w 5.0
+      h 5.0
+    ]
+    id 0
+    label ""
+  ]
+  edge [
+    graphics
+    [
+    fill "#FF0000"
+    ]
+    source 1
+    target 2
+    label "CB10"
+  ]
+  edge [
+    graphics
+    [
+    fill "#00FF00"
+    ]
+    source 2
+    target 3
+    label "DS20"
+  ]
+  edge [
+    graphics
+    [
+    fill "#FF0000"
+    ]
+    source 3
+    target 4
+    label "CB30"
+  ]
+  edge [
+    graphics
+    [
+    fill "#FF0000"
+    ]
+    source 5
+    target 6
+    label "CB40"
+  ]
+  edge [
+    graphics
+    [
+    style "dotted"
+      fill "#FF0000"
+    ]
+    source 6
+    target 4
+    label "CB50"
+  ]
+  edge [
+    graphics
+    [
+    style "dotted"
+      fill "#00FF00"
+    ]
+    source 6
+    target 3
+    label "DS60"
+  ]
+  edge [
+    graphics
+    [
+    style "dotted"
+      fill "#000000"
+    ]
+    source 3
+    target 7
+    label "B70"
+  ]
+  edge [
+    source 2
+    target 6
+    label "B80"
+  ]
+]
@@ -0,0 +1,77 @@
+package topogrid
+
+import (
+	"slices"
+	"sort"
+)
+
+// TieSwitches returns sorted equipment ids of open switches whose closing would connect two distinct islands
+// of the current topology graph both containing power nodes
+func (t *TopologyGridStruct) TieSwitches() []int {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.tieSwitches(func(island1, island2 int, powerNodeIds [][]int) bool {
+		return len(powerNodeIds[island1]) != 0 && len(powerNodeIds[island2]) != 0
+	})
+}
+
+// TieSwitchesBetween returns sorted equipment ids of open switches whose closing would connect the island
+// of the current topology graph containing one power node with the island containing the other
+func (t *TopologyGridStruct) TieSwitchesBetween(powerNodeId1, powerNodeId2 int) ([]int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	for _, nodeId := range []int{powerNodeId1, powerNodeId2} {
+		if _, exists := t.nodeIdxFromNodeId[nodeId]; !exists {
+			return nil, nodeNotFound(nodeId)
+		}
+	}
+
+	return t.tieSwitches(func(island1, island2 int, powerNodeIds [][]int) bool {
+		return slices.Contains(powerNodeIds[island1], powerNodeId1) && slices.Contains(powerNodeIds[island2], powerNodeId2) ||
+			slices.Contains(powerNodeIds[island1], powerNodeId2) && slices.Contains(powerNodeIds[island2], powerNodeId1)
+	}), nil
+}
+
+// tieSwitches returns sorted equipment ids of open switches connecting distinct islands of the current topology graph
+// accepted by the filter. The filter gets power node ids of every island
+func (t *TopologyGridStruct) tieSwitches(accept func(island1, island2 int, powerNodeIds [][]int) bool) []int {
+	labels, numberOfIslands := t.islandLabels(t.currentGraph)
+
+	powerNodeIds := make([][]int, numberOfIslands)
+	for _, nodeIdOfPowerNode := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+		if nodeIdx, exists := t.nodeIdxFromNodeId[nodeIdOfPowerNode]; exists {
+			powerNodeIds[labels[nodeIdx]] = append(powerNodeIds[labels[nodeIdx]], nodeIdOfPowerNode)
+		}
+	}
+
+	ties := make(map[int]bool)
+
+	for _, edge := range t.edges {
+		typeId, _ := t.edgeState(edge)
+		if !isSwitchType(typeId) || typeId == TypeGroundSwitch || edge.equipmentId == 0 ||
+			t.isEdgeClosed(edge) || t.isEdgeFaulted(edge) {
+			continue
+		}
+
+		node1Idx, existsNode1 := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx, existsNode2 := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+		if !existsNode1 || !existsNode2 || labels[node1Idx] == labels[node2Idx] {
+			continue
+		}
+
+		if accept(labels[node1Idx], labels[node2Idx], powerNodeIds) {
+			ties[edge.equipmentId] = true
+		}
+	}
+
+	equipmentIds := make([]int, 0, len(ties))
+	for equipmentId := range ties {
+		equipmentIds = append(equipmentIds, equipmentId)
+	}
+
+	sort.Ints(equipmentIds)
+
+	return equipmentIds
+}
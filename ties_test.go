@@ -0,0 +1,70 @@
+package topogrid
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTieSwitches(t *testing.T) {
+	topology := newRingGrid(t)
+
+	if ties := topology.TieSwitches(); !slices.Equal(ties, []int{140}) {
+		t.Fatalf("TieSwitches of the ring = %v, want [140]", ties)
+	}
+
+	ties, err := topology.TieSwitchesBetween(7, 1)
+	mustSucceed(t, err)
+
+	if !slices.Equal(ties, []int{140}) {
+		t.Fatalf("TieSwitchesBetween(7, 1) = %v, want [140]", ties)
+	}
+
+	// Opening DS30 leaves C2 dead, neither DS30 nor CB40 connects two powered islands
+	mustSucceed(t, topology.SetSwitchState(130, SwitchStateOpen))
+
+	if ties := topology.TieSwitches(); len(ties) != 0 {
+		t.Fatalf("TieSwitches with a dead consumer between open switches = %v, want empty", ties)
+	}
+
+	// Closing the ring leaves no open switch between the power nodes
+	mustSucceed(t, topology.SetSwitchState(130, SwitchStateClose))
+	mustSucceed(t, topology.SetSwitchState(140, SwitchStateClose))
+
+	if ties := topology.TieSwitches(); len(ties) != 0 {
+		t.Fatalf("TieSwitches of a closed ring = %v, want empty", ties)
+	}
+}
+
+func TestTieSwitchesBetween(t *testing.T) {
+	// Three radial feeders: P1 and P2 are tied by CB40, P2 and P3 by CB80
+	topology := newRingGrid(t)
+	mustSucceed(t, topology.AddNode(8, 0, TypeAllEquipment, ""))
+	mustSucceed(t, topology.AddNode(9, 109, TypePower, "P3"))
+	mustSucceed(t, topology.AddEdge(80, 6, 8, SwitchStateOpen, 180, TypeCircuitBreaker, "CB80"))
+	mustSucceed(t, topology.AddEdge(90, 8, 9, SwitchStateClose, 190, TypeCircuitBreaker, "CB90"))
+
+	if ties := topology.TieSwitches(); !slices.Equal(ties, []int{140, 180}) {
+		t.Fatalf("TieSwitches = %v, want [140 180]", ties)
+	}
+
+	for _, test := range []struct {
+		powerNodeId1, powerNodeId2 int
+		want                       []int
+	}{
+		{1, 7, []int{140}},
+		{7, 9, []int{180}},
+		{1, 9, []int{}},
+	} {
+		ties, err := topology.TieSwitchesBetween(test.powerNodeId1, test.powerNodeId2)
+		mustSucceed(t, err)
+
+		if !slices.Equal(ties, test.want) {
+			t.Fatalf("TieSwitchesBetween(%d, %d) = %v, want %v", test.powerNodeId1, test.powerNodeId2, ties, test.want)
+		}
+	}
+
+	if _, err := topology.TieSwitchesBetween(1, 99); !errors.Is(err, ErrNodeNotFound) {
+		t.Fatalf("TieSwitchesBetween with an unknown node returns %v, want ErrNodeNotFound", err)
+	}
+}
@@ -4,21 +4,25 @@
 package topogrid
 
 import (
-	"errors"
 	"fmt"
 	"github.com/yourbasic/graph"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 )
 
 const (
-	SwitchStateOpen  = 0
-	SwitchStateClose = 1
+	SwitchStateOpen    = 0
+	SwitchStateClose   = 1
+	SwitchStateClosed  = SwitchStateClose
+	SwitchStateUnknown = 2 // Invalid position reported by SCADA, the switch is treated as open in the current topology graph
 )
 
-var ErrBothAreEnergized = errors.New("both segments are already energized")
-var ErrEnergizedWillBeGrounded = errors.New("energized segment will be grounded")
-var ErrSwitchIsAlreadyClosed = errors.New("switch is already closed")
-var ErrEquipmentNotFound = errors.New("equipment not found")
+// isValidSwitchState returns true if the state is one of the switch state constants
+func isValidSwitchState(state int) bool {
+	return state == SwitchStateOpen || state == SwitchStateClose || state == SwitchStateUnknown
+}
 
 type EquipmentStruct struct {
 	id              int
@@ -27,12 +31,17 @@ type EquipmentStruct struct {
 	electricalState uint8
 	poweredBy       map[int]int64
 	switchState     int
+	faulted         bool // Edges of faulted equipment are excluded from both topology graphs
+	outOfService    bool // Edges of equipment out of service are not traversed by SetEquipmentElectricalState
+	priority        int  // Consumers with a higher priority are restored first, see SuggestRestoration
+	loadKw          float64
 }
 
 type NodeStruct struct {
 	idx             int
 	id              int
 	equipmentId     int
+	typeId          int // Equipment type the node was added with
 	electricalState uint8
 }
 
@@ -42,18 +51,28 @@ type TerminalStruct struct {
 	numberOfSwitches int64
 }
 
+type CoordinatesStruct struct {
+	x float64
+	y float64
+}
+
 type EdgeStruct struct {
-	idx         int
-	id          int
-	equipmentId int
-	terminal    TerminalStruct
+	idx          int
+	id           int
+	equipmentId  int
+	typeId       int // Equipment type the edge was added with
+	normalState  int // Switch state the edge was added with
+	terminal     TerminalStruct
+	weight       int64   // Cost of the edge in the weighted topology graph, e.g. the line length
+	lengthMeters float64 // Line length used by DistanceToSource
 }
 
 type TopologyGridStruct struct {
 	sync.RWMutex
 
-	currentGraph *graph.Mutable // Current grid topology (depends on circuit breaker states)
-	fullGraph    *graph.Mutable // Full grid topology
+	currentGraph  *graph.Mutable // Current grid topology (depends on circuit breaker states)
+	fullGraph     *graph.Mutable // Full grid topology
+	weightedGraph *graph.Mutable // Current grid topology with edge weights as costs
 
 	nodes     []NodeStruct
 	edges     []EdgeStruct
@@ -61,36 +80,103 @@ type TopologyGridStruct struct {
 
 	nodeIdxFromNodeId              map[int]int   // NodeId -> NodeIdx
 	nodeIdArrayFromEquipmentTypeId map[int][]int // EquipmentTypeId -> []NodeId
-	nodeIdArrayFromEquipmentId     map[int][]int // EquipmentId -> []NodeId
-
-	edgeIdxFromEdgeId              map[int]int              // EdgeId -> EdgeIdx
-	edgeIdArrayFromEquipmentTypeId map[int][]int            // EquipmentTypeId -> []EdgeId
-	edgeIdArrayFromTerminalStruct  map[TerminalStruct][]int // TerminalStruct -> []EdgeId
-	edgeIdArrayFromNodeId          map[int][]int            // NodeId -> []EdgeId
-	edgeIdArrayFromEquipmentId     map[int][]int            // EquipmentId -> []EdgeId
-	nodeIdx                        int
-	edgeIdx                        int
+	nodeIdArrayFromEquipmentId     map[int][]int // EquipmentId -> []NodeId of nodes added with the equipment
+
+	edgeIdxFromEdgeId                  map[int]int              // EdgeId -> EdgeIdx
+	edgeIdArrayFromEquipmentTypeId     map[int][]int            // EquipmentTypeId -> []EdgeId
+	edgeIdArrayFromTerminalStruct      map[TerminalStruct][]int // TerminalStruct -> []EdgeId
+	edgeIdArrayFromNodeId              map[int][]int            // NodeId -> []EdgeId
+	edgeIdArrayFromEquipmentId         map[int][]int            // EquipmentId -> []EdgeId
+	terminalNodeIdsFromEdgeEquipmentId map[int][]int            // EquipmentId -> []NodeId of terminals of edges added with the equipment
+	nodeIdx                            int
+	edgeIdx                            int
+	unusedNodeSlots                    int // Node slots preallocated by New and never used by AddNode, see Validate
+
+	coordinatesFromNodeId  map[int]CoordinatesStruct // NodeId -> Coordinates
+	voltageLevelFromNodeId map[int]float64           // NodeId -> Voltage level, kV
+
+	attributesFromEquipmentId map[int]map[string]string  // EquipmentId -> Key -> Value
+	equipmentIdsFromAttribute map[attribute]map[int]bool // Key and value -> set of EquipmentId
+	equipmentIdFromPointId    map[uint64]int             // SCADA PointId -> EquipmentId
+
+	version        uint64         // Incremented on every change, see Version
+	changeLog      []ChangeRecord // Latest changes, a ring buffer of changeLogSize records
+	changeLogStart int            // Index of the oldest record in the full change log
+
+	graphVersion uint64     // Incremented on every change of nodes or arcs, invalidates caches
+	cacheMutex   sync.Mutex // Guards caches built lazily under the read lock
+	zones        *zoneCache
+	components   [2]*componentCache   // Connected components of the current and the full topology graph
+	sorted       [2]*sortedGraphCache // Sorted current and full topology graph
+	sections     *sectionCache
+	feeders      *feederCache
+
+	sourceReaches map[int]sourceReach // PowerNodeId -> electrical state calculated from the power node
+	reachVersion  uint64              // Graph version the source reaches were calculated for
+	blockedArcs   map[[2]int]bool     // Arcs of equipment out of service the source reaches were calculated with
+
+	observerMutex sync.Mutex // Guards observers, they are called without the topology lock held
+	observers     []equipmentStateObserverEntry
+	observerId    int
 }
 
 // New topology
 func New(numberOfNodes int) *TopologyGridStruct {
 	return &TopologyGridStruct{
-		currentGraph:                   graph.New(numberOfNodes),
-		fullGraph:                      graph.New(numberOfNodes),
-		nodes:                          make([]NodeStruct, numberOfNodes),
-		nodeIdxFromNodeId:              make(map[int]int),
-		nodeIdArrayFromEquipmentTypeId: make(map[int][]int),
-		nodeIdArrayFromEquipmentId:     make(map[int][]int),
-		edgeIdArrayFromEquipmentTypeId: make(map[int][]int),
-		edgeIdxFromEdgeId:              make(map[int]int),
-		edgeIdArrayFromTerminalStruct:  make(map[TerminalStruct][]int),
-		edgeIdArrayFromNodeId:          make(map[int][]int),
-		edgeIdArrayFromEquipmentId:     make(map[int][]int),
-		edges:                          make([]EdgeStruct, 0),
-		nodeIdx:                        0,
-		edgeIdx:                        0,
-		equipment:                      make(map[int]EquipmentStruct),
+		currentGraph:                       graph.New(numberOfNodes),
+		fullGraph:                          graph.New(numberOfNodes),
+		weightedGraph:                      graph.New(numberOfNodes),
+		nodes:                              make([]NodeStruct, numberOfNodes),
+		nodeIdxFromNodeId:                  make(map[int]int),
+		nodeIdArrayFromEquipmentTypeId:     make(map[int][]int),
+		nodeIdArrayFromEquipmentId:         make(map[int][]int),
+		coordinatesFromNodeId:              make(map[int]CoordinatesStruct),
+		voltageLevelFromNodeId:             make(map[int]float64),
+		attributesFromEquipmentId:          make(map[int]map[string]string),
+		equipmentIdsFromAttribute:          make(map[attribute]map[int]bool),
+		equipmentIdFromPointId:             make(map[uint64]int),
+		edgeIdArrayFromEquipmentTypeId:     make(map[int][]int),
+		edgeIdxFromEdgeId:                  make(map[int]int),
+		edgeIdArrayFromTerminalStruct:      make(map[TerminalStruct][]int),
+		edgeIdArrayFromNodeId:              make(map[int][]int),
+		edgeIdArrayFromEquipmentId:         make(map[int][]int),
+		terminalNodeIdsFromEdgeEquipmentId: make(map[int][]int),
+		edges:                              make([]EdgeStruct, 0),
+		nodeIdx:                            0,
+		edgeIdx:                            0,
+		unusedNodeSlots:                    numberOfNodes,
+		equipment:                          make(map[int]EquipmentStruct),
+	}
+}
+
+// NewDynamic topology without preallocated nodes. The topology grows as nodes are added
+func NewDynamic() *TopologyGridStruct {
+	return New(0)
+}
+
+// grow increases the number of nodes the topology can hold and copies existing arcs to larger graphs
+func (t *TopologyGridStruct) grow(numberOfNodes int) {
+	nodes := make([]NodeStruct, numberOfNodes)
+	copy(nodes, t.nodes)
+	t.nodes = nodes
+
+	t.currentGraph = resizeGraph(t.currentGraph, numberOfNodes)
+	t.fullGraph = resizeGraph(t.fullGraph, numberOfNodes)
+	t.weightedGraph = resizeGraph(t.weightedGraph, numberOfNodes)
+}
+
+// resizeGraph returns a copy of the graph with the new number of vertices
+func resizeGraph(g *graph.Mutable, order int) *graph.Mutable {
+	resized := graph.New(order)
+
+	for v := 0; v < g.Order(); v++ {
+		g.Visit(v, func(w int, c int64) bool {
+			resized.AddCost(v, w, c)
+			return false
+		})
 	}
+
+	return resized
 }
 
 // EquipmentNameByEquipmentId returns a string with node name from the equipment id
@@ -165,507 +251,1594 @@ func (t *TopologyGridStruct) EquipmentElectricalStateByEquipmentId(id int) (uint
 	return equipment.electricalState, exists
 }
 
-func (t *TopologyGridStruct) EquipmentSwitchStateByEquipmentId(id int) (int, bool) {
+// EquipmentElectricalStateById returns an equipment electrical state by the equipment id
+// or ErrEquipmentNotFound if there is no such equipment
+func (t *TopologyGridStruct) EquipmentElectricalStateById(equipmentId int) (uint8, error) {
 	t.RLock()
-	equipment, exists := t.equipment[id]
-	t.RUnlock()
-
-	return equipment.switchState, exists
-}
+	defer t.RUnlock()
 
-// EquipmentNameByEdgeId returns a string with node name from the node id
-func (t *TopologyGridStruct) EquipmentNameByEdgeId(id int) string {
-	if idx, exists := t.edgeIdxFromEdgeId[id]; exists {
-		return t.EquipmentNameByEdgeIdx(idx)
-	} else {
-		return ""
+	if equipment, exists := t.equipment[equipmentId]; exists {
+		return equipment.electricalState, nil
 	}
-}
 
-// EquipmentNameByEdgeIdArray returns a string with node names separated by ',' from an array of node ids
-func (t *TopologyGridStruct) EquipmentNameByEdgeIdArray(idArray []int) string {
-	var name string
-	for i, id := range idArray {
-		if i != 0 {
-			name += ","
-		}
-		name += t.EquipmentNameByEdgeId(id)
-	}
-	return name
+	return StateIsolated, equipmentNotFound(equipmentId)
 }
 
-// EquipmentIdByEdgeId returns equipment identifier by corresponded edge id
-func (t *TopologyGridStruct) EquipmentIdByEdgeId(edgeId int) (int, error) {
-	if edgeIdx, exists := t.edgeIdxFromEdgeId[edgeId]; exists {
-		return t.edges[edgeIdx].equipmentId, nil
+// EquipmentElectricalStates returns a map of electrical states for all equipment: EquipmentId -> electrical state
+func (t *TopologyGridStruct) EquipmentElectricalStates() map[int]uint8 {
+	t.RLock()
+	defer t.RUnlock()
+
+	states := make(map[int]uint8, len(t.equipment))
+	for id, equipment := range t.equipment {
+		states[id] = equipment.electricalState
 	}
-	return 0, errors.New(fmt.Sprintf("EquipmentIdByEdgeId: edge idx was not found for edge id %d", edgeId))
+
+	return states
 }
 
-// SetSwitchStateByEquipmentId set switchState field and changes current topology graph
-func (t *TopologyGridStruct) SetSwitchStateByEquipmentId(equipmentId int, switchState int) error {
-	var err error = nil
+// EquipmentIdsByStateAndType returns sorted ids of equipment with the type (TypeAllEquipment for any type)
+// whose electrical state includes any of the states in the mask. StateIsolated matches equipment with no state bits set only
+func (t *TopologyGridStruct) EquipmentIdsByStateAndType(stateMask uint8, typeId int) []int {
+	t.RLock()
+	defer t.RUnlock()
 
-	if equipment, exists := t.equipment[equipmentId]; exists {
-		equipment.switchState = switchState
-		t.equipment[equipmentId] = equipment
+	equipmentIds := make([]int, 0)
 
-		var cost int64
-		if equipment.typeId == TypeCircuitBreaker {
-			cost = 1
-		} else if equipment.typeId == TypeDisconnectSwitch {
-			cost = 0
-		} else {
-			return errors.New(fmt.Sprintf("equipment id %d is not a switch", equipmentId))
+	for id, equipment := range t.equipment {
+		if typeId != TypeAllEquipment && equipment.typeId != typeId {
+			continue
 		}
 
-		for _, edgeId := range t.edgeIdArrayFromEquipmentId[equipmentId] {
-			if edgeIdx, exists := t.edgeIdxFromEdgeId[edgeId]; exists {
-				edge := t.edges[edgeIdx]
-
-				node1idx, existsNode1 := t.nodeIdxFromNodeId[edge.terminal.node1Id]
-				node2idx, existsNode2 := t.nodeIdxFromNodeId[edge.terminal.node2Id]
-
-				if existsNode1 && existsNode2 {
-					if switchState == 1 {
-						t.Lock()
-						t.currentGraph.AddBothCost(node1idx, node2idx, cost)
-						t.Unlock()
-					} else {
-						t.Lock()
-						t.currentGraph.DeleteBoth(node1idx, node2idx)
-						t.Unlock()
-					}
-				} else {
-					return errors.New(fmt.Sprintf("Nodes %d:%d are not found", edge.terminal.node1Id, edge.terminal.node2Id))
-				}
-			}
+		if (stateMask == StateIsolated && equipment.electricalState == StateIsolated) || equipment.electricalState&stateMask != 0 {
+			equipmentIds = append(equipmentIds, id)
 		}
-
-	} else {
-		err = errors.New(fmt.Sprintf("%d - no such equipment", equipmentId))
 	}
 
-	return err
-}
+	sort.Ints(equipmentIds)
 
-// AddNode to grid topology
-func (t *TopologyGridStruct) AddNode(id int, equipmentId int, equipmentTypeId int, equipmentName string) {
+	return equipmentIds
+}
 
-	if equipmentId != 0 {
-		t.equipment[equipmentId] = EquipmentStruct{
-			id:              equipmentId,
-			typeId:          equipmentTypeId,
-			name:            equipmentName,
-			electricalState: StateIsolated,
-			poweredBy:       make(map[int]int64),
-		}
-	}
+// DeEnergizedConsumers returns sorted ids of consumers whose electrical state does not include StateEnergized
+func (t *TopologyGridStruct) DeEnergizedConsumers() []int {
+	t.RLock()
+	defer t.RUnlock()
 
-	t.nodes[t.nodeIdx] = NodeStruct{idx: t.nodeIdx, id: id, equipmentId: equipmentId}
+	return t.deEnergizedConsumers()
+}
 
-	t.nodeIdxFromNodeId[id] = t.nodeIdx
+// DeEnergizedConsumersByPriority returns ids of consumers whose electrical state does not include StateEnergized
+// sorted by priority from the highest, consumers with the same priority are sorted by id
+func (t *TopologyGridStruct) DeEnergizedConsumersByPriority() []int {
+	t.RLock()
+	defer t.RUnlock()
 
-	if _, exists := t.nodeIdArrayFromEquipmentId[equipmentId]; !exists {
-		t.nodeIdArrayFromEquipmentId[equipmentId] = make([]int, 0)
-	}
-	t.nodeIdArrayFromEquipmentId[equipmentId] = append(t.nodeIdArrayFromEquipmentId[equipmentId], id)
+	equipmentIds := t.deEnergizedConsumers()
 
-	if _, exists := t.nodeIdArrayFromEquipmentTypeId[equipmentTypeId]; !exists {
-		t.nodeIdArrayFromEquipmentTypeId[equipmentTypeId] = make([]int, 0)
-	}
-	t.nodeIdArrayFromEquipmentTypeId[equipmentTypeId] = append(t.nodeIdArrayFromEquipmentTypeId[equipmentTypeId], id)
+	sort.SliceStable(equipmentIds, func(i, j int) bool {
+		return t.equipment[equipmentIds[i]].priority > t.equipment[equipmentIds[j]].priority
+	})
 
-	t.nodeIdx += 1
+	return equipmentIds
 }
 
-// AddEdge to grid topology
-func (t *TopologyGridStruct) AddEdge(id int, terminal1 int, terminal2 int, state int, equipmentId int, equipmentTypeId int, equipmentName string) error {
-	terminal := TerminalStruct{node1Id: terminal1, node2Id: terminal2}
-	t.edges = append(t.edges,
-		EdgeStruct{idx: t.edgeIdx,
-			id:          id,
-			equipmentId: equipmentId,
-			terminal:    terminal,
-		})
+func (t *TopologyGridStruct) deEnergizedConsumers() []int {
+	equipmentIds := make([]int, 0)
 
-	if equipmentId != 0 {
-		t.equipment[equipmentId] = EquipmentStruct{id: equipmentId,
-			typeId:          equipmentTypeId,
-			name:            equipmentName,
-			electricalState: StateIsolated,
-			poweredBy:       make(map[int]int64),
-			switchState:     state,
+	for id, equipment := range t.equipment {
+		if equipment.typeId == TypeConsumer && equipment.electricalState&StateEnergized == 0 {
+			equipmentIds = append(equipmentIds, id)
 		}
 	}
 
-	t.edgeIdxFromEdgeId[id] = t.edgeIdx
+	sort.Ints(equipmentIds)
 
-	if _, exists := t.nodeIdArrayFromEquipmentId[equipmentId]; !exists {
-		t.nodeIdArrayFromEquipmentId[equipmentId] = make([]int, 0)
-	}
-	t.nodeIdArrayFromEquipmentId[equipmentId] = append(t.nodeIdArrayFromEquipmentId[equipmentId], terminal1)
-	t.nodeIdArrayFromEquipmentId[equipmentId] = append(t.nodeIdArrayFromEquipmentId[equipmentId], terminal2)
+	return equipmentIds
+}
 
-	if _, exists := t.edgeIdArrayFromEquipmentId[equipmentId]; !exists {
-		t.edgeIdArrayFromEquipmentId[equipmentId] = make([]int, 0)
+// EquipmentPoweredBy returns a copy of the map PowerNodeId -> number of switches between the power node and the equipment,
+// calculated by SetEquipmentElectricalState
+func (t *TopologyGridStruct) EquipmentPoweredBy(equipmentId int) (map[int]int64, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	equipment, exists := t.equipment[equipmentId]
+	if !exists {
+		return nil, equipmentNotFound(equipmentId)
 	}
-	t.edgeIdArrayFromEquipmentId[equipmentId] = append(t.edgeIdArrayFromEquipmentId[equipmentId], id)
 
-	if _, exists := t.edgeIdArrayFromTerminalStruct[terminal]; !exists {
-		t.edgeIdArrayFromTerminalStruct[terminal] = make([]int, 0)
+	poweredBy := make(map[int]int64, len(equipment.poweredBy))
+	for powerNodeId, numberOfSwitches := range equipment.poweredBy {
+		poweredBy[powerNodeId] = numberOfSwitches
 	}
 
-	t.edgeIdArrayFromTerminalStruct[terminal] = append(t.edgeIdArrayFromTerminalStruct[terminal], id)
+	return poweredBy, nil
+}
 
-	if _, exists := t.edgeIdArrayFromEquipmentTypeId[equipmentTypeId]; !exists {
-		t.edgeIdArrayFromEquipmentTypeId[equipmentTypeId] = make([]int, 0)
-	}
+// EquipmentDistance is an equipment id and the number of switches between a power node and the equipment
+type EquipmentDistance struct {
+	EquipmentId      int
+	NumberOfSwitches int64
+}
 
-	t.edgeIdArrayFromEquipmentTypeId[equipmentTypeId] = append(t.edgeIdArrayFromEquipmentTypeId[equipmentTypeId], id)
+// EquipmentByDistanceFromPower returns all equipment powered from the power node, calculated by
+// SetEquipmentElectricalState, sorted by the number of switches and then by the equipment id. Equipment fed through
+// several routes is listed once with the fewest switches, supply from other power nodes is ignored
+func (t *TopologyGridStruct) EquipmentByDistanceFromPower(powerNodeId int) ([]EquipmentDistance, error) {
+	t.RLock()
+	defer t.RUnlock()
 
-	if _, exists := t.edgeIdArrayFromNodeId[terminal1]; !exists {
-		t.edgeIdArrayFromNodeId[terminal1] = make([]int, 0)
+	if _, exists := t.nodeIdxFromNodeId[powerNodeId]; !exists {
+		return nil, nodeNotFound(powerNodeId)
 	}
 
-	t.edgeIdArrayFromNodeId[terminal1] = append(t.edgeIdArrayFromNodeId[terminal1], id)
-
-	if _, exists := t.edgeIdArrayFromNodeId[terminal2]; !exists {
-		t.edgeIdArrayFromNodeId[terminal2] = make([]int, 0)
+	distances := make([]EquipmentDistance, 0)
+	for id, equipment := range t.equipment {
+		if numberOfSwitches, exists := equipment.poweredBy[powerNodeId]; exists {
+			distances = append(distances, EquipmentDistance{EquipmentId: id, NumberOfSwitches: numberOfSwitches})
+		}
 	}
 
-	t.edgeIdArrayFromNodeId[terminal2] = append(t.edgeIdArrayFromNodeId[terminal2], id)
+	sort.Slice(distances, func(i, j int) bool {
+		if distances[i].NumberOfSwitches != distances[j].NumberOfSwitches {
+			return distances[i].NumberOfSwitches < distances[j].NumberOfSwitches
+		}
+		return distances[i].EquipmentId < distances[j].EquipmentId
+	})
 
-	t.edgeIdx += 1
+	return distances, nil
+}
 
-	node1idx, existsNode1 := t.nodeIdxFromNodeId[terminal1]
-	node2idx, existsNode2 := t.nodeIdxFromNodeId[terminal2]
+// EquipmentIsPoweredFrom returns true and the number of switches if the equipment is powered from the power node
+func (t *TopologyGridStruct) EquipmentIsPoweredFrom(equipmentId int, powerNodeId int) (bool, int64) {
+	t.RLock()
+	defer t.RUnlock()
 
-	// Edge cost == 0 but for Circuit Breaker cost == 1, so we can calculate the shortest path between two nodes
-	// to know how many CBs between ones
-	var cost int64 = 0
-	if equipmentTypeId == TypeCircuitBreaker {
-		cost = 1
-	}
+	numberOfSwitches, exists := t.equipment[equipmentId].poweredBy[powerNodeId]
 
-	if existsNode1 && existsNode2 {
-		if state == 1 {
-			t.currentGraph.AddBothCost(node1idx, node2idx, cost)
-		}
+	return exists, numberOfSwitches
+}
 
-		if equipmentTypeId != TypeDisconnectSwitch || (equipmentTypeId == TypeDisconnectSwitch && state == 1) {
-			t.fullGraph.AddBothCost(node1idx, node2idx, cost)
-		}
+// SetNodeCoordinates sets the node coordinates used by graphical exports
+func (t *TopologyGridStruct) SetNodeCoordinates(nodeId int, x float64, y float64) error {
+	t.Lock()
+	defer t.Unlock()
 
-	} else {
-		return errors.New(fmt.Sprintf("Nodes %d:%d are not found", terminal1, terminal2))
+	if _, exists := t.nodeIdxFromNodeId[nodeId]; !exists {
+		return nodeNotFound(nodeId)
 	}
 
+	t.coordinatesFromNodeId[nodeId] = CoordinatesStruct{x: x, y: y}
+	t.recordChange(ChangeNodeData, nodeId)
+
 	return nil
 }
 
-// NodeIsPoweredBy returns an array of nodes id with the type of equipment "TypePower"
-// from which the specified node is powered with the current switchState of the circuit breakers
-func (t *TopologyGridStruct) NodeIsPoweredBy(nodeId int) ([]int, error) {
-	poweredBy := make([]int, 0)
+// NodeCoordinates returns the node coordinates and true if they were set
+func (t *TopologyGridStruct) NodeCoordinates(nodeId int) (float64, float64, bool) {
+	t.RLock()
+	defer t.RUnlock()
 
-	nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
+	coordinates, exists := t.coordinatesFromNodeId[nodeId]
 
+	return coordinates.x, coordinates.y, exists
+}
+
+// NodeElectricalState returns a node electrical state by the node id
+func (t *TopologyGridStruct) NodeElectricalState(nodeId int) (uint8, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
 	if !exists {
-		return nil, errors.New(fmt.Sprintf("node idx was not found for node id %d", nodeId))
+		return StateIsolated, nodeNotFound(nodeId)
 	}
 
-	for _, nodeTypePowerId := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+	return t.nodes[nodeIdx].electricalState, nil
+}
 
-		nodeTypePowerIdx, exists := t.nodeIdxFromNodeId[nodeTypePowerId]
+// SwitchTerminalStates returns electrical states of both terminals of the equipment edge.
+// Returns ErrEquipmentHasNoEdges if the equipment was not added with an edge
+func (t *TopologyGridStruct) SwitchTerminalStates(equipmentId int) (uint8, uint8, error) {
+	t.RLock()
+	defer t.RUnlock()
 
-		if !exists {
-			return nil, errors.New(fmt.Sprintf("node idx was not found for node id %d", nodeId))
-		}
+	if _, exists := t.equipment[equipmentId]; !exists {
+		return StateIsolated, StateIsolated, equipmentNotFound(equipmentId)
+	}
 
-		t.RLock()
-		path, _ := graph.ShortestPath(t.currentGraph, nodeTypePowerIdx, nodeIdx)
-		t.RUnlock()
-		if len(path) > 0 {
-			poweredBy = append(poweredBy, nodeTypePowerId)
-		}
+	edgeIds := t.edgeIdArrayFromEquipmentId[equipmentId]
+	if len(edgeIds) == 0 {
+		return StateIsolated, StateIsolated, &IdError{Err: ErrEquipmentHasNoEdges, Id: equipmentId}
 	}
 
-	return poweredBy, nil
-}
+	edge := t.edges[t.edgeIdxFromEdgeId[edgeIds[0]]]
 
-// NodeCanBePoweredBy returns an array of nodes id with the type of equipment "Power",
-// from which the specified node can be powered regardless of the current switchState of the circuit breakers
-func (t *TopologyGridStruct) NodeCanBePoweredBy(nodeId int) ([]int, error) {
-	poweredBy := make([]int, 0)
+	return t.nodeElectricalState(edge.terminal.node1Id), t.nodeElectricalState(edge.terminal.node2Id), nil
+}
 
-	nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
+// nodeElectricalState returns the node electrical state or StateIsolated if there is no such node
+func (t *TopologyGridStruct) nodeElectricalState(nodeId int) uint8 {
+	if nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]; exists {
+		return t.nodes[nodeIdx].electricalState
+	}
+	return StateIsolated
+}
 
-	if !exists {
-		return nil, errors.New(fmt.Sprintf("node idx was not found for node id %d", nodeId))
+// IsNodeEnergized returns true if the node electrical state includes StateEnergized
+func (t *TopologyGridStruct) IsNodeEnergized(nodeId int) (bool, error) {
+	state, err := t.NodeElectricalState(nodeId)
+	if err != nil {
+		return false, err
 	}
 
-	for _, nodeTypePowerId := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+	return state&StateEnergized == StateEnergized, nil
+}
 
-		nodeTypePowerIdx, exists := t.nodeIdxFromNodeId[nodeTypePowerId]
+func (t *TopologyGridStruct) EquipmentSwitchStateByEquipmentId(id int) (int, bool) {
+	t.RLock()
+	equipment, exists := t.equipment[id]
+	t.RUnlock()
 
-		if !exists {
-			return nil, errors.New(fmt.Sprintf("node idx was not found for node id %d", nodeId))
-		}
+	return equipment.switchState, exists
+}
 
-		t.RLock()
-		path, _ := graph.ShortestPath(t.fullGraph, nodeTypePowerIdx, nodeIdx)
-		t.RUnlock()
+// EquipmentNameByEdgeId returns a string with node name from the node id
+func (t *TopologyGridStruct) EquipmentNameByEdgeId(id int) string {
+	if idx, exists := t.edgeIdxFromEdgeId[id]; exists {
+		return t.EquipmentNameByEdgeIdx(idx)
+	} else {
+		return ""
+	}
+}
 
-		if len(path) > 0 {
-			poweredBy = append(poweredBy, nodeTypePowerId)
+// EquipmentNameByEdgeIdArray returns a string with node names separated by ',' from an array of node ids
+func (t *TopologyGridStruct) EquipmentNameByEdgeIdArray(idArray []int) string {
+	var name string
+	for i, id := range idArray {
+		if i != 0 {
+			name += ","
 		}
+		name += t.EquipmentNameByEdgeId(id)
 	}
-
-	return poweredBy, nil
+	return name
 }
 
-// GetCircuitBreakersEdgeIdsNextToNode returns an array of circuit breakers id next to the node and map with visited equipment ids
-func (t *TopologyGridStruct) GetCircuitBreakersEdgeIdsNextToNode(nodeId int) ([]int, map[int]bool, error) {
-	var exists bool
-	var nodeIdx int
-	var edgeCircuitBreakerIdx int
-	var visitedNodes = make(map[int]bool)
-
-	circuitBreakersEdgesId := make([]int, 0)
-
-	nodeIdx, exists = t.nodeIdxFromNodeId[nodeId]
+// EquipmentIdByEdgeId returns equipment identifier by corresponded edge id
+func (t *TopologyGridStruct) EquipmentIdByEdgeId(edgeId int) (int, error) {
+	t.RLock()
+	defer t.RUnlock()
 
-	if !exists {
-		return nil, nil, errors.New(fmt.Sprintf("node idx was not found for node id %d", nodeId))
+	if edgeIdx, exists := t.edgeIdxFromEdgeId[edgeId]; exists {
+		return t.edges[edgeIdx].equipmentId, nil
 	}
+	return 0, edgeNotFound(edgeId)
+}
 
-	for _, edgeCircuitBreakerId := range t.edgeIdArrayFromEquipmentTypeId[TypeCircuitBreaker] {
+// EquipmentIdByNodeId returns equipment identifier by corresponded node id
+func (t *TopologyGridStruct) EquipmentIdByNodeId(nodeId int) (int, error) {
+	t.RLock()
+	defer t.RUnlock()
 
-		edgeCircuitBreakerIdx, exists = t.edgeIdxFromEdgeId[edgeCircuitBreakerId]
+	if nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]; exists {
+		return t.nodes[nodeIdx].equipmentId, nil
+	}
+	return 0, nodeNotFound(nodeId)
+}
 
-		if !exists {
-			return nil, nil, errors.New(fmt.Sprintf("node idx was not found for node id %d", nodeId))
-		}
+// NodeIdsByEquipmentId returns sorted ids of nodes added with the equipment
+func (t *TopologyGridStruct) NodeIdsByEquipmentId(equipmentId int) []int {
+	t.RLock()
+	defer t.RUnlock()
 
-		circuitBreaker := t.edges[edgeCircuitBreakerIdx]
+	nodeIds := slices.Clone(t.nodeIdArrayFromEquipmentId[equipmentId])
+	sort.Ints(nodeIds)
 
-		t.RLock()
-		path, pathLen := graph.ShortestPath(t.fullGraph, t.nodeIdxFromNodeId[circuitBreaker.terminal.node1Id], nodeIdx)
-		t.RUnlock()
+	return nodeIds
+}
 
-		if len(path) > 0 && pathLen == 0 {
-			circuitBreakersEdgesId = append(circuitBreakersEdgesId, edgeCircuitBreakerId)
-			for _, _nodeIdx := range path {
-				equipmentId := t.nodes[_nodeIdx].equipmentId
-				visitedNodes[equipmentId] = true
-			}
-		} else {
-			t.RLock()
-			path, pathLen = graph.ShortestPath(t.fullGraph, t.nodeIdxFromNodeId[circuitBreaker.terminal.node2Id], nodeIdx)
-			t.RUnlock()
+// EdgeIdsByEquipmentId returns sorted ids of edges added with the equipment
+func (t *TopologyGridStruct) EdgeIdsByEquipmentId(equipmentId int) []int {
+	t.RLock()
+	defer t.RUnlock()
 
-			if len(path) > 0 && pathLen == 0 {
-				circuitBreakersEdgesId = append(circuitBreakersEdgesId, edgeCircuitBreakerId)
-				for _, _nodeIdx := range path {
-					equipmentId := t.nodes[_nodeIdx].equipmentId
-					visitedNodes[equipmentId] = true
-				}
-			}
-		}
-	}
+	edgeIds := slices.Clone(t.edgeIdArrayFromEquipmentId[equipmentId])
+	sort.Ints(edgeIds)
 
-	return circuitBreakersEdgesId, visitedNodes, nil
+	return edgeIds
 }
 
-// BfsFromNodeId traverses current graph in breadth-first order starting at nodeStart
-func (t *TopologyGridStruct) BfsFromNodeId(nodeIdStart int) []TerminalStruct {
+// NodeIdsByType returns sorted ids of nodes with the type of equipment
+func (t *TopologyGridStruct) NodeIdsByType(typeId int) []int {
+	t.RLock()
+	defer t.RUnlock()
 
-	var path []TerminalStruct
+	nodeIds := slices.Clone(t.nodeIdArrayFromEquipmentTypeId[typeId])
+	sort.Ints(nodeIds)
 
-	graph.BFS(graph.Sort(t.currentGraph), t.nodeIdxFromNodeId[nodeIdStart], func(v, w int, c int64) {
-		path = append(path, TerminalStruct{node1Id: t.nodes[v].id, node2Id: t.nodes[w].id, numberOfSwitches: c})
-	})
-	return path
+	return nodeIds
 }
 
-// GetAsGraphMl returns a string with a graph represented by the graph modeling language
-func (t *TopologyGridStruct) GetAsGraphMl() string {
-	var graphMl string
-	var graphics string
-
-	const GraphicsPower = "\n    graphics\n    [\n      type \"star6\"\n      fill \"#FF0000\"\n    ]"
-	const GraphicsConsumer = "\n    graphics\n    [\n      type \"triangle\"\n      fill \"#FFCC00\"\n    ]"
-	const GraphicsJoin = "\n    graphics\n    [\n      type \"ellipse\"\n      fill \"#808080\"\n      w 5.0\n      h 5.0\n    ]"
-	const GraphicsLine = "\n    graphics\n    [\n      type \"rectangle\"\n      fill \"#FF8080\"\n      w 40.0\n      h 10.0\n    ]"
+// EdgeIdsByType returns sorted ids of edges with the type of equipment
+func (t *TopologyGridStruct) EdgeIdsByType(typeId int) []int {
+	t.RLock()
+	defer t.RUnlock()
 
-	const GraphicsStateOff = "\n    graphics\n    [\n    style \"dotted\"\n      fill \"#000000\"\n    ]"
-	const GraphicsCircuitBreakerOn = "\n    graphics\n    [\n    fill \"#FF0000\"\n    ]"
-	const GraphicsCircuitBreakerOff = "\n    graphics\n    [\n    style \"dotted\"\n      fill \"#FF0000\"\n    ]"
-	const GraphicsDisconnectSwitchOn = "\n    graphics\n    [\n    fill \"#00FF00\"\n    ]"
-	const GraphicsDisconnectSwitchOff = "\n    graphics\n    [\n    style \"dotted\"\n      fill \"#00FF00\"\n    ]"
+	edgeIds := slices.Clone(t.edgeIdArrayFromEquipmentTypeId[typeId])
+	sort.Ints(edgeIds)
 
-	for _, node := range t.nodes {
+	return edgeIds
+}
 
-		//if t.equipment[node.equipmentId].typeId == TypeConsumer {
-		//	continue
-		//}
+// EquipmentIdsByType returns sorted ids of equipment with the type (TypeAllEquipment for any type),
+// equipment with several nodes or edges is listed once
+func (t *TopologyGridStruct) EquipmentIdsByType(typeId int) []int {
+	t.RLock()
+	defer t.RUnlock()
 
-		if t.equipment[node.equipmentId].typeId == TypePower {
-			graphics = GraphicsPower
-		} else if t.equipment[node.equipmentId].typeId == TypeConsumer {
-			graphics = GraphicsConsumer
-		} else if t.equipment[node.equipmentId].typeId == TypeLine {
-			graphics = GraphicsLine
-		} else {
-			graphics = GraphicsJoin
+	equipmentIds := make([]int, 0)
+	for id, equipment := range t.equipment {
+		if typeId == TypeAllEquipment || equipment.typeId == typeId {
+			equipmentIds = append(equipmentIds, id)
 		}
-		graphMl += fmt.Sprintf("  node [%s\n    id %d\n    label \"%s\"\n  ]\n",
-			graphics, node.id, t.equipment[node.equipmentId].name)
 	}
 
-	for _, edge := range t.edges {
-		graphics = ""
-
-		//nodeIdx := t.nodeIdxFromNodeId[edge.terminal.node1Id]
-		//node := t.nodes[nodeIdx]
-		//if t.equipment[node.equipmentId].typeId == TypeConsumer {
-		//	continue
-		//}
-		//
-		//nodeIdx = t.nodeIdxFromNodeId[edge.terminal.node2Id]
-		//node = t.nodes[nodeIdx]
-		//if t.equipment[node.equipmentId].typeId == TypeConsumer {
-		//	continue
-		//}
-
-		if t.equipment[edge.equipmentId].switchState == 0 {
-			graphics = GraphicsStateOff
-		}
-
-		if t.equipment[edge.equipmentId].typeId == TypeCircuitBreaker {
-			if t.equipment[edge.equipmentId].switchState == 1 {
-				graphics = GraphicsCircuitBreakerOn
-			} else {
-				graphics = GraphicsCircuitBreakerOff
-			}
-		} else if t.equipment[edge.equipmentId].typeId == TypeDisconnectSwitch {
-			if t.equipment[edge.equipmentId].switchState == 1 {
-				graphics = GraphicsDisconnectSwitchOn
-			} else {
-				graphics = GraphicsDisconnectSwitchOff
-			}
+	sort.Ints(equipmentIds)
+
+	return equipmentIds
+}
+
+// NodeIds returns sorted ids of all nodes
+func (t *TopologyGridStruct) NodeIds() []int {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIds := make([]int, 0, t.nodeIdx)
+	for _, node := range t.nodes[:t.nodeIdx] {
+		nodeIds = append(nodeIds, node.id)
+	}
+
+	sort.Ints(nodeIds)
+
+	return nodeIds
+}
+
+// EdgeIds returns sorted ids of all edges
+func (t *TopologyGridStruct) EdgeIds() []int {
+	t.RLock()
+	defer t.RUnlock()
+
+	edgeIds := make([]int, 0, len(t.edges))
+	for _, edge := range t.edges {
+		edgeIds = append(edgeIds, edge.id)
+	}
+
+	sort.Ints(edgeIds)
+
+	return edgeIds
+}
+
+// EquipmentIds returns sorted ids of all equipment
+func (t *TopologyGridStruct) EquipmentIds() []int {
+	t.RLock()
+	defer t.RUnlock()
+
+	equipmentIds := make([]int, 0, len(t.equipment))
+	for id := range t.equipment {
+		equipmentIds = append(equipmentIds, id)
+	}
+
+	sort.Ints(equipmentIds)
+
+	return equipmentIds
+}
+
+// EdgeIdsBetweenNodes returns sorted ids of edges connecting two nodes in either direction
+func (t *TopologyGridStruct) EdgeIdsBetweenNodes(nodeId1 int, nodeId2 int) []int {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.edgeIdsBetweenNodes(nodeId1, nodeId2)
+}
+
+// edgeIdsBetweenNodes looks up edges by terminals in both orientations, since the topology is undirected
+func (t *TopologyGridStruct) edgeIdsBetweenNodes(nodeId1 int, nodeId2 int) []int {
+	edgeIds := make([]int, 0)
+	edgeIds = append(edgeIds, t.edgeIdArrayFromTerminalStruct[TerminalStruct{node1Id: nodeId1, node2Id: nodeId2}]...)
+
+	if nodeId1 != nodeId2 {
+		edgeIds = append(edgeIds, t.edgeIdArrayFromTerminalStruct[TerminalStruct{node1Id: nodeId2, node2Id: nodeId1}]...)
+	}
+
+	sort.Ints(edgeIds)
+
+	return edgeIds
+}
+
+// EdgeTerminals returns ids of two nodes connected by the edge
+func (t *TopologyGridStruct) EdgeTerminals(edgeId int) (int, int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	edgeIdx, exists := t.edgeIdxFromEdgeId[edgeId]
+	if !exists {
+		return 0, 0, edgeNotFound(edgeId)
+	}
+
+	terminal := t.edges[edgeIdx].terminal
+
+	return terminal.node1Id, terminal.node2Id, nil
+}
+
+// AdjacentNodes returns sorted distinct ids of nodes connected to the node by closed edges.
+// If useFullGraph is true, nodes connected by open switches are returned as well
+func (t *TopologyGridStruct) AdjacentNodes(nodeId int, useFullGraph bool) ([]int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	if _, exists := t.nodeIdxFromNodeId[nodeId]; !exists {
+		return nil, nodeNotFound(nodeId)
+	}
+
+	adjacent := make(map[int]bool)
+
+	for _, edgeId := range t.edgeIdArrayFromNodeId[nodeId] {
+		edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+
+		if !useFullGraph && !t.isEdgeClosed(edge) {
+			continue
+		}
+
+		if edge.terminal.node1Id != nodeId {
+			adjacent[edge.terminal.node1Id] = true
+		}
+
+		if edge.terminal.node2Id != nodeId {
+			adjacent[edge.terminal.node2Id] = true
+		}
+	}
+
+	nodeIds := make([]int, 0, len(adjacent))
+	for id := range adjacent {
+		nodeIds = append(nodeIds, id)
+	}
+
+	sort.Ints(nodeIds)
+
+	return nodeIds, nil
+}
+
+// NodeDegree returns the number of edges connected to the node regardless of the switch state.
+// Parallel edges are counted separately, an edge connecting the node to itself is counted twice
+func (t *TopologyGridStruct) NodeDegree(nodeId int) (int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	if _, exists := t.nodeIdxFromNodeId[nodeId]; !exists {
+		return 0, nodeNotFound(nodeId)
+	}
+
+	return len(t.edgeIdArrayFromNodeId[nodeId]), nil
+}
+
+// SetSwitchStateByEquipmentId set switchState field and changes current topology graph
+func (t *TopologyGridStruct) SetSwitchStateByEquipmentId(equipmentId int, switchState int) error {
+	t.Lock()
+	defer t.Unlock()
+
+	if equipment, exists := t.equipment[equipmentId]; exists {
+		if !isSwitchType(equipment.typeId) {
+			return &IdError{Err: ErrEquipmentIsNotSwitch, Id: equipmentId}
+		}
+	} else {
+		return equipmentNotFound(equipmentId)
+	}
+
+	return t.setSwitchState(equipmentId, switchState)
+}
+
+// SetSwitchState sets the switch state of the equipment and adds/removes arcs of its edges in the current topology graph.
+// It does nothing if the state is unchanged.
+func (t *TopologyGridStruct) SetSwitchState(equipmentId int, state int) error {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.setSwitchState(equipmentId, state)
+}
+
+// ApplySwitchStates sets switch states for several equipment at once under one lock.
+// All equipment ids and states are validated first, so either all states are applied or none.
+// Returns the sorted list of equipment ids whose state actually changed.
+func (t *TopologyGridStruct) ApplySwitchStates(states map[int]int) ([]int, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.applySwitchStates(states)
+}
+
+func (t *TopologyGridStruct) applySwitchStates(states map[int]int) ([]int, error) {
+	equipmentIds := make([]int, 0, len(states))
+	for equipmentId := range states {
+		equipmentIds = append(equipmentIds, equipmentId)
+	}
+	sort.Ints(equipmentIds)
+
+	for _, equipmentId := range equipmentIds {
+		if err := t.checkSwitchState(equipmentId, states[equipmentId]); err != nil {
+			return nil, err
+		}
+	}
+
+	changed := make([]int, 0)
+	terminals := make(map[TerminalStruct]bool)
+
+	for _, equipmentId := range equipmentIds {
+		equipment := t.equipment[equipmentId]
+
+		if equipment.switchState == states[equipmentId] {
+			continue
+		}
+
+		equipment.switchState = states[equipmentId]
+		t.equipment[equipmentId] = equipment
+		changed = append(changed, equipmentId)
+		t.recordChange(ChangeSwitchState, equipmentId)
+
+		for _, edgeId := range t.edgeIdArrayFromEquipmentId[equipmentId] {
+			terminals[t.edges[t.edgeIdxFromEdgeId[edgeId]].terminal] = true
+		}
+	}
+
+	for terminal := range terminals {
+		t.updateArcs(terminal.node1Id, terminal.node2Id)
+	}
+
+	return changed, nil
+}
+
+// SnapshotSwitchStates returns switch states of all circuit breakers and disconnect switches: EquipmentId -> switch state
+func (t *TopologyGridStruct) SnapshotSwitchStates() map[int]int {
+	t.RLock()
+	defer t.RUnlock()
+
+	states := make(map[int]int)
+	for id, equipment := range t.equipment {
+		if isSwitchType(equipment.typeId) {
+			states[id] = equipment.switchState
+		}
+	}
+
+	return states
+}
+
+// RestoreSwitchStates sets switch states from the snapshot made by SnapshotSwitchStates.
+// The snapshot is validated first, so nothing is changed if it references unknown equipment
+func (t *TopologyGridStruct) RestoreSwitchStates(snapshot map[int]int) error {
+	_, err := t.ApplySwitchStates(snapshot)
+	return err
+}
+
+// checkSwitchState checks that the switch state can be set for the equipment
+func (t *TopologyGridStruct) checkSwitchState(equipmentId int, state int) error {
+	if _, exists := t.equipment[equipmentId]; !exists {
+		return equipmentNotFound(equipmentId)
+	}
+
+	if len(t.edgeIdArrayFromEquipmentId[equipmentId]) == 0 {
+		return &IdError{Err: ErrEquipmentHasNoEdges, Id: equipmentId}
+	}
+
+	if !isValidSwitchState(state) {
+		return fmt.Errorf("%w: %d for equipment id %d", ErrInvalidSwitchState, state, equipmentId)
+	}
+
+	return nil
+}
+
+func (t *TopologyGridStruct) setSwitchState(equipmentId int, state int) error {
+	if err := t.checkSwitchState(equipmentId, state); err != nil {
+		return err
+	}
+
+	equipment := t.equipment[equipmentId]
+
+	if equipment.switchState == state {
+		return nil
+	}
+
+	equipment.switchState = state
+	t.equipment[equipmentId] = equipment
+	t.recordChange(ChangeSwitchState, equipmentId)
+
+	for _, edgeId := range t.edgeIdArrayFromEquipmentId[equipmentId] {
+		edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+		t.updateArcs(edge.terminal.node1Id, edge.terminal.node2Id)
+	}
+
+	return nil
+}
+
+// edgeState returns the equipment type and the current switch state of the edge.
+// Edges without equipment keep the type and the state they were added with.
+func (t *TopologyGridStruct) edgeState(edge EdgeStruct) (int, int) {
+	if equipment, exists := t.equipment[edge.equipmentId]; exists {
+		return equipment.typeId, equipment.switchState
+	}
+	return edge.typeId, edge.normalState
+}
+
+// isEdgeClosed returns true if the edge is in the current topology graph
+func (t *TopologyGridStruct) isEdgeClosed(edge EdgeStruct) bool {
+	_, state := t.edgeState(edge)
+	return state == SwitchStateClose && !t.isEdgeFaulted(edge)
+}
+
+// isInFullGraph returns true if the non-faulted edge of the equipment type is in the full topology graph:
+// disconnect and ground switches that are normally open are left out
+func isInFullGraph(typeId int, edge EdgeStruct) bool {
+	return typeId != TypeDisconnectSwitch && typeId != TypeGroundSwitch || edge.normalState != SwitchStateOpen
+}
+
+// isEdgeFaulted returns true if the edge equipment is faulted
+func (t *TopologyGridStruct) isEdgeFaulted(edge EdgeStruct) bool {
+	return t.equipment[edge.equipmentId].faulted
+}
+
+// edgeAdjacency returns indexes of edges accepted by the filter for every node index.
+// Edges with terminals that were not found are skipped
+func (t *TopologyGridStruct) edgeAdjacency(accept func(edge EdgeStruct) bool) [][]int {
+	adjacency := make([][]int, t.nodeIdx)
+
+	for edgeIdx, edge := range t.edges {
+		node1Idx, existsNode1 := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx, existsNode2 := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+
+		if !existsNode1 || !existsNode2 || !accept(edge) {
+			continue
+		}
+
+		adjacency[node1Idx] = append(adjacency[node1Idx], edgeIdx)
+		if node1Idx != node2Idx {
+			adjacency[node2Idx] = append(adjacency[node2Idx], edgeIdx)
+		}
+	}
+
+	return adjacency
+}
+
+// otherTerminalIdx returns the node index of the edge terminal opposite to the node index
+func (t *TopologyGridStruct) otherTerminalIdx(edge EdgeStruct, nodeIdx int) int {
+	node1Idx := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+	if node1Idx == nodeIdx {
+		return t.nodeIdxFromNodeId[edge.terminal.node2Id]
+	}
+	return node1Idx
+}
+
+// nodeTypeId returns the equipment type of the node.
+// Nodes without equipment keep the type they were added with.
+func (t *TopologyGridStruct) nodeTypeId(node NodeStruct) int {
+	if equipment, exists := t.equipment[node.equipmentId]; exists {
+		return equipment.typeId
+	}
+	return node.typeId
+}
+
+// edgeCost returns the cost of the edge in topology graphs.
+// Edge cost == 0 but for Circuit Breaker and Fuse cost == 1, so we can calculate the shortest path between two nodes
+// to know how many protective devices between ones
+func edgeCost(equipmentTypeId int) int64 {
+	if equipmentTypeId == TypeCircuitBreaker || equipmentTypeId == TypeFuse {
+		return 1
+	}
+	return 0
+}
+
+// updateArcs recalculates the arcs between two nodes in all topology graphs from all edges connecting them.
+// The current and the weighted graphs contain closed edges only, the full graph contains all edges except disconnect
+// and ground switches that were added in the open state. Edges of faulted equipment are skipped.
+// Parallel edges keep the lowest cost.
+func (t *TopologyGridStruct) updateArcs(node1Id int, node2Id int) {
+	t.graphVersion++
+
+	node1Idx, existsNode1 := t.nodeIdxFromNodeId[node1Id]
+	node2Idx, existsNode2 := t.nodeIdxFromNodeId[node2Id]
+
+	if !existsNode1 || !existsNode2 {
+		return
+	}
+
+	var currentCost int64 = -1
+	var fullCost int64 = -1
+	var weightedCost int64 = -1
+
+	for _, edgeId := range t.edgeIdsBetweenNodes(node1Id, node2Id) {
+		edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
+
+		if t.isEdgeFaulted(edge) {
+			continue
+		}
+
+		typeId, state := t.edgeState(edge)
+		cost := edgeCost(typeId)
+
+		if state == SwitchStateClose && (currentCost < 0 || cost < currentCost) {
+			currentCost = cost
+		}
+
+		if state == SwitchStateClose && (weightedCost < 0 || edge.weight < weightedCost) {
+			weightedCost = edge.weight
+		}
+
+		if isInFullGraph(typeId, edge) && (fullCost < 0 || cost < fullCost) {
+			fullCost = cost
+		}
+	}
+
+	if currentCost < 0 {
+		t.currentGraph.DeleteBoth(node1Idx, node2Idx)
+	} else {
+		t.currentGraph.AddBothCost(node1Idx, node2Idx, currentCost)
+	}
+
+	if fullCost < 0 {
+		t.fullGraph.DeleteBoth(node1Idx, node2Idx)
+	} else {
+		t.fullGraph.AddBothCost(node1Idx, node2Idx, fullCost)
+	}
+
+	if weightedCost < 0 {
+		t.weightedGraph.DeleteBoth(node1Idx, node2Idx)
+	} else {
+		t.weightedGraph.AddBothCost(node1Idx, node2Idx, weightedCost)
+	}
+}
+
+// AddNode to grid topology. If all preallocated nodes are used, the topology grows.
+// Returns ErrDuplicateNodeId if the node id already exists
+func (t *TopologyGridStruct) AddNode(id int, equipmentId int, equipmentTypeId int, equipmentName string) error {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.addNode(id, equipmentId, equipmentTypeId, equipmentName)
+}
+
+func (t *TopologyGridStruct) addNode(id int, equipmentId int, equipmentTypeId int, equipmentName string) error {
+	if _, exists := t.nodeIdxFromNodeId[id]; exists {
+		return &IdError{Err: ErrDuplicateNodeId, Id: id}
+	}
+
+	if t.nodeIdx == len(t.nodes) {
+		t.grow(max(2*len(t.nodes), 16))
+	}
+
+	// Preallocated slots never used are at the end of the nodes, slots freed by RemoveNode come before them
+	if t.nodeIdx == len(t.nodes)-t.unusedNodeSlots {
+		t.unusedNodeSlots--
+	}
+
+	if equipmentId != 0 {
+		t.equipment[equipmentId] = EquipmentStruct{
+			id:              equipmentId,
+			typeId:          equipmentTypeId,
+			name:            equipmentName,
+			electricalState: StateIsolated,
+			poweredBy:       make(map[int]int64),
+		}
+	}
+
+	t.nodes[t.nodeIdx] = NodeStruct{idx: t.nodeIdx, id: id, equipmentId: equipmentId, typeId: equipmentTypeId}
+
+	t.nodeIdxFromNodeId[id] = t.nodeIdx
+
+	if _, exists := t.nodeIdArrayFromEquipmentId[equipmentId]; !exists {
+		t.nodeIdArrayFromEquipmentId[equipmentId] = make([]int, 0)
+	}
+	t.nodeIdArrayFromEquipmentId[equipmentId] = append(t.nodeIdArrayFromEquipmentId[equipmentId], id)
+
+	if _, exists := t.nodeIdArrayFromEquipmentTypeId[equipmentTypeId]; !exists {
+		t.nodeIdArrayFromEquipmentTypeId[equipmentTypeId] = make([]int, 0)
+	}
+	t.nodeIdArrayFromEquipmentTypeId[equipmentTypeId] = append(t.nodeIdArrayFromEquipmentTypeId[equipmentTypeId], id)
+
+	t.nodeIdx += 1
+	t.graphVersion++
+	t.recordChange(ChangeNodeAdded, id)
+
+	return nil
+}
+
+// AddEdge to grid topology. Returns ErrDuplicateEdgeId if the edge id already exists
+// and ErrInvalidSwitchState if the state is not one of the switch state constants
+func (t *TopologyGridStruct) AddEdge(id int, terminal1 int, terminal2 int, state int, equipmentId int, equipmentTypeId int, equipmentName string) error {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.addEdge(id, terminal1, terminal2, state, equipmentId, equipmentTypeId, equipmentName)
+}
+
+func (t *TopologyGridStruct) addEdge(id int, terminal1 int, terminal2 int, state int, equipmentId int, equipmentTypeId int, equipmentName string) error {
+	if _, exists := t.edgeIdxFromEdgeId[id]; exists {
+		return &IdError{Err: ErrDuplicateEdgeId, Id: id}
+	}
+
+	if !isValidSwitchState(state) {
+		return fmt.Errorf("%w: %d for edge id %d", ErrInvalidSwitchState, state, id)
+	}
+
+	terminal := TerminalStruct{node1Id: terminal1, node2Id: terminal2}
+	t.edges = append(t.edges,
+		EdgeStruct{idx: t.edgeIdx,
+			id:          id,
+			equipmentId: equipmentId,
+			typeId:      equipmentTypeId,
+			normalState: state,
+			terminal:    terminal,
+		})
+
+	if equipmentId != 0 {
+		t.equipment[equipmentId] = EquipmentStruct{id: equipmentId,
+			typeId:          equipmentTypeId,
+			name:            equipmentName,
+			electricalState: StateIsolated,
+			poweredBy:       make(map[int]int64),
+			switchState:     state,
+		}
+	}
+
+	t.edgeIdxFromEdgeId[id] = t.edgeIdx
+
+	if _, exists := t.terminalNodeIdsFromEdgeEquipmentId[equipmentId]; !exists {
+		t.terminalNodeIdsFromEdgeEquipmentId[equipmentId] = make([]int, 0)
+	}
+	t.terminalNodeIdsFromEdgeEquipmentId[equipmentId] = append(t.terminalNodeIdsFromEdgeEquipmentId[equipmentId], terminal1)
+	t.terminalNodeIdsFromEdgeEquipmentId[equipmentId] = append(t.terminalNodeIdsFromEdgeEquipmentId[equipmentId], terminal2)
+
+	if _, exists := t.edgeIdArrayFromEquipmentId[equipmentId]; !exists {
+		t.edgeIdArrayFromEquipmentId[equipmentId] = make([]int, 0)
+	}
+	t.edgeIdArrayFromEquipmentId[equipmentId] = append(t.edgeIdArrayFromEquipmentId[equipmentId], id)
+
+	if _, exists := t.edgeIdArrayFromTerminalStruct[terminal]; !exists {
+		t.edgeIdArrayFromTerminalStruct[terminal] = make([]int, 0)
+	}
+
+	t.edgeIdArrayFromTerminalStruct[terminal] = append(t.edgeIdArrayFromTerminalStruct[terminal], id)
+
+	if _, exists := t.edgeIdArrayFromEquipmentTypeId[equipmentTypeId]; !exists {
+		t.edgeIdArrayFromEquipmentTypeId[equipmentTypeId] = make([]int, 0)
+	}
+
+	t.edgeIdArrayFromEquipmentTypeId[equipmentTypeId] = append(t.edgeIdArrayFromEquipmentTypeId[equipmentTypeId], id)
+
+	if _, exists := t.edgeIdArrayFromNodeId[terminal1]; !exists {
+		t.edgeIdArrayFromNodeId[terminal1] = make([]int, 0)
+	}
+
+	t.edgeIdArrayFromNodeId[terminal1] = append(t.edgeIdArrayFromNodeId[terminal1], id)
+
+	if _, exists := t.edgeIdArrayFromNodeId[terminal2]; !exists {
+		t.edgeIdArrayFromNodeId[terminal2] = make([]int, 0)
+	}
+
+	t.edgeIdArrayFromNodeId[terminal2] = append(t.edgeIdArrayFromNodeId[terminal2], id)
+
+	t.edgeIdx += 1
+	t.recordChange(ChangeEdgeAdded, id)
+
+	_, existsNode1 := t.nodeIdxFromNodeId[terminal1]
+	_, existsNode2 := t.nodeIdxFromNodeId[terminal2]
+
+	if !existsNode1 {
+		return nodeNotFound(terminal1)
+	}
+
+	if !existsNode2 {
+		return nodeNotFound(terminal2)
+	}
+
+	t.updateArcs(terminal1, terminal2)
+
+	return nil
+}
+
+// RemoveEdge removes the edge from grid topology. The arcs between the edge terminals are removed from
+// both topology graphs unless another parallel edge remains
+func (t *TopologyGridStruct) RemoveEdge(edgeId int) error {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.removeEdge(edgeId)
+}
+
+func (t *TopologyGridStruct) removeEdge(edgeId int) error {
+	edgeIdx, exists := t.edgeIdxFromEdgeId[edgeId]
+	if !exists {
+		return edgeNotFound(edgeId)
+	}
+
+	edge := t.edges[edgeIdx]
+
+	t.edges = append(t.edges[:edgeIdx], t.edges[edgeIdx+1:]...)
+	delete(t.edgeIdxFromEdgeId, edgeId)
+
+	for idx := edgeIdx; idx < len(t.edges); idx++ {
+		t.edges[idx].idx = idx
+		t.edgeIdxFromEdgeId[t.edges[idx].id] = idx
+	}
+
+	t.edgeIdx = len(t.edges)
+
+	removeIdFromArrayMap(t.edgeIdArrayFromTerminalStruct, edge.terminal, edgeId)
+	removeIdFromArrayMap(t.edgeIdArrayFromNodeId, edge.terminal.node1Id, edgeId)
+	removeIdFromArrayMap(t.edgeIdArrayFromNodeId, edge.terminal.node2Id, edgeId)
+	removeIdFromArrayMap(t.edgeIdArrayFromEquipmentTypeId, edge.typeId, edgeId)
+	removeIdFromArrayMap(t.edgeIdArrayFromEquipmentId, edge.equipmentId, edgeId)
+	removeIdFromArrayMap(t.terminalNodeIdsFromEdgeEquipmentId, edge.equipmentId, edge.terminal.node1Id)
+	removeIdFromArrayMap(t.terminalNodeIdsFromEdgeEquipmentId, edge.equipmentId, edge.terminal.node2Id)
+
+	if !t.equipmentIsReferenced(edge.equipmentId) {
+		delete(t.equipment, edge.equipmentId)
+		t.deleteEquipmentAttributes(edge.equipmentId)
+		t.unbindPoints(edge.equipmentId)
+	}
+
+	t.updateArcs(edge.terminal.node1Id, edge.terminal.node2Id)
+	t.recordChange(ChangeEdgeRemoved, edgeId)
+
+	return nil
+}
+
+// RemoveNode removes the node from grid topology. The node must not be referenced by any edge.
+// The last added node takes the index of the removed one, so node indexes stay dense.
+func (t *TopologyGridStruct) RemoveNode(nodeId int) error {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.removeNode(nodeId)
+}
+
+func (t *TopologyGridStruct) removeNode(nodeId int) error {
+	nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
+	if !exists {
+		return nodeNotFound(nodeId)
+	}
+
+	if len(t.edgeIdArrayFromNodeId[nodeId]) != 0 {
+		return &IdError{Err: ErrNodeHasEdges, Id: nodeId}
+	}
+
+	node := t.nodes[nodeIdx]
+
+	lastIdx := t.nodeIdx - 1
+	if nodeIdx != lastIdx {
+		lastNode := t.nodes[lastIdx]
+		lastNode.idx = nodeIdx
+		t.nodes[nodeIdx] = lastNode
+		t.nodeIdxFromNodeId[lastNode.id] = nodeIdx
+
+		moveVertex(t.currentGraph, lastIdx, nodeIdx)
+		moveVertex(t.fullGraph, lastIdx, nodeIdx)
+		moveVertex(t.weightedGraph, lastIdx, nodeIdx)
+	}
+
+	t.nodes[lastIdx] = NodeStruct{}
+	t.nodeIdx = lastIdx
+	t.graphVersion++
+
+	delete(t.nodeIdxFromNodeId, nodeId)
+	delete(t.coordinatesFromNodeId, nodeId)
+	delete(t.voltageLevelFromNodeId, nodeId)
+
+	removeIdFromArrayMap(t.nodeIdArrayFromEquipmentTypeId, node.typeId, nodeId)
+	removeIdFromArrayMap(t.nodeIdArrayFromEquipmentId, node.equipmentId, nodeId)
+
+	if !t.equipmentIsReferenced(node.equipmentId) {
+		delete(t.equipment, node.equipmentId)
+		t.deleteEquipmentAttributes(node.equipmentId)
+		t.unbindPoints(node.equipmentId)
+	}
+
+	t.recordChange(ChangeNodeRemoved, nodeId)
+
+	return nil
+}
+
+// equipmentIsReferenced returns true if any node or edge was added with the equipment
+func (t *TopologyGridStruct) equipmentIsReferenced(equipmentId int) bool {
+	return len(t.nodeIdArrayFromEquipmentId[equipmentId]) != 0 || len(t.edgeIdArrayFromEquipmentId[equipmentId]) != 0
+}
+
+// moveVertex moves all arcs of the vertex to another vertex without arcs
+func moveVertex(g *graph.Mutable, from int, to int) {
+	type arc struct {
+		w int
+		c int64
+	}
+
+	arcs := make([]arc, 0)
+	g.Visit(from, func(w int, c int64) bool {
+		arcs = append(arcs, arc{w: w, c: c})
+		return false
+	})
+
+	for _, a := range arcs {
+		g.DeleteBoth(from, a.w)
+		if a.w == from {
+			g.AddBothCost(to, to, a.c)
+		} else {
+			g.AddBothCost(to, a.w, a.c)
+		}
+	}
+}
+
+// removeIdFromArrayMap removes the first occurrence of id from the array stored by the key and deletes the key
+// when the array becomes empty
+func removeIdFromArrayMap[K comparable](arrayMap map[K][]int, key K, id int) {
+	array := arrayMap[key]
+
+	for i, _id := range array {
+		if _id == id {
+			array = append(array[:i], array[i+1:]...)
+			break
+		}
+	}
+
+	if len(array) == 0 {
+		delete(arrayMap, key)
+	} else {
+		arrayMap[key] = array
+	}
+}
+
+// NodeIsPoweredBy returns a sorted array of nodes id with the type of equipment "TypePower"
+// from which the specified node is powered with the current switchState of the circuit breakers
+func (t *TopologyGridStruct) NodeIsPoweredBy(nodeId int) ([]int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.nodePoweredBy(nodeId, t.componentLabels(false))
+}
+
+// NodeCanBePoweredBy returns a sorted array of nodes id with the type of equipment "Power",
+// from which the specified node can be powered regardless of the current switchState of the circuit breakers
+func (t *TopologyGridStruct) NodeCanBePoweredBy(nodeId int) ([]int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.nodePoweredBy(nodeId, t.componentLabels(true))
+}
+
+// nodePoweredBy returns an array of power node ids in the same connected component as the node
+func (t *TopologyGridStruct) nodePoweredBy(nodeId int, labels []int) ([]int, error) {
+	poweredBy := make([]int, 0)
+
+	nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
+
+	if !exists {
+		return nil, nodeNotFound(nodeId)
+	}
+
+	for _, nodeTypePowerId := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
+
+		nodeTypePowerIdx, exists := t.nodeIdxFromNodeId[nodeTypePowerId]
+
+		if !exists {
+			return nil, nodeNotFound(nodeTypePowerId)
 		}
 
-		graphMl += fmt.Sprintf("  edge [%s\n    source %d\n    target %d\n    label \"%s\"\n  ]\n",
-			graphics, edge.terminal.node1Id, edge.terminal.node2Id, t.equipment[edge.equipmentId].name)
+		if labels[nodeTypePowerIdx] == labels[nodeIdx] {
+			poweredBy = append(poweredBy, nodeTypePowerId)
+		}
 	}
 
-	return "graph [\n" + graphMl + "]\n"
+	sort.Ints(poweredBy)
+
+	return poweredBy, nil
 }
 
-// SetEquipmentElectricalState for all equipment
-// TODO: The electrical state of the switches (edges) in the off state must be calculated by more sophisticated algorithm, since its terminals can have different electrical states.
-func (t *TopologyGridStruct) SetEquipmentElectricalState() {
-	t.Lock()
+// NodeMightBePoweredBy returns sorted node ids of power nodes that supply the node if switches in the unknown state
+// are closed. NodeIsPoweredBy treats them as open, so both bounds are known
+func (t *TopologyGridStruct) NodeMightBePoweredBy(nodeId int) ([]int, error) {
+	t.RLock()
+	defer t.RUnlock()
 
-	for id, equipment := range t.equipment {
-		equipment.electricalState = StateIsolated
-		t.equipment[id] = equipment
+	optimisticGraph := graph.Copy(t.currentGraph)
+
+	for _, edge := range t.edges {
+		if _, state := t.edgeState(edge); state != SwitchStateUnknown || t.isEdgeFaulted(edge) {
+			continue
+		}
+
+		node1Idx, existsNode1 := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+		node2Idx, existsNode2 := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+		if existsNode1 && existsNode2 && !optimisticGraph.Edge(node1Idx, node2Idx) {
+			optimisticGraph.AddBoth(node1Idx, node2Idx)
+		}
+	}
+
+	labels, _ := t.islandLabels(optimisticGraph)
+
+	return t.nodePoweredBy(nodeId, labels)
+}
+
+// NodesPoweredBy returns sorted node ids powered from the power node with the current switch states
+func (t *TopologyGridStruct) NodesPoweredBy(powerNodeId int) ([]int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIdxArray, err := t.nodeIdxArrayPoweredBy(powerNodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeIds := make([]int, 0, len(nodeIdxArray))
+	for _, nodeIdx := range nodeIdxArray {
+		nodeIds = append(nodeIds, t.nodes[nodeIdx].id)
+	}
+
+	sort.Ints(nodeIds)
+
+	return nodeIds, nil
+}
+
+// ConsumersPoweredBy returns sorted equipment ids of consumers powered from the power node with the current switch states
+func (t *TopologyGridStruct) ConsumersPoweredBy(powerNodeId int) ([]int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	consumers, err := t.consumerIdSetPoweredBy(powerNodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	equipmentIds := make([]int, 0, len(consumers))
+	for equipmentId := range consumers {
+		equipmentIds = append(equipmentIds, equipmentId)
+	}
+
+	sort.Ints(equipmentIds)
+
+	return equipmentIds, nil
+}
+
+// consumerIdSetPoweredBy returns a set of equipment ids of consumers reachable from the power node
+// in the current topology graph
+func (t *TopologyGridStruct) consumerIdSetPoweredBy(powerNodeId int) (map[int]bool, error) {
+	nodeIdxArray, err := t.nodeIdxArrayPoweredBy(powerNodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	consumers := make(map[int]bool)
+	for _, nodeIdx := range nodeIdxArray {
+		if node := t.nodes[nodeIdx]; node.equipmentId != 0 && t.nodeTypeId(node) == TypeConsumer {
+			consumers[node.equipmentId] = true
+		}
+	}
+
+	return consumers, nil
+}
+
+// nodeIdxArrayPoweredBy returns indexes of nodes reachable from the power node in the current topology graph
+// found by a single breadth-first search
+func (t *TopologyGridStruct) nodeIdxArrayPoweredBy(powerNodeId int) ([]int, error) {
+	powerNodeIdx, exists := t.nodeIdxFromNodeId[powerNodeId]
+	if !exists {
+		return nil, nodeNotFound(powerNodeId)
 	}
 
-	for idx, node := range t.nodes {
-		node.electricalState = StateIsolated
-		t.nodes[idx] = node
+	nodeIdxArray := []int{powerNodeIdx}
+
+	graph.BFS(t.currentGraph, powerNodeIdx, func(v, w int, c int64) {
+		nodeIdxArray = append(nodeIdxArray, w)
+	})
+
+	return nodeIdxArray, nil
+}
+
+// GetCircuitBreakersEdgeIdsNextToNode returns a sorted array of circuit breakers id next to the node and map with visited equipment ids.
+// Circuit breakers are looked up by the zone of the node in the full topology graph
+func (t *TopologyGridStruct) GetCircuitBreakersEdgeIdsNextToNode(nodeId int) ([]int, map[int]bool, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	var visitedNodes = make(map[int]bool)
+
+	circuitBreakersEdgesId := make([]int, 0)
+
+	nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
+
+	if !exists {
+		return nil, nil, nodeNotFound(nodeId)
 	}
 
-	for _, nodeIdOfPowerNode := range t.nodeIdArrayFromEquipmentTypeId[TypePower] {
-		cost := make(map[int]int64)
+	zones := t.zoneCache()
+	zone := zones.zoneFromNodeIdx[nodeIdx]
+
+	parentFromNodeIdx := zeroCostParents(t.fullGraph, nodeIdx)
+
+	for _, edgeCircuitBreakerId := range zones.edgeIdArrayFromTypeAndZone[TypeCircuitBreaker][zone] {
+		circuitBreaker := t.edges[t.edgeIdxFromEdgeId[edgeCircuitBreakerId]]
+
+		terminalIdx := t.nodeIdxFromNodeId[circuitBreaker.terminal.node1Id]
+		if zones.zoneFromNodeIdx[terminalIdx] != zone {
+			terminalIdx = t.nodeIdxFromNodeId[circuitBreaker.terminal.node2Id]
+		}
 
-		node := t.nodes[t.nodeIdxFromNodeId[nodeIdOfPowerNode]]
-		node.electricalState = StateEnergized
-		t.nodes[t.nodeIdxFromNodeId[nodeIdOfPowerNode]] = node
+		circuitBreakersEdgesId = append(circuitBreakersEdgesId, edgeCircuitBreakerId)
 
-		for _, terminal := range t.BfsFromNodeId(nodeIdOfPowerNode) {
-			cost[terminal.node2Id] += terminal.numberOfSwitches + cost[terminal.node1Id]
+		for _nodeIdx := terminalIdx; ; _nodeIdx = parentFromNodeIdx[_nodeIdx] {
+			equipmentId := t.nodes[_nodeIdx].equipmentId
+			visitedNodes[equipmentId] = true
 
-			node := t.nodes[t.nodeIdxFromNodeId[terminal.node1Id]]
-			node.electricalState |= StateEnergized
-			t.nodes[t.nodeIdxFromNodeId[terminal.node1Id]] = node
-			if node.equipmentId != 0 {
-				equipment := t.equipment[node.equipmentId]
-				equipment.electricalState |= StateEnergized
-				equipment.poweredBy[nodeIdOfPowerNode] = cost[terminal.node1Id]
-				t.equipment[node.equipmentId] = equipment
+			if _nodeIdx == nodeIdx {
+				break
 			}
+		}
+	}
 
-			for _, edgeId := range t.edgeIdArrayFromNodeId[node.id] {
-				edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
-				if edge.equipmentId != 0 {
-					equipment := t.equipment[edge.equipmentId]
-					equipment.electricalState |= StateEnergized
-					equipment.poweredBy[nodeIdOfPowerNode] = cost[terminal.node1Id]
-					t.equipment[edge.equipmentId] = equipment
-				}
+	sort.Ints(circuitBreakersEdgesId)
+
+	return circuitBreakersEdgesId, visitedNodes, nil
+}
+
+// CircuitBreakersNextToNodeCurrent returns a sorted array of circuit breaker edge ids next to the node
+// in the current topology graph, so breakers separated from the node by an open switch are skipped
+func (t *TopologyGridStruct) CircuitBreakersNextToNodeCurrent(nodeId int) ([]int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
+	if !exists {
+		return nil, nodeNotFound(nodeId)
+	}
+
+	parentFromNodeIdx := zeroCostParents(t.currentGraph, nodeIdx)
+
+	circuitBreakersEdgesId := make([]int, 0)
+
+	for _, edgeCircuitBreakerId := range t.edgeIdArrayFromEquipmentTypeId[TypeCircuitBreaker] {
+		circuitBreaker := t.edges[t.edgeIdxFromEdgeId[edgeCircuitBreakerId]]
+
+		for _, terminalId := range []int{circuitBreaker.terminal.node1Id, circuitBreaker.terminal.node2Id} {
+			terminalIdx, exists := t.nodeIdxFromNodeId[terminalId]
+			if !exists {
+				continue
 			}
 
-			node = t.nodes[t.nodeIdxFromNodeId[terminal.node2Id]]
-			node.electricalState |= StateEnergized
-			t.nodes[t.nodeIdxFromNodeId[terminal.node2Id]] = node
-			if node.equipmentId != 0 {
-				equipment := t.equipment[node.equipmentId]
-				equipment.electricalState |= StateEnergized
-				equipment.poweredBy[nodeIdOfPowerNode] = cost[terminal.node2Id]
-				t.equipment[node.equipmentId] = equipment
+			if _, reached := parentFromNodeIdx[terminalIdx]; reached {
+				circuitBreakersEdgesId = append(circuitBreakersEdgesId, edgeCircuitBreakerId)
+				break
 			}
+		}
+	}
 
-			for _, edgeId := range t.edgeIdArrayFromNodeId[node.id] {
-				edge := t.edges[t.edgeIdxFromEdgeId[edgeId]]
-				if edge.equipmentId != 0 {
-					equipment := t.equipment[edge.equipmentId]
-					equipment.electricalState |= StateEnergized
-					equipment.poweredBy[nodeIdOfPowerNode] = cost[terminal.node2Id]
-					t.equipment[edge.equipmentId] = equipment
-				}
+	sort.Ints(circuitBreakersEdgesId)
+
+	return circuitBreakersEdgesId, nil
+}
+
+// zeroCostParents returns the parent node index on a zero cost path to the start node for every node reachable
+// from the start node by zero cost arcs, i.e. without passing a circuit breaker
+func zeroCostParents(g *graph.Mutable, startIdx int) map[int]int {
+	parentFromNodeIdx := map[int]int{startIdx: startIdx}
+	queue := []int{startIdx}
+
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+
+		g.Visit(v, func(w int, c int64) bool {
+			if _, visited := parentFromNodeIdx[w]; c == 0 && !visited {
+				parentFromNodeIdx[w] = v
+				queue = append(queue, w)
+			}
+			return false
+		})
+	}
+
+	return parentFromNodeIdx
+}
+
+// BfsFromNodeId traverses current graph in breadth-first order starting at nodeStart
+func (t *TopologyGridStruct) BfsFromNodeId(nodeIdStart int) []TerminalStruct {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.bfsFromNodeId(t.sortedGraph(false), nodeIdStart)
+}
+
+// BfsFromNodeIdFull traverses full graph in breadth-first order starting at nodeStart, i.e. regardless
+// of the current switch states
+func (t *TopologyGridStruct) BfsFromNodeIdFull(nodeIdStart int) []TerminalStruct {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.bfsFromNodeId(t.sortedGraph(true), nodeIdStart)
+}
+
+func (t *TopologyGridStruct) bfsFromNodeId(g graph.Iterator, nodeIdStart int) []TerminalStruct {
+	var path []TerminalStruct
+
+	graph.BFS(g, t.nodeIdxFromNodeId[nodeIdStart], func(v, w int, c int64) {
+		path = append(path, TerminalStruct{node1Id: t.nodes[v].id, node2Id: t.nodes[w].id, numberOfSwitches: c})
+	})
+	return path
+}
+
+// DfsFromNodeId traverses current graph in depth-first order starting at nodeStart
+func (t *TopologyGridStruct) DfsFromNodeId(nodeIdStart int) []TerminalStruct {
+	t.RLock()
+	defer t.RUnlock()
+
+	var path []TerminalStruct
+
+	t.dfsFromNodeIdx(t.sortedGraph(false), t.nodeIdxFromNodeId[nodeIdStart], func(v, w int, c int64) {
+		path = append(path, TerminalStruct{node1Id: t.nodes[v].id, node2Id: t.nodes[w].id, numberOfSwitches: c})
+	})
+	return path
+}
+
+// FeederOrder returns node ids powered from the power node in the current graph in parent-before-child order,
+// so downstream values can be accumulated in one pass in the reverse order. Returns ErrLoopDetected
+// if the feeder is not radial
+func (t *TopologyGridStruct) FeederOrder(powerNodeId int) ([]int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	powerNodeIdx, exists := t.nodeIdxFromNodeId[powerNodeId]
+	if !exists {
+		return nil, nodeNotFound(powerNodeId)
+	}
+
+	nodeIds := []int{powerNodeId}
+	reached := map[int]bool{powerNodeId: true}
+
+	t.dfsFromNodeIdx(t.sortedGraph(false), powerNodeIdx, func(v, w int, c int64) {
+		nodeIds = append(nodeIds, t.nodes[w].id)
+		reached[t.nodes[w].id] = true
+	})
+
+	// A radial feeder has one edge less than nodes, parallel edges are loops as well
+	numberOfEdges := 0
+	for _, edge := range t.edges {
+		if reached[edge.terminal.node1Id] && reached[edge.terminal.node2Id] && t.isEdgeClosed(edge) {
+			numberOfEdges++
+		}
+	}
+
+	if numberOfEdges >= len(nodeIds) {
+		return nil, &IdError{Err: ErrLoopDetected, Id: powerNodeId}
+	}
+
+	return nodeIds, nil
+}
+
+// dfsFromNodeIdx traverses the graph in depth-first order and calls do for every arc to a node found
+func (t *TopologyGridStruct) dfsFromNodeIdx(g graph.Iterator, nodeIdxStart int, do func(v, w int, c int64)) {
+	visited := map[int]bool{nodeIdxStart: true}
+
+	var dfs func(v int)
+	dfs = func(v int) {
+		g.Visit(v, func(w int, c int64) bool {
+			if !visited[w] {
+				visited[w] = true
+				do(v, w, c)
+				dfs(w)
 			}
+			return false
+		})
+	}
+
+	dfs(nodeIdxStart)
+}
+
+// BfsFromNodeIdFunc traverses current graph starting at nodeStart in the order of the number of circuit breakers
+// between the start node and the found node, and calls visit with node ids of the arc the node was found by
+// on a path with the fewest circuit breakers and their number. Arcs without circuit breakers are traversed first,
+// so the numbers are minimal (0-1 breadth-first search). The traversal stops when visit returns false.
+// Nodes with more circuit breakers than maxSwitches, if given, are skipped.
+// The topology is locked for reading, so visit must not change it
+func (t *TopologyGridStruct) BfsFromNodeIdFunc(nodeIdStart int, visit func(from int, to int, switches int64) bool, maxSwitches ...int64) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIdxStart, exists := t.nodeIdxFromNodeId[nodeIdStart]
+	if !exists {
+		return nodeNotFound(nodeIdStart)
+	}
+
+	sorted := t.sortedGraph(false)
+	switches := map[int]int64{nodeIdxStart: 0}
+	from := map[int]int{nodeIdxStart: nodeIdxStart}
+	isVisited := make(map[int]bool)
+
+	// The deque of nodes to visit: nodes found by arcs without circuit breakers are pushed to the front stack
+	// and the others to the back queue, so nodes are taken in the nondecreasing order of the number of switches
+	front := make([]int, 0)
+	back := []int{nodeIdxStart}
+
+	for len(front) > 0 || len(back) > 0 {
+		var v int
+		if len(front) > 0 {
+			v, front = front[len(front)-1], front[:len(front)-1]
+		} else {
+			v, back = back[0], back[1:]
+		}
+
+		if isVisited[v] {
+			continue
+		}
+		isVisited[v] = true
+
+		if len(maxSwitches) > 0 && switches[v] > maxSwitches[0] {
+			break
+		}
+
+		if v != nodeIdxStart && !visit(t.nodes[from[v]].id, t.nodes[v].id, switches[v]) {
+			break
 		}
+
+		sorted.Visit(v, func(w int, c int64) bool {
+			if found, exists := switches[w]; exists && found <= switches[v]+c {
+				return false
+			}
+
+			switches[w] = switches[v] + c
+			from[w] = v
+
+			if c == 0 {
+				front = append(front, w)
+			} else {
+				back = append(back, w)
+			}
+
+			return false
+		})
 	}
+
+	return nil
+}
+
+// SetEquipmentElectricalState for all equipment.
+// Equipment of edges is energized if one of its edges is closed or both terminals of the edge are energized,
+// so an open switch between a live busbar and a dead feeder stays isolated. Use SwitchTerminalStates for the terminals
+func (t *TopologyGridStruct) SetEquipmentElectricalState() {
+	t.Lock()
+	changes := t.setEquipmentElectricalState()
 	t.Unlock()
+
+	t.notifyObservers(changes)
 }
 
+// PrintfEquipments prints EquipmentReport to the standard output.
+//
+// Deprecated: use EquipmentReport and log the result
 func (t *TopologyGridStruct) PrintfEquipments(typeId int) {
-	fmt.Printf("-- Equipment begin\n")
-	for _, equipment := range t.equipment {
+	fmt.Print(t.EquipmentReport(typeId))
+}
+
+// EquipmentReport returns a report of the equipment with the type (TypeAllEquipment for any type) sorted by
+// the equipment id: one line per equipment with the id, name, switch state, electrical state and the power nodes
+// the equipment is powered by
+func (t *TopologyGridStruct) EquipmentReport(typeId int) string {
+	t.RLock()
+	defer t.RUnlock()
+
+	equipmentIds := make([]int, 0, len(t.equipment))
+	for id, equipment := range t.equipment {
 		if typeId == TypeAllEquipment || typeId == equipment.typeId {
-			fmt.Printf("%4d:%30s:%2d:%2d <- %+v\n", equipment.id, equipment.name, equipment.switchState, equipment.electricalState, equipment.poweredBy)
+			equipmentIds = append(equipmentIds, id)
+		}
+	}
+
+	sort.Ints(equipmentIds)
+
+	var report strings.Builder
+
+	report.WriteString("-- Equipment begin\n")
+	for _, id := range equipmentIds {
+		equipment := t.equipment[id]
+		_, _ = fmt.Fprintf(&report, "%4d:%30s:%2d:%2d <- %+v\n", equipment.id, equipment.name, equipment.switchState, equipment.electricalState, equipment.poweredBy)
+	}
+	report.WriteString("-- Equipment end\n")
+
+	return report.String()
+}
+
+// String returns a summary of the topology: the number of nodes, edges and equipment, and the number of energized
+// and isolated equipment calculated by SetEquipmentElectricalState
+func (t *TopologyGridStruct) String() string {
+	t.RLock()
+	defer t.RUnlock()
+
+	var energized, isolated int
+
+	for _, equipment := range t.equipment {
+		if IsEnergized(equipment.electricalState) {
+			energized++
+		} else if IsIsolated(equipment.electricalState) {
+			isolated++
 		}
 	}
-	fmt.Printf("-- Equipment end\n")
+
+	return fmt.Sprintf("topology: %d nodes, %d edges, %d equipment, %d energized, %d isolated",
+		t.nodeIdx, len(t.edges), len(t.equipment), energized, isolated)
 }
 
 // GetFurthestEquipmentFromPower returns the furthest equipment from the power supply, the ID of the power supply node,
-// and the number of switches between the power supply and the equipment
-func (t *TopologyGridStruct) GetFurthestEquipmentFromPower(equipmentIds []int) (int, int, int64) {
-	var furthestEquipmentId = 0
-	var poweredByNodeId = 0
+// and the number of switches between the power supply and the equipment, calculated by SetEquipmentElectricalState.
+// Open switches are skipped. Ties are broken by the lowest equipment id, then by the lowest power node id.
+// Returns ErrNoPoweredEquipment if none of the equipment is powered
+func (t *TopologyGridStruct) GetFurthestEquipmentFromPower(equipmentIds []int) (int, int, int64, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.extremeEquipmentFromPower(equipmentIds, func(powerNodeId int) bool { return true }, true)
+}
 
-	poweredBy := make(map[int]int64)
+// GetFurthestEquipmentFromPowerNode is GetFurthestEquipmentFromPower restricted to the equipment powered by the power node
+func (t *TopologyGridStruct) GetFurthestEquipmentFromPowerNode(equipmentIds []int, powerNodeId int) (int, int, int64, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	if _, exists := t.nodeIdxFromNodeId[powerNodeId]; !exists {
+		return 0, 0, 0, nodeNotFound(powerNodeId)
+	}
+
+	return t.extremeEquipmentFromPower(equipmentIds, func(id int) bool { return id == powerNodeId }, true)
+}
+
+// GetNearestEquipmentToPower returns the nearest equipment to the power supply, the ID of the power supply node,
+// and the number of switches between the power supply and the equipment, the same way as GetFurthestEquipmentFromPower
+func (t *TopologyGridStruct) GetNearestEquipmentToPower(equipmentIds []int) (int, int, int64, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.extremeEquipmentFromPower(equipmentIds, func(powerNodeId int) bool { return true }, false)
+}
+
+// extremeEquipmentFromPower returns the furthest or the nearest equipment from the accepted power nodes
+func (t *TopologyGridStruct) extremeEquipmentFromPower(equipmentIds []int, acceptPowerNode func(powerNodeId int) bool, furthest bool) (int, int, int64, error) {
+	var extremeEquipmentId = 0
+	var poweredByNodeId = 0
+	var extremeNumberOfSwitches int64 = -1
 
 	for _, equipmentId := range equipmentIds {
 		equipment := t.equipment[equipmentId]
-		if equipment.switchState == 0 {
+		if len(t.edgeIdArrayFromEquipmentId[equipmentId]) != 0 && equipment.switchState == SwitchStateOpen {
 			continue
 		}
-		for _poweredByNodeId, numberOfSwitches := range equipment.poweredBy {
-			if poweredBy[_poweredByNodeId] < numberOfSwitches {
-				poweredBy[_poweredByNodeId] = numberOfSwitches
-				furthestEquipmentId = equipmentId
-				poweredByNodeId = _poweredByNodeId
+
+		for powerNodeId, numberOfSwitches := range equipment.poweredBy {
+			if !acceptPowerNode(powerNodeId) {
+				continue
+			}
+
+			isBeyond := numberOfSwitches > extremeNumberOfSwitches
+			if !furthest {
+				isBeyond = numberOfSwitches < extremeNumberOfSwitches
+			}
+
+			isExtreme := extremeNumberOfSwitches < 0 || isBeyond ||
+				numberOfSwitches == extremeNumberOfSwitches && (equipmentId < extremeEquipmentId ||
+					equipmentId == extremeEquipmentId && powerNodeId < poweredByNodeId)
+
+			if isExtreme {
+				extremeNumberOfSwitches = numberOfSwitches
+				extremeEquipmentId = equipmentId
+				poweredByNodeId = powerNodeId
 			}
 		}
 	}
 
-	return furthestEquipmentId, poweredByNodeId, poweredBy[poweredByNodeId]
+	if extremeNumberOfSwitches < 0 {
+		return 0, 0, 0, ErrNoPoweredEquipment
+	}
+
+	return extremeEquipmentId, poweredByNodeId, extremeNumberOfSwitches, nil
 }
 
 // GetFurthestEquipmentTerminalIdFromPower returns the farthest (from two) equipment node id (terminal) from the power source
@@ -673,7 +1846,11 @@ func (t *TopologyGridStruct) GetFurthestEquipmentTerminalIdFromPower(poweredByNo
 	var furthestNodeId = 0
 	var maxNumberOfSwitches int64 = 0
 
-	for _, nodeId := range t.nodeIdArrayFromEquipmentId[equipmentId] {
+	t.RLock()
+	terminalNodeIds := t.equipmentTerminalNodeIds(equipmentId)
+	t.RUnlock()
+
+	for _, nodeId := range terminalNodeIds {
 		t.RLock()
 		_, numberOfSwitches := graph.ShortestPath(t.currentGraph, t.nodeIdxFromNodeId[nodeId], t.nodeIdxFromNodeId[poweredByNodeId])
 		t.RUnlock()
@@ -686,13 +1863,17 @@ func (t *TopologyGridStruct) GetFurthestEquipmentTerminalIdFromPower(poweredByNo
 	return furthestNodeId
 }
 
-// GetCbListToEnergizeEquipment Returns a map of lists with equipment id of CBs that you must use to power up the selected equipment.
+// GetCbListToEnergizeEquipment Returns a map of sorted lists with equipment id of CBs that you must use to power up the selected equipment.
 // The mapping keys are the equipment identifier of the power nodes.
 func (t *TopologyGridStruct) GetCbListToEnergizeEquipment(equipmentId int) map[int][]int {
 
 	cbListToEnergizeEquipment := make(map[int][]int)
 
-	for _, nodeId := range t.nodeIdArrayFromEquipmentId[equipmentId] {
+	t.RLock()
+	terminalNodeIds := t.equipmentTerminalNodeIds(equipmentId)
+	t.RUnlock()
+
+	for _, nodeId := range terminalNodeIds {
 		if powerNodeIdArray, err := t.NodeCanBePoweredBy(nodeId); err == nil {
 
 			for _, poweredByNodeId := range powerNodeIdArray {
@@ -706,29 +1887,10 @@ func (t *TopologyGridStruct) GetCbListToEnergizeEquipment(equipmentId int) map[i
 				if numberOfSwitches != 0 {
 					if len(path) > 1 {
 						for i := 0; i < len(path)-1; i++ {
-							terminal := TerminalStruct{
-								node1Id: t.nodes[path[i]].id,
-								node2Id: t.nodes[path[i+1]].id,
-							}
-
-							if edgeIdArray, exists := t.edgeIdArrayFromTerminalStruct[terminal]; exists {
-								for _, edgeId := range edgeIdArray {
-									if equipmentInPathId, err := t.EquipmentIdByEdgeId(edgeId); err == nil {
-										if t.equipment[equipmentInPathId].typeId == TypeCircuitBreaker {
-											pathCb[equipmentInPathId] = true
-										}
-									}
-								}
-							}
-
-							terminal.node1Id, terminal.node2Id = terminal.node2Id, terminal.node1Id
-
-							if edgeIdArray, exists := t.edgeIdArrayFromTerminalStruct[terminal]; exists {
-								for _, edgeId := range edgeIdArray {
-									if equipmentInPathId, err := t.EquipmentIdByEdgeId(edgeId); err == nil {
-										if t.equipment[equipmentInPathId].typeId == TypeCircuitBreaker {
-											pathCb[equipmentInPathId] = true
-										}
+							for _, edgeId := range t.edgeIdsBetweenNodes(t.nodes[path[i]].id, t.nodes[path[i+1]].id) {
+								if equipmentInPathId, err := t.EquipmentIdByEdgeId(edgeId); err == nil {
+									if t.equipment[equipmentInPathId].typeId == TypeCircuitBreaker {
+										pathCb[equipmentInPathId] = true
 									}
 								}
 							}
@@ -743,6 +1905,7 @@ func (t *TopologyGridStruct) GetCbListToEnergizeEquipment(equipmentId int) map[i
 						cbListToEnergizeEquipment[powerNodeEquipmentId][i] = equipmentCbId
 						i += 1
 					}
+					sort.Ints(cbListToEnergizeEquipment[powerNodeEquipmentId])
 				}
 			}
 		}
@@ -765,7 +1928,7 @@ func (t *TopologyGridStruct) CanBeSwitchedOn(cbEquipmentId int) (bool, error) {
 			return false, ErrSwitchIsAlreadyClosed
 		}
 	} else {
-		return false, ErrEquipmentNotFound
+		return false, equipmentNotFound(cbEquipmentId)
 	}
 
 	if edgeIdArray, exists := t.edgeIdArrayFromEquipmentId[cbEquipmentId]; exists {
@@ -779,8 +1942,8 @@ func (t *TopologyGridStruct) CanBeSwitchedOn(cbEquipmentId int) (bool, error) {
 
 			//fmt.Printf("%s %+v %+v\n", equipment.name, terminal1Node, terminal2Node)
 
-			if terminal1Node.electricalState == StateIsolated ||
-				terminal2Node.electricalState == StateIsolated {
+			if !IsEnergized(terminal1Node.electricalState) && !IsGrounded(terminal1Node.electricalState) ||
+				!IsEnergized(terminal2Node.electricalState) && !IsGrounded(terminal2Node.electricalState) {
 				return true, nil
 			} else if terminal1Node.electricalState&StateGrounded == StateGrounded &&
 				terminal2Node.electricalState&StateGrounded == StateGrounded {
@@ -798,7 +1961,7 @@ func (t *TopologyGridStruct) CanBeSwitchedOn(cbEquipmentId int) (bool, error) {
 		}
 	}
 
-	return false, ErrEquipmentNotFound
+	return false, equipmentNotFound(cbEquipmentId)
 }
 
 // CopyEquipmentSwitchStateFrom form one topogrid object to this
@@ -808,7 +1971,7 @@ func (t *TopologyGridStruct) CopyEquipmentSwitchStateFrom(source *TopologyGridSt
 		if equipment.typeId == TypeCircuitBreaker {
 			if err := t.SetSwitchStateByEquipmentId(equipment.id, equipment.switchState); err != nil {
 				source.RUnlock()
-				return fmt.Errorf("unable copy switch state for equipment %d:%s", equipment.id, equipment.name)
+				return fmt.Errorf("unable copy switch state for equipment %d:%s: %w", equipment.id, equipment.name, err)
 			}
 		}
 	}
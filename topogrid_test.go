@@ -0,0 +1,789 @@
+package topogrid
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yourbasic/graph"
+)
+
+// newTestGrid returns the topology:
+//
+//	P1(1) -CB10- 2 -DS20- L1(3) -CB30- C1(4)
+//	P2(5) -CB40- 6 -CB50(open)- C1(4)
+//
+// Edge ids are 10, 20, ..., the equipment ids of edges are 110, 120, ..., the equipment ids of nodes are 100, 103, 104 and 105
+func newTestGrid(t testing.TB) *TopologyGridStruct {
+	t.Helper()
+
+	topology := New(6)
+
+	for _, node := range []struct {
+		id, equipmentId, typeId int
+		name                    string
+	}{
+		{1, 100, TypePower, "P1"},
+		{2, 0, TypeAllEquipment, ""},
+		{3, 103, TypeLine, "L1"},
+		{4, 104, TypeConsumer, "C1"},
+		{5, 105, TypePower, "P2"},
+		{6, 0, TypeAllEquipment, ""},
+	} {
+		mustSucceed(t, topology.AddNode(node.id, node.equipmentId, node.typeId, node.name))
+	}
+
+	for _, edge := range []struct {
+		id, terminal1, terminal2, state, equipmentId, typeId int
+		name                                                 string
+	}{
+		{10, 1, 2, SwitchStateClose, 110, TypeCircuitBreaker, "CB10"},
+		{20, 2, 3, SwitchStateClose, 120, TypeDisconnectSwitch, "DS20"},
+		{30, 3, 4, SwitchStateClose, 130, TypeCircuitBreaker, "CB30"},
+		{40, 5, 6, SwitchStateClose, 140, TypeCircuitBreaker, "CB40"},
+		{50, 6, 4, SwitchStateOpen, 150, TypeCircuitBreaker, "CB50"},
+	} {
+		mustSucceed(t, topology.AddEdge(edge.id, edge.terminal1, edge.terminal2, edge.state, edge.equipmentId, edge.typeId, edge.name))
+	}
+
+	return topology
+}
+
+// newRandomGrid returns a random meshed topology with power nodes, consumers, lines and grounds connected by
+// breakers, disconnect switches, fuses, ground switches and bus bar connections in random states.
+// Node ids are 1..n, edge ids are 1001.., the equipment ids of nodes are 10000+id and of edges 20000+id
+func newRandomGrid(t testing.TB, r *rand.Rand) *TopologyGridStruct {
+	t.Helper()
+
+	numberOfNodes := 10 + r.Intn(30)
+	nodeTypes := []int{TypePower, TypeConsumer, TypeConsumer, TypeLine, TypeLine, TypeLine, TypeGround, TypeAllEquipment, TypeAllEquipment}
+	edgeTypes := []int{TypeCircuitBreaker, TypeCircuitBreaker, TypeDisconnectSwitch, TypeFuse, TypeGroundSwitch, TypeAllEquipment}
+	states := []int{SwitchStateClose, SwitchStateClose, SwitchStateOpen, SwitchStateUnknown}
+
+	topology := New(numberOfNodes)
+
+	for id := 1; id <= numberOfNodes; id++ {
+		typeId := nodeTypes[r.Intn(len(nodeTypes))]
+		if id == 1 {
+			typeId = TypePower
+		}
+
+		equipmentId := 0
+		if typeId != TypeAllEquipment {
+			equipmentId = 10000 + id
+		}
+
+		mustSucceed(t, topology.AddNode(id, equipmentId, typeId, fmt.Sprintf("N%d", id)))
+	}
+
+	addEdge := func(terminal1 int, terminal2 int) {
+		id := 1001 + len(topology.edges)
+		typeId := edgeTypes[r.Intn(len(edgeTypes))]
+		mustSucceed(t, topology.AddEdge(id, terminal1, terminal2, states[r.Intn(len(states))], 20000+id, typeId, fmt.Sprintf("E%d", id)))
+	}
+
+	for id := 2; id <= numberOfNodes; id++ {
+		addEdge(1+r.Intn(id-1), id)
+	}
+
+	for i := r.Intn(numberOfNodes / 2); i > 0; i-- {
+		addEdge(1+r.Intn(numberOfNodes), 1+r.Intn(numberOfNodes))
+	}
+
+	return topology
+}
+
+// switchEquipmentIds returns sorted equipment ids of the topology edges
+func switchEquipmentIds(topology *TopologyGridStruct) []int {
+	equipmentIds := make([]int, 0)
+
+	for _, equipmentId := range topology.EquipmentIds() {
+		if len(topology.EdgeIdsByEquipmentId(equipmentId)) != 0 {
+			equipmentIds = append(equipmentIds, equipmentId)
+		}
+	}
+
+	return equipmentIds
+}
+
+func mustSucceed(t testing.TB, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newLargeGrid returns a radial topology of the number of nodes fed by the number of power nodes with ids 1..numberOfSources.
+// Every node is connected to a random node with a smaller id, every tenth connection is a circuit breaker and the others
+// are disconnect switches, and one percent of nodes are connected by open tie breakers. Edge ids are 1..,
+// the equipment ids of nodes are 1000000+id and of edges 2000000+id
+func newLargeGrid(t testing.TB, numberOfNodes int, numberOfSources int) *TopologyGridStruct {
+	t.Helper()
+
+	r := rand.New(rand.NewSource(1))
+	topology := New(numberOfNodes)
+
+	nodes := make([]NodeDefinition, 0, numberOfNodes)
+	for id := 1; id <= numberOfNodes; id++ {
+		typeId := TypeConsumer
+		if id <= numberOfSources {
+			typeId = TypePower
+		}
+		nodes = append(nodes, NodeDefinition{Id: id, EquipmentId: 1000000 + id, EquipmentTypeId: typeId, EquipmentName: fmt.Sprintf("N%d", id)})
+	}
+	mustSucceed(t, topology.AddNodes(nodes))
+
+	edges := make([]EdgeDefinition, 0, numberOfNodes+numberOfNodes/100)
+	addEdge := func(terminal1 int, terminal2 int, state int, typeId int) {
+		id := len(edges) + 1
+		edges = append(edges, EdgeDefinition{Id: id, Terminal1: terminal1, Terminal2: terminal2, State: state,
+			EquipmentId: 2000000 + id, EquipmentTypeId: typeId, EquipmentName: fmt.Sprintf("E%d", id)})
+	}
+
+	for id := numberOfSources + 1; id <= numberOfNodes; id++ {
+		typeId := TypeDisconnectSwitch
+		if id%10 == 0 {
+			typeId = TypeCircuitBreaker
+		}
+		addEdge(1+r.Intn(id-1), id, SwitchStateClose, typeId)
+	}
+
+	for i := numberOfNodes / 100; i > 0; i-- {
+		if terminal1, terminal2 := 1+r.Intn(numberOfNodes), 1+r.Intn(numberOfNodes); terminal1 != terminal2 {
+			addEdge(terminal1, terminal2, SwitchStateOpen, TypeCircuitBreaker)
+		}
+	}
+	mustSucceed(t, topology.AddEdges(edges))
+
+	return topology
+}
+
+// newBypassGrid returns the topology where the node 4 is one circuit breaker away from the power node,
+// but can be reached without circuit breakers through the disconnect switches:
+//
+//	P1(1) -CB10- 4 -CB50- 5
+//	P1(1) -DS20- 2 -DS30- 3 -DS40- 4
+func newBypassGrid(t testing.TB) *TopologyGridStruct {
+	t.Helper()
+
+	topology := New(5)
+
+	mustSucceed(t, topology.AddNode(1, 100, TypePower, "P1"))
+	for id := 2; id <= 5; id++ {
+		mustSucceed(t, topology.AddNode(id, 100+id, TypeLine, fmt.Sprintf("L%d", id)))
+	}
+
+	mustSucceed(t, topology.AddEdge(10, 1, 4, SwitchStateClose, 110, TypeCircuitBreaker, "CB10"))
+	mustSucceed(t, topology.AddEdge(20, 1, 2, SwitchStateClose, 120, TypeDisconnectSwitch, "DS20"))
+	mustSucceed(t, topology.AddEdge(30, 2, 3, SwitchStateClose, 130, TypeDisconnectSwitch, "DS30"))
+	mustSucceed(t, topology.AddEdge(40, 3, 4, SwitchStateClose, 140, TypeDisconnectSwitch, "DS40"))
+	mustSucceed(t, topology.AddEdge(50, 4, 5, SwitchStateClose, 150, TypeCircuitBreaker, "CB50"))
+
+	return topology
+}
+
+// newRingGrid returns the ring between two power nodes with the normally open circuit breaker CB40:
+//
+//	P1(1) -CB10- 2 -LN20- C1(3) -DS30- C2(4) -CB40(open)- C3(5) -LN50- 6 -CB60- P2(7)
+//
+// Edge ids are 10, 20, ..., the equipment ids of edges are 110, 120, ..., the equipment ids of nodes are 100+id
+func newRingGrid(t testing.TB) *TopologyGridStruct {
+	t.Helper()
+
+	topology := New(7)
+
+	for _, node := range []struct {
+		id, equipmentId, typeId int
+		name                    string
+	}{
+		{1, 101, TypePower, "P1"},
+		{2, 0, TypeAllEquipment, ""},
+		{3, 103, TypeConsumer, "C1"},
+		{4, 104, TypeConsumer, "C2"},
+		{5, 105, TypeConsumer, "C3"},
+		{6, 0, TypeAllEquipment, ""},
+		{7, 107, TypePower, "P2"},
+	} {
+		mustSucceed(t, topology.AddNode(node.id, node.equipmentId, node.typeId, node.name))
+	}
+
+	for _, edge := range []struct {
+		id, terminal1, terminal2, state, equipmentId, typeId int
+		name                                                 string
+	}{
+		{10, 1, 2, SwitchStateClose, 110, TypeCircuitBreaker, "CB10"},
+		{20, 2, 3, SwitchStateClose, 120, TypeLine, "LN20"},
+		{30, 3, 4, SwitchStateClose, 130, TypeDisconnectSwitch, "DS30"},
+		{40, 4, 5, SwitchStateOpen, 140, TypeCircuitBreaker, "CB40"},
+		{50, 5, 6, SwitchStateClose, 150, TypeLine, "LN50"},
+		{60, 6, 7, SwitchStateClose, 160, TypeCircuitBreaker, "CB60"},
+	} {
+		mustSucceed(t, topology.AddEdge(edge.id, edge.terminal1, edge.terminal2, edge.state, edge.equipmentId, edge.typeId, edge.name))
+	}
+
+	return topology
+}
+
+// newRadialGrid returns the radial feeder with two consumers protected by fuses:
+//
+//	P1(1) -CB10- 2 -LN20- 3 -FU30- C1(4)
+//	                      3 -FU40- C2(5)
+//
+// Edge ids are 10, 20, ..., the equipment ids of edges are 110, 120, ..., the equipment ids of nodes are 100+id
+func newRadialGrid(t testing.TB) *TopologyGridStruct {
+	t.Helper()
+
+	topology := New(5)
+
+	mustSucceed(t, topology.AddNode(1, 101, TypePower, "P1"))
+	mustSucceed(t, topology.AddNode(2, 0, TypeAllEquipment, ""))
+	mustSucceed(t, topology.AddNode(3, 0, TypeAllEquipment, ""))
+	mustSucceed(t, topology.AddNode(4, 104, TypeConsumer, "C1"))
+	mustSucceed(t, topology.AddNode(5, 105, TypeConsumer, "C2"))
+
+	mustSucceed(t, topology.AddEdge(10, 1, 2, SwitchStateClose, 110, TypeCircuitBreaker, "CB10"))
+	mustSucceed(t, topology.AddEdge(20, 2, 3, SwitchStateClose, 120, TypeLine, "LN20"))
+	mustSucceed(t, topology.AddEdge(30, 3, 4, SwitchStateClose, 130, TypeFuse, "FU30"))
+	mustSucceed(t, topology.AddEdge(40, 3, 5, SwitchStateClose, 140, TypeFuse, "FU40"))
+
+	return topology
+}
+
+// newParallelGrid returns two parallel lines with their own circuit breakers feeding a consumer bus:
+//
+//	P1(1) -CB10- 2 -LN30- 4 -CB50- C1(5)
+//	P1(1) -CB20- 3 -LN40- 4
+//
+// Edge ids are 10, 20, ..., the equipment ids of edges are 110, 120, ..., the equipment ids of nodes are 100+id
+func newParallelGrid(t testing.TB) *TopologyGridStruct {
+	t.Helper()
+
+	topology := New(5)
+
+	mustSucceed(t, topology.AddNode(1, 101, TypePower, "P1"))
+	for id := 2; id <= 4; id++ {
+		mustSucceed(t, topology.AddNode(id, 0, TypeAllEquipment, ""))
+	}
+	mustSucceed(t, topology.AddNode(5, 105, TypeConsumer, "C1"))
+
+	mustSucceed(t, topology.AddEdge(10, 1, 2, SwitchStateClose, 110, TypeCircuitBreaker, "CB10"))
+	mustSucceed(t, topology.AddEdge(20, 1, 3, SwitchStateClose, 120, TypeCircuitBreaker, "CB20"))
+	mustSucceed(t, topology.AddEdge(30, 2, 4, SwitchStateClose, 130, TypeLine, "LN30"))
+	mustSucceed(t, topology.AddEdge(40, 3, 4, SwitchStateClose, 140, TypeLine, "LN40"))
+	mustSucceed(t, topology.AddEdge(50, 4, 5, SwitchStateClose, 150, TypeCircuitBreaker, "CB50"))
+
+	return topology
+}
+
+func TestBfsFromNodeIdFuncMinimalSwitches(t *testing.T) {
+	topology := newBypassGrid(t)
+
+	visited := make([]string, 0)
+	mustSucceed(t, topology.BfsFromNodeIdFunc(1, func(from int, to int, switches int64) bool {
+		visited = append(visited, fmt.Sprintf("%d-%d:%d", from, to, switches))
+		return true
+	}))
+
+	if want := []string{"1-2:0", "2-3:0", "3-4:0", "4-5:1"}; !slices.Equal(visited, want) {
+		t.Fatalf("visited %v, want %v", visited, want)
+	}
+
+	// The node 5 is one circuit breaker away through the disconnect switches, so it is not pruned
+	visited = visited[:0]
+	mustSucceed(t, topology.BfsFromNodeIdFunc(1, func(from int, to int, switches int64) bool {
+		visited = append(visited, fmt.Sprintf("%d-%d:%d", from, to, switches))
+		return true
+	}, 1))
+
+	if want := []string{"1-2:0", "2-3:0", "3-4:0", "4-5:1"}; !slices.Equal(visited, want) {
+		t.Fatalf("visited with at most 1 switch %v, want %v", visited, want)
+	}
+
+	visited = visited[:0]
+	mustSucceed(t, topology.BfsFromNodeIdFunc(1, func(from int, to int, switches int64) bool {
+		visited = append(visited, fmt.Sprintf("%d-%d:%d", from, to, switches))
+		return len(visited) < 2
+	}, 0))
+
+	if want := []string{"1-2:0", "2-3:0"}; !slices.Equal(visited, want) {
+		t.Fatalf("visited until stopped %v, want %v", visited, want)
+	}
+
+	if err := topology.BfsFromNodeIdFunc(999, func(int, int, int64) bool { return true }); !errors.Is(err, ErrNodeNotFound) {
+		t.Fatalf("traversal from an unknown node returns %v", err)
+	}
+}
+
+func TestBfsFromNodeIdFuncMatchesShortestPaths(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		topology := newRandomGrid(t, r)
+		_, dist := graph.ShortestPaths(topology.currentGraph, topology.nodeIdxFromNodeId[1])
+
+		for _, maxSwitches := range []int64{0, 1, 2, math.MaxInt64} {
+			visited := map[int]int64{1: 0}
+			last := int64(0)
+
+			mustSucceed(t, topology.BfsFromNodeIdFunc(1, func(from int, to int, switches int64) bool {
+				if _, exists := visited[to]; exists {
+					t.Fatalf("seed %d: node %d is visited twice", seed, to)
+				}
+
+				if _, exists := visited[from]; !exists {
+					t.Fatalf("seed %d: node %d is visited from the node %d that was not visited", seed, to, from)
+				}
+
+				if switches < last {
+					t.Fatalf("seed %d: node %d with %d switches is visited after a node with %d switches", seed, to, switches, last)
+				}
+
+				visited[to], last = switches, switches
+
+				return true
+			}, maxSwitches))
+
+			for _, nodeId := range topology.NodeIds() {
+				want := dist[topology.nodeIdxFromNodeId[nodeId]]
+
+				if switches, exists := visited[nodeId]; exists != (want >= 0 && want <= maxSwitches) || exists && switches != want {
+					t.Fatalf("seed %d: node %d visited %v with %d switches, want %d switches with at most %d", seed, nodeId, exists, switches, want, maxSwitches)
+				}
+			}
+		}
+	}
+}
+
+func TestEquipmentByDistanceFromPower(t *testing.T) {
+	topology := newTestGrid(t)
+	topology.SetEquipmentElectricalState()
+
+	for _, test := range []struct {
+		powerNodeId int
+		distances   string
+	}{
+		// The open CB50 has a live terminal, so it is listed, but P2 behind it is not
+		{1, "[{100 0} {103 1} {110 1} {120 1} {104 2} {130 2} {150 2}]"},
+		{5, "[{105 0} {140 1} {150 1}]"},
+	} {
+		distances, err := topology.EquipmentByDistanceFromPower(test.powerNodeId)
+		mustSucceed(t, err)
+
+		if fmt.Sprint(distances) != test.distances {
+			t.Fatalf("equipment by distance from %d %v, want %s", test.powerNodeId, distances, test.distances)
+		}
+	}
+
+	// C1 behind the open CB30 is not powered from P1 any more
+	_, err := topology.UpdateElectricalStateAfterSwitch(130, SwitchStateOpen)
+	mustSucceed(t, err)
+
+	distances, err := topology.EquipmentByDistanceFromPower(1)
+	mustSucceed(t, err)
+
+	if want := "[{100 0} {103 1} {110 1} {120 1} {130 1}]"; fmt.Sprint(distances) != want {
+		t.Fatalf("equipment by distance from P1 with CB30 open %v, want %s", distances, want)
+	}
+
+	if _, err := topology.EquipmentByDistanceFromPower(999); !errors.Is(err, ErrNodeNotFound) {
+		t.Fatalf("unknown power node returns %v", err)
+	}
+
+	distances, err = topology.EquipmentByDistanceFromPower(4)
+	mustSucceed(t, err)
+
+	if len(distances) != 0 {
+		t.Fatalf("equipment by distance from the consumer node %v, want none", distances)
+	}
+}
+
+func TestAddDuplicateIds(t *testing.T) {
+	topology := newTestGrid(t)
+	before := topologySnapshot(topology)
+
+	if err := topology.AddNode(3, 999, TypeConsumer, "Duplicate"); !errors.Is(err, ErrDuplicateNodeId) {
+		t.Fatalf("adding the node 3 again returns %v", err)
+	}
+
+	if err := topology.AddEdge(20, 1, 4, SwitchStateOpen, 998, TypeCircuitBreaker, "Duplicate"); !errors.Is(err, ErrDuplicateEdgeId) {
+		t.Fatalf("adding the edge 20 again returns %v", err)
+	}
+
+	var idError *IdError
+	if err := topology.AddNode(5, 105, TypePower, "P2"); !errors.As(err, &idError) || idError.Id != 5 {
+		t.Fatalf("adding the node 5 again returns %v, want IdError with the id 5", err)
+	}
+
+	if after := topologySnapshot(topology); after != before {
+		t.Fatalf("adding duplicates changes the topology\nbefore:\n%s\nafter:\n%s", before, after)
+	}
+
+	if name := topology.EquipmentNameByEquipmentId(103); name != "L1" {
+		t.Fatalf("equipment 103 is renamed to %q", name)
+	}
+}
+
+func TestNodesAndConsumersPoweredByWithClosedTie(t *testing.T) {
+	topology := newTestGrid(t)
+
+	// A second consumer next to P2, so the sources feed different consumers while the tie CB50 is open
+	mustSucceed(t, topology.AddNode(7, 107, TypeConsumer, "C2"))
+	mustSucceed(t, topology.AddEdge(60, 6, 7, SwitchStateClose, 160, TypeDisconnectSwitch, "DS60"))
+
+	for _, test := range []struct {
+		tieState               int
+		nodes1, nodes5         []int
+		consumers1, consumers5 []int
+	}{
+		{SwitchStateOpen, []int{1, 2, 3, 4}, []int{5, 6, 7}, []int{104}, []int{107}},
+		{SwitchStateClose, []int{1, 2, 3, 4, 5, 6, 7}, []int{1, 2, 3, 4, 5, 6, 7}, []int{104, 107}, []int{104, 107}},
+	} {
+		mustSucceed(t, topology.SetSwitchState(150, test.tieState))
+
+		for _, source := range []struct {
+			powerNodeId int
+			nodes       []int
+			consumers   []int
+		}{
+			{1, test.nodes1, test.consumers1},
+			{5, test.nodes5, test.consumers5},
+		} {
+			nodeIds, err := topology.NodesPoweredBy(source.powerNodeId)
+			mustSucceed(t, err)
+
+			if !slices.Equal(nodeIds, source.nodes) {
+				t.Fatalf("tie state %d: nodes powered by %d %v, want %v", test.tieState, source.powerNodeId, nodeIds, source.nodes)
+			}
+
+			consumers, err := topology.ConsumersPoweredBy(source.powerNodeId)
+			mustSucceed(t, err)
+
+			if !slices.Equal(consumers, source.consumers) {
+				t.Fatalf("tie state %d: consumers powered by %d %v, want %v", test.tieState, source.powerNodeId, consumers, source.consumers)
+			}
+
+			// The reverse query agrees with NodeIsPoweredBy
+			for _, nodeId := range topology.NodeIds() {
+				poweredBy, err := topology.NodeIsPoweredBy(nodeId)
+				mustSucceed(t, err)
+
+				if slices.Contains(poweredBy, source.powerNodeId) != slices.Contains(nodeIds, nodeId) {
+					t.Fatalf("tie state %d: node %d is powered by %v, but nodes powered by %d are %v",
+						test.tieState, nodeId, poweredBy, source.powerNodeId, nodeIds)
+				}
+			}
+		}
+	}
+
+	if _, err := topology.NodesPoweredBy(999); !errors.Is(err, ErrNodeNotFound) {
+		t.Fatalf("nodes powered by an unknown node returns %v", err)
+	}
+
+	if _, err := topology.ConsumersPoweredBy(999); !errors.Is(err, ErrNodeNotFound) {
+		t.Fatalf("consumers powered by an unknown node returns %v", err)
+	}
+}
+
+func TestPowerSourceQueriesDoNotDependOnInsertionOrder(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		model := newRandomGrid(t, r).Model()
+
+		// The same topology with nodes and edges added in the reverse and in a random order
+		reversed, shuffled := model, model
+		reversed.Nodes, reversed.Edges = slices.Clone(model.Nodes), slices.Clone(model.Edges)
+		slices.Reverse(reversed.Nodes)
+		slices.Reverse(reversed.Edges)
+		shuffled.Nodes, shuffled.Edges = slices.Clone(model.Nodes), slices.Clone(model.Edges)
+		r.Shuffle(len(shuffled.Nodes), func(i, j int) { shuffled.Nodes[i], shuffled.Nodes[j] = shuffled.Nodes[j], shuffled.Nodes[i] })
+		r.Shuffle(len(shuffled.Edges), func(i, j int) { shuffled.Edges[i], shuffled.Edges[j] = shuffled.Edges[j], shuffled.Edges[i] })
+
+		results := make([]string, 0, 2)
+
+		for _, m := range []TopologyModel{reversed, shuffled} {
+			topology, err := NewFromModel(m)
+			mustSucceed(t, err)
+
+			var result strings.Builder
+			check := func(name string, ids []int, err error) {
+				mustSucceed(t, err)
+
+				if !slices.IsSorted(ids) {
+					t.Fatalf("seed %d: %s %v is not sorted", seed, name, ids)
+				}
+				_, _ = fmt.Fprintf(&result, "%s %v\n", name, ids)
+			}
+
+			for _, nodeId := range topology.NodeIds() {
+				poweredBy, err := topology.NodeIsPoweredBy(nodeId)
+				check(fmt.Sprintf("NodeIsPoweredBy(%d)", nodeId), poweredBy, err)
+
+				canBePoweredBy, err := topology.NodeCanBePoweredBy(nodeId)
+				check(fmt.Sprintf("NodeCanBePoweredBy(%d)", nodeId), canBePoweredBy, err)
+
+				breakers, _, err := topology.GetCircuitBreakersEdgeIdsNextToNode(nodeId)
+				check(fmt.Sprintf("GetCircuitBreakersEdgeIdsNextToNode(%d)", nodeId), breakers, err)
+
+				currentBreakers, err := topology.CircuitBreakersNextToNodeCurrent(nodeId)
+				check(fmt.Sprintf("CircuitBreakersNextToNodeCurrent(%d)", nodeId), currentBreakers, err)
+			}
+
+			for _, typeId := range []int{TypePower, TypeConsumer, TypeCircuitBreaker} {
+				check(fmt.Sprintf("NodeIdsByType(%d)", typeId), topology.NodeIdsByType(typeId), nil)
+				check(fmt.Sprintf("EdgeIdsByType(%d)", typeId), topology.EdgeIdsByType(typeId), nil)
+			}
+
+			results = append(results, result.String())
+		}
+
+		if results[0] != results[1] {
+			t.Fatalf("seed %d: results depend on the insertion order\nreversed:\n%s\nshuffled:\n%s", seed, results[0], results[1])
+		}
+	}
+}
+
+// reachedNodeIds returns sorted ids of the start node and the nodes reached by the traversal
+func reachedNodeIds(nodeIdStart int, path []TerminalStruct) []int {
+	nodeIds := []int{nodeIdStart}
+	for _, terminal := range path {
+		nodeIds = append(nodeIds, terminal.node2Id)
+	}
+
+	slices.Sort(nodeIds)
+
+	return nodeIds
+}
+
+func TestBfsFromNodeIdFullIgnoresOpenBreaker(t *testing.T) {
+	topology := newTestGrid(t)
+
+	// CB50 between nodes 6 and 4 is open, so the current graph stops at node 6
+	if got, want := reachedNodeIds(5, topology.BfsFromNodeId(5)), []int{5, 6}; !slices.Equal(got, want) {
+		t.Fatalf("BfsFromNodeId(5) reaches %v, want %v", got, want)
+	}
+
+	if got, want := reachedNodeIds(5, topology.BfsFromNodeIdFull(5)), []int{1, 2, 3, 4, 5, 6}; !slices.Equal(got, want) {
+		t.Fatalf("BfsFromNodeIdFull(5) reaches %v, want %v", got, want)
+	}
+
+	mustSucceed(t, topology.SetSwitchState(150, SwitchStateClose))
+
+	if got, want := topology.BfsFromNodeId(5), topology.BfsFromNodeIdFull(5); !slices.Equal(got, want) {
+		t.Fatalf("with CB50 closed BfsFromNodeId(5) = %v, BfsFromNodeIdFull(5) = %v", got, want)
+	}
+}
+
+func TestEdgeIdsBetweenNodesWithSwappedTerminals(t *testing.T) {
+	topology := New(2)
+	mustSucceed(t, topology.AddNode(1, 100, TypePower, "P1"))
+	mustSucceed(t, topology.AddNode(2, 102, TypeConsumer, "C1"))
+
+	// Two parallel breakers, the second one added with the terminals in the reverse order
+	mustSucceed(t, topology.AddEdge(10, 1, 2, SwitchStateClose, 110, TypeCircuitBreaker, "CB10"))
+	mustSucceed(t, topology.AddEdge(20, 2, 1, SwitchStateClose, 120, TypeCircuitBreaker, "CB20"))
+
+	for _, terminals := range [][2]int{{1, 2}, {2, 1}} {
+		if got, want := topology.EdgeIdsBetweenNodes(terminals[0], terminals[1]), []int{10, 20}; !slices.Equal(got, want) {
+			t.Fatalf("EdgeIdsBetweenNodes(%d, %d) = %v, want %v", terminals[0], terminals[1], got, want)
+		}
+	}
+
+	// Opening one of the parallel breakers keeps the consumer powered through the other one
+	for _, equipmentId := range []int{110, 120} {
+		mustSucceed(t, topology.SetSwitchState(equipmentId, SwitchStateOpen))
+
+		if poweredBy, err := topology.NodeIsPoweredBy(2); err != nil || !slices.Equal(poweredBy, []int{1}) {
+			t.Fatalf("with %d open NodeIsPoweredBy(2) = %v, %v, want [1]", equipmentId, poweredBy, err)
+		}
+
+		mustSucceed(t, topology.SetSwitchState(equipmentId, SwitchStateClose))
+	}
+
+	mustSucceed(t, topology.SetSwitchState(110, SwitchStateOpen))
+	mustSucceed(t, topology.SetSwitchState(120, SwitchStateOpen))
+
+	if poweredBy, err := topology.NodeIsPoweredBy(2); err != nil || len(poweredBy) != 0 {
+		t.Fatalf("with both breakers open NodeIsPoweredBy(2) = %v, %v, want none", poweredBy, err)
+	}
+}
+
+func TestGetFurthestEquipmentFromPowerCountsFuses(t *testing.T) {
+	for _, test := range []struct {
+		fuseTypeId   int
+		wantId       int
+		wantSwitches int64
+	}{
+		{TypeFuse, 130, 3},
+		// Disconnect switches are not counted, so all equipment is one switch away and the lowest id wins
+		{TypeDisconnectSwitch, 110, 1},
+	} {
+		topology := New(4)
+		mustSucceed(t, topology.AddNode(1, 100, TypePower, "P1"))
+		mustSucceed(t, topology.AddNode(2, 0, TypeAllEquipment, ""))
+		mustSucceed(t, topology.AddNode(3, 0, TypeAllEquipment, ""))
+		mustSucceed(t, topology.AddNode(4, 104, TypeConsumer, "C1"))
+
+		mustSucceed(t, topology.AddEdge(10, 1, 2, SwitchStateClose, 110, TypeCircuitBreaker, "CB10"))
+		mustSucceed(t, topology.AddEdge(20, 2, 3, SwitchStateClose, 120, test.fuseTypeId, "F20"))
+		mustSucceed(t, topology.AddEdge(30, 3, 4, SwitchStateClose, 130, test.fuseTypeId, "F30"))
+
+		topology.SetEquipmentElectricalState()
+
+		equipmentId, powerNodeId, numberOfSwitches, err := topology.GetFurthestEquipmentFromPower([]int{110, 120, 130})
+		mustSucceed(t, err)
+
+		if equipmentId != test.wantId || powerNodeId != 1 || numberOfSwitches != test.wantSwitches {
+			t.Fatalf("type %d: GetFurthestEquipmentFromPower = %d, %d, %d, want %d, 1, %d",
+				test.fuseTypeId, equipmentId, powerNodeId, numberOfSwitches, test.wantId, test.wantSwitches)
+		}
+	}
+}
+
+func TestGetNearestEquipmentToPowerConsumers(t *testing.T) {
+	topology := newTestGrid(t)
+	topology.SetEquipmentElectricalState()
+
+	// The line L1 is one circuit breaker away from P1 and the consumer C1 two
+	equipmentId, powerNodeId, numberOfSwitches, err := topology.GetNearestEquipmentToPower([]int{104, 103})
+	mustSucceed(t, err)
+
+	if equipmentId != 103 || powerNodeId != 1 || numberOfSwitches != 1 {
+		t.Fatalf("GetNearestEquipmentToPower = %d, %d, %d, want 103, 1, 1", equipmentId, powerNodeId, numberOfSwitches)
+	}
+
+	// The open circuit breaker CB50 is skipped, the consumer is still a candidate
+	equipmentId, powerNodeId, numberOfSwitches, err = topology.GetNearestEquipmentToPower([]int{150, 104})
+	mustSucceed(t, err)
+
+	if equipmentId != 104 || powerNodeId != 1 || numberOfSwitches != 2 {
+		t.Fatalf("GetNearestEquipmentToPower = %d, %d, %d, want 104, 1, 2", equipmentId, powerNodeId, numberOfSwitches)
+	}
+
+	if _, _, _, err := topology.GetNearestEquipmentToPower([]int{150}); !errors.Is(err, ErrNoPoweredEquipment) {
+		t.Fatalf("GetNearestEquipmentToPower of an open switch returns %v, want ErrNoPoweredEquipment", err)
+	}
+}
+
+func TestGetFurthestEquipmentFromPowerConsumers(t *testing.T) {
+	topology := newTestGrid(t)
+	topology.SetEquipmentElectricalState()
+
+	equipmentId, powerNodeId, numberOfSwitches, err := topology.GetFurthestEquipmentFromPower([]int{103, 104, 150})
+	mustSucceed(t, err)
+
+	if equipmentId != 104 || powerNodeId != 1 || numberOfSwitches != 2 {
+		t.Fatalf("GetFurthestEquipmentFromPower = %d, %d, %d, want 104, 1, 2", equipmentId, powerNodeId, numberOfSwitches)
+	}
+
+	equipmentId, powerNodeId, numberOfSwitches, err = topology.GetFurthestEquipmentFromPowerNode([]int{103, 104}, 1)
+	mustSucceed(t, err)
+
+	if equipmentId != 104 || powerNodeId != 1 || numberOfSwitches != 2 {
+		t.Fatalf("GetFurthestEquipmentFromPowerNode = %d, %d, %d, want 104, 1, 2", equipmentId, powerNodeId, numberOfSwitches)
+	}
+}
+
+func TestGetIslandsPowered(t *testing.T) {
+	topology := newTestGrid(t)
+	mustSucceed(t, topology.SetSwitchState(120, SwitchStateOpen))
+
+	// Opening DS20 leaves L1 and C1 without a power node, CB50 is open
+	want := []Island{
+		{NodeIds: []int{1, 2}, Powered: true},
+		{NodeIds: []int{3, 4}, Powered: false},
+		{NodeIds: []int{5, 6}, Powered: true},
+	}
+
+	if islands := topology.GetIslands(); !reflect.DeepEqual(islands, want) {
+		t.Fatalf("GetIslands = %v, want %v", islands, want)
+	}
+
+	island, err := topology.GetIslandOfNode(4)
+	mustSucceed(t, err)
+
+	if !reflect.DeepEqual(island, want[1]) {
+		t.Fatalf("GetIslandOfNode(4) = %v, want %v", island, want[1])
+	}
+
+	mustSucceed(t, topology.SetSwitchState(150, SwitchStateClose))
+
+	island, err = topology.GetIslandOfNode(4)
+	mustSucceed(t, err)
+
+	if want := (Island{NodeIds: []int{3, 4, 5, 6}, Powered: true}); !reflect.DeepEqual(island, want) {
+		t.Fatalf("GetIslandOfNode(4) with closed CB50 = %v, want %v", island, want)
+	}
+
+	if _, err := topology.GetIslandOfNode(7); !errors.Is(err, ErrNodeNotFound) {
+		t.Fatalf("GetIslandOfNode of an unknown node returns %v, want ErrNodeNotFound", err)
+	}
+}
+
+func TestValidateUnusedNodeSlots(t *testing.T) {
+	unusedNodeSlots := func(topology *TopologyGridStruct) []error {
+		issues := make([]error, 0)
+		for _, err := range topology.Validate() {
+			if errors.Is(err, ErrUnusedNodeSlots) {
+				issues = append(issues, err)
+			}
+		}
+		return issues
+	}
+
+	addConsumers := func(topology *TopologyGridStruct, nodeIds ...int) {
+		for _, nodeId := range nodeIds {
+			mustSucceed(t, topology.AddNode(nodeId, 100+nodeId, TypeConsumer, fmt.Sprintf("C%d", nodeId)))
+		}
+	}
+
+	preallocated := New(4)
+	addConsumers(preallocated, 1, 2)
+
+	if issues := unusedNodeSlots(preallocated); len(issues) != 1 || issues[0].Error() != "unused preallocated node slots: 2 of 4" {
+		t.Fatalf("Validate of New(4) with 2 nodes = %v, want 2 of 4 unused node slots", issues)
+	}
+
+	// A slot freed by RemoveNode and used again does not use a preallocated slot
+	mustSucceed(t, preallocated.RemoveNode(2))
+	addConsumers(preallocated, 3)
+
+	if issues := unusedNodeSlots(preallocated); len(issues) != 1 || issues[0].Error() != "unused preallocated node slots: 2 of 4" {
+		t.Fatalf("Validate after RemoveNode and AddNode = %v, want 2 of 4 unused node slots", issues)
+	}
+
+	var encoded bytes.Buffer
+	mustSucceed(t, preallocated.Encode(&encoded))
+
+	decoded, err := Decode(&encoded)
+	mustSucceed(t, err)
+
+	if issues := unusedNodeSlots(decoded); len(issues) != 1 || issues[0].Error() != "unused preallocated node slots: 2 of 4" {
+		t.Fatalf("Validate of the decoded topology = %v, want 2 of 4 unused node slots", issues)
+	}
+
+	addConsumers(preallocated, 4, 5, 6)
+
+	if issues := unusedNodeSlots(preallocated); len(issues) != 0 {
+		t.Fatalf("Validate of a grown topology = %v, want no unused node slots", issues)
+	}
+
+	// Slots freed by RemoveNode are not reported
+	mustSucceed(t, preallocated.RemoveNode(6))
+
+	if issues := unusedNodeSlots(preallocated); len(issues) != 0 {
+		t.Fatalf("Validate after RemoveNode = %v, want no unused node slots", issues)
+	}
+
+	dynamic := NewDynamic()
+	addConsumers(dynamic, 1, 2, 3)
+
+	if issues := unusedNodeSlots(dynamic); len(issues) != 0 {
+		t.Fatalf("Validate of NewDynamic with 3 nodes = %v, want no unused node slots", issues)
+	}
+}
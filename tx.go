@@ -0,0 +1,79 @@
+package topogrid
+
+// TopologyTx records topology changes to apply them at once by Commit, see Begin
+type TopologyTx struct {
+	topology   *TopologyGridStruct
+	operations []func(t *TopologyGridStruct) error
+	done       bool
+}
+
+// Begin starts a transaction. Changes are recorded without locking the topology and applied by Commit
+func (t *TopologyGridStruct) Begin() *TopologyTx {
+	return &TopologyTx{topology: t}
+}
+
+// AddNode records adding the node like TopologyGridStruct.AddNode
+func (tx *TopologyTx) AddNode(id int, equipmentId int, equipmentTypeId int, equipmentName string) {
+	tx.operations = append(tx.operations, func(t *TopologyGridStruct) error {
+		return t.addNode(id, equipmentId, equipmentTypeId, equipmentName)
+	})
+}
+
+// AddEdge records adding the edge like TopologyGridStruct.AddEdge
+func (tx *TopologyTx) AddEdge(id int, terminal1 int, terminal2 int, state int, equipmentId int, equipmentTypeId int, equipmentName string) {
+	tx.operations = append(tx.operations, func(t *TopologyGridStruct) error {
+		return t.addEdge(id, terminal1, terminal2, state, equipmentId, equipmentTypeId, equipmentName)
+	})
+}
+
+// RemoveEdge records removing the edge like TopologyGridStruct.RemoveEdge
+func (tx *TopologyTx) RemoveEdge(edgeId int) {
+	tx.operations = append(tx.operations, func(t *TopologyGridStruct) error {
+		return t.removeEdge(edgeId)
+	})
+}
+
+// RemoveNode records removing the node like TopologyGridStruct.RemoveNode
+func (tx *TopologyTx) RemoveNode(nodeId int) {
+	tx.operations = append(tx.operations, func(t *TopologyGridStruct) error {
+		return t.removeNode(nodeId)
+	})
+}
+
+// SetSwitchState records setting the switch state like TopologyGridStruct.SetSwitchState
+func (tx *TopologyTx) SetSwitchState(equipmentId int, state int) {
+	tx.operations = append(tx.operations, func(t *TopologyGridStruct) error {
+		return t.setSwitchState(equipmentId, state)
+	})
+}
+
+// Commit applies the recorded changes in the order they were recorded under the write lock. The changes are applied
+// to a copy of the topology first, so if one of them fails, its error is returned and the topology is left exactly
+// as before. Returns ErrTxDone if the transaction was already committed or rolled back
+func (tx *TopologyTx) Commit() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.done = true
+
+	tx.topology.Lock()
+	defer tx.topology.Unlock()
+
+	staged := tx.topology.clone()
+
+	for _, operation := range tx.operations {
+		if err := operation(staged); err != nil {
+			return err
+		}
+	}
+
+	tx.topology.adopt(staged)
+
+	return nil
+}
+
+// Rollback discards the recorded changes, the topology is not changed
+func (tx *TopologyTx) Rollback() {
+	tx.done = true
+	tx.operations = nil
+}
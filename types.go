@@ -0,0 +1,138 @@
+package topogrid
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// equipmentType is a registered equipment type: the name and the graphics of nodes and edges of the type.
+// Graphics are drawn with the fill color only if it is set and the type has graphics for nodes or edges
+type equipmentType struct {
+	name            string
+	fill            string
+	gmlShape        gmlNodeShape
+	hasNodeGraphics bool
+	hasEdgeGraphics bool
+}
+
+// Graphviz node shapes closest to the GML node types
+var dotShapesFromGml = map[string]string{
+	"rectangle":      "box",
+	"roundrectangle": "box",
+	"ellipse":        "ellipse",
+	"triangle":       "triangle",
+	"diamond":        "diamond",
+	"hexagon":        "hexagon",
+	"octagon":        "octagon",
+	"parallelogram":  "parallelogram",
+	"trapezoid":      "trapezium",
+	"star5":          "star",
+	"star6":          "star",
+	"star8":          "star",
+}
+
+// Tokens of the GML graphics: quoted strings, keys, numbers and brackets
+var gmlGraphicsTokens = regexp.MustCompile(`"[^"]*"|[\[\]]|[^\s\[\]"]+`)
+
+var (
+	equipmentTypesMutex sync.RWMutex
+	equipmentTypes      = map[int]equipmentType{
+		TypeAllEquipment:     {name: "AllEquipment"},
+		TypeCircuitBreaker:   {name: "CircuitBreaker", fill: "#FF0000", hasEdgeGraphics: true},
+		TypeDisconnectSwitch: {name: "DisconnectSwitch", fill: "#00FF00", hasEdgeGraphics: true},
+		TypePower:            {name: "Power", fill: "#FF0000", gmlShape: gmlNodeShape{shapeType: "star6"}, hasNodeGraphics: true},
+		TypeConsumer:         {name: "Consumer", fill: "#FFCC00", gmlShape: gmlNodeShape{shapeType: "triangle"}, hasNodeGraphics: true},
+		TypeGround:           {name: "Ground"},
+		TypeLine:             {name: "Line", fill: "#FF8080", gmlShape: gmlNodeShape{shapeType: "rectangle", width: 40.0, height: 10.0}, hasNodeGraphics: true},
+		TypeGroundSwitch:     {name: "GroundSwitch"},
+		TypeTransformer:      {name: "Transformer"},
+		TypeFuse:             {name: "Fuse", fill: "#0000FF", hasEdgeGraphics: true},
+	}
+)
+
+// TypeName returns the name of the registered equipment type, or "Type<id>" if the type is not registered
+func TypeName(typeId int) string {
+	equipmentTypesMutex.RLock()
+	defer equipmentTypesMutex.RUnlock()
+
+	if registered, exists := equipmentTypes[typeId]; exists {
+		return registered.name
+	}
+
+	return fmt.Sprintf("Type%d", typeId)
+}
+
+// RegisterEquipmentType registers the name and the GML graphics of the equipment type. The graphics are the attributes
+// of a GML graphics section, optionally enclosed in "graphics [" and "]", e.g. `type "octagon" fill "#FF00FF" w 20.0 h 20.0`.
+// Nodes of a type with the node type "type" are drawn with this shape, the fill color and the size "w" and "h", and edges
+// of a type with the fill color are drawn with this color, dotted if the switch state is not close. The DOT output uses
+// the closest Graphviz shape and the same color. Attributes that are not given keep the registered graphics, so built-in
+// types keep their shapes if only the fill color is given. Other attributes are ignored
+func RegisterEquipmentType(typeId int, name string, graphicsGml string) {
+	equipmentTypesMutex.Lock()
+	defer equipmentTypesMutex.Unlock()
+
+	registered := equipmentTypes[typeId]
+	registered.name = name
+	registered.setGmlGraphics(graphicsGml)
+
+	equipmentTypes[typeId] = registered
+}
+
+// setGmlGraphics sets the node type, the fill color and the size given by the attributes of the GML graphics
+func (e *equipmentType) setGmlGraphics(graphicsGml string) {
+	tokens := gmlGraphicsTokens.FindAllString(graphicsGml, -1)
+
+	if len(tokens) > 0 && tokens[0] == "graphics" {
+		tokens = tokens[1:]
+	}
+
+	tokens = slices.DeleteFunc(tokens, func(token string) bool { return token == "[" || token == "]" })
+
+	for i := 0; i+1 < len(tokens); i += 2 {
+		value := strings.Trim(tokens[i+1], `"`)
+
+		switch tokens[i] {
+		case "type":
+			e.gmlShape.shapeType = value
+			e.hasNodeGraphics = true
+		case "fill":
+			e.fill = value
+			e.hasEdgeGraphics = true
+		case "w":
+			e.gmlShape.width, _ = strconv.ParseFloat(value, 64)
+		case "h":
+			e.gmlShape.height, _ = strconv.ParseFloat(value, 64)
+		}
+	}
+}
+
+// registeredEquipmentType returns the registered equipment type
+func registeredEquipmentType(typeId int) equipmentType {
+	equipmentTypesMutex.RLock()
+	defer equipmentTypesMutex.RUnlock()
+
+	return equipmentTypes[typeId]
+}
+
+// nodeGraphics returns true if nodes of the type have their own graphics
+func (e equipmentType) nodeGraphics() bool {
+	return e.hasNodeGraphics && e.fill != ""
+}
+
+// edgeGraphics returns true if edges of the type have their own graphics
+func (e equipmentType) edgeGraphics() bool {
+	return e.hasEdgeGraphics && e.fill != ""
+}
+
+// dotShape returns the Graphviz node shape closest to the GML node type of the type
+func (e equipmentType) dotShape() string {
+	if shape, exists := dotShapesFromGml[e.gmlShape.shapeType]; exists {
+		return shape
+	}
+	return "ellipse"
+}
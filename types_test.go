@@ -0,0 +1,119 @@
+package topogrid
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+// restoreEquipmentTypes restores the registered equipment types when the test finishes
+func restoreEquipmentTypes(t testing.TB) {
+	t.Helper()
+
+	equipmentTypesMutex.RLock()
+	registered := maps.Clone(equipmentTypes)
+	equipmentTypesMutex.RUnlock()
+
+	t.Cleanup(func() {
+		equipmentTypesMutex.Lock()
+		equipmentTypes = registered
+		equipmentTypesMutex.Unlock()
+	})
+}
+
+func TestRegisterEquipmentTypeTransformer(t *testing.T) {
+	restoreEquipmentTypes(t)
+
+	topology := New(3)
+	mustSucceed(t, topology.AddNode(1, 101, TypePower, "P1"))
+	mustSucceed(t, topology.AddNode(2, 102, TypeTransformer, "T1"))
+	mustSucceed(t, topology.AddNode(3, 103, TypeConsumer, "C1"))
+	mustSucceed(t, topology.AddEdge(10, 1, 2, SwitchStateClose, 110, TypeCircuitBreaker, "CB10"))
+	mustSucceed(t, topology.AddEdge(20, 2, 3, SwitchStateClose, 120, TypeTransformer, "T2"))
+
+	const transformerNode = "\n    graphics\n    [\n      type \"octagon\"\n      fill \"#FF00FF\"\n      w 20.0\n      h 20.0\n    ]\n    id 2\n"
+	const transformerEdge = "\n    graphics\n    [\n    fill \"#FF00FF\"\n    ]\n    source 2\n"
+
+	if graphMl := topology.GetAsGraphMl(); strings.Contains(graphMl, "#FF00FF") {
+		t.Fatalf("transformer has graphics before it is registered:\n%s", graphMl)
+	}
+
+	RegisterEquipmentType(TypeTransformer, "PowerTransformer", `type "octagon" fill "#FF00FF" w 20.0 h 20.0`)
+
+	if name := TypeName(TypeTransformer); name != "PowerTransformer" {
+		t.Fatalf("TypeName(TypeTransformer) = %q", name)
+	}
+
+	graphMl := topology.GetAsGraphMl()
+	if !strings.Contains(graphMl, transformerNode) || !strings.Contains(graphMl, transformerEdge) {
+		t.Fatalf("registered transformer is not drawn with the fill color:\n%s", graphMl)
+	}
+
+	dot := topology.GetAsDot()
+	if !strings.Contains(dot, "2 [label=\"T1\" shape=octagon style=filled fillcolor=\"#FF00FF\"];") ||
+		!strings.Contains(dot, "2 -- 3 [label=\"T2\" color=\"#FF00FF\"];") {
+		t.Fatalf("registered transformer is not drawn with the fill color:\n%s", dot)
+	}
+
+	// Registering the type again replaces the color and keeps the shape
+	RegisterEquipmentType(TypeTransformer, "PowerTransformer", `fill "#00FFFF"`)
+
+	if graphMl := topology.GetAsGraphMl(); !strings.Contains(graphMl, strings.ReplaceAll(transformerNode, "#FF00FF", "#00FFFF")) {
+		t.Fatalf("re-registered transformer is not drawn with the new fill color:\n%s", graphMl)
+	}
+}
+
+func TestRegisterEquipmentTypeKeepsBuiltInShapes(t *testing.T) {
+	restoreEquipmentTypes(t)
+
+	topology := newTestGrid(t)
+
+	RegisterEquipmentType(TypeConsumer, "Load", `fill "#0080FF"`)
+
+	if graphMl := topology.GetAsGraphMl(); !strings.Contains(graphMl, "type \"triangle\"\n      fill \"#0080FF\"") {
+		t.Fatalf("consumer is not drawn as a triangle with the new fill color:\n%s", graphMl)
+	}
+
+	RegisterEquipmentType(TypeConsumer, "Consumer", "")
+
+	if graphMl := topology.GetAsGraphMl(); !strings.Contains(graphMl, "fill \"#0080FF\"") {
+		t.Fatalf("empty graphics replace the registered ones:\n%s", graphMl)
+	}
+}
+
+func TestRegisterEquipmentTypeGraphics(t *testing.T) {
+	restoreEquipmentTypes(t)
+
+	const typeReactor = 100
+
+	topology := New(3)
+	mustSucceed(t, topology.AddNode(1, 101, TypePower, "P1"))
+	mustSucceed(t, topology.AddNode(2, 102, typeReactor, "R1"))
+	mustSucceed(t, topology.AddNode(3, 0, TypeAllEquipment, ""))
+	mustSucceed(t, topology.AddEdge(10, 1, 2, SwitchStateClose, 110, TypeCircuitBreaker, "CB10"))
+	mustSucceed(t, topology.AddEdge(20, 2, 3, SwitchStateOpen, 120, typeReactor, "R2"))
+
+	RegisterEquipmentType(typeReactor, "Reactor", "graphics [ type \"hexagon\" fill \"#8000FF\" outline \"#000000\" ]")
+
+	if name := TypeName(typeReactor); name != "Reactor" {
+		t.Fatalf("TypeName(%d) = %q", typeReactor, name)
+	}
+
+	const reactorNode = "\n    graphics\n    [\n      type \"hexagon\"\n      fill \"#8000FF\"\n    ]\n    id 2\n"
+	const reactorEdge = "\n    graphics\n    [\n    style \"dotted\"\n      fill \"#8000FF\"\n    ]\n    source 2\n"
+
+	graphMl := topology.GetAsGraphMl()
+	if !strings.Contains(graphMl, reactorNode) || !strings.Contains(graphMl, reactorEdge) {
+		t.Fatalf("registered reactor is not drawn with its graphics:\n%s", graphMl)
+	}
+
+	if styled := topology.GetAsGraphMlStyled(DefaultGraphMlStyle()); styled != graphMl {
+		t.Fatalf("GetAsGraphMlStyled with the default style differs from GetAsGraphMl:\n%s", styled)
+	}
+
+	dot := topology.GetAsDot()
+	if !strings.Contains(dot, "2 [label=\"R1\" shape=hexagon style=filled fillcolor=\"#8000FF\"];") ||
+		!strings.Contains(dot, "2 -- 3 [label=\"R2\" style=dashed color=\"#8000FF\"];") {
+		t.Fatalf("registered reactor is not drawn with its graphics:\n%s", dot)
+	}
+}
@@ -0,0 +1,55 @@
+package topogrid
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Validate checks the consistency of the topology and returns every issue found:
+// edges referencing node ids that were never added, nodes without equipment and edges,
+// equipment ids shared by a node and an edge, switch edges with invalid states and node slots preallocated by New
+// and never used. Spare slots of a topology grown by AddNode and slots freed by RemoveNode are not reported.
+// Returns an empty array if the topology is consistent
+func (t *TopologyGridStruct) Validate() []error {
+	t.RLock()
+	defer t.RUnlock()
+
+	issues := make([]error, 0)
+
+	edges := make([]EdgeStruct, len(t.edges))
+	copy(edges, t.edges)
+	sort.Slice(edges, func(i, j int) bool { return edges[i].id < edges[j].id })
+
+	for _, edge := range edges {
+		for _, nodeId := range []int{edge.terminal.node1Id, edge.terminal.node2Id} {
+			if _, exists := t.nodeIdxFromNodeId[nodeId]; !exists {
+				issues = append(issues, fmt.Errorf("%w: %d referenced by edge id %d", ErrNodeNotFound, nodeId, edge.id))
+			}
+		}
+
+		if typeId, state := t.edgeState(edge); isSwitchType(typeId) && !isValidSwitchState(state) {
+			issues = append(issues, fmt.Errorf("%w: %d for edge id %d", ErrInvalidSwitchState, state, edge.id))
+		}
+	}
+
+	nodes := make([]NodeStruct, t.nodeIdx)
+	copy(nodes, t.nodes[:t.nodeIdx])
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].id < nodes[j].id })
+
+	for _, node := range nodes {
+		if node.equipmentId == 0 && len(t.edgeIdArrayFromNodeId[node.id]) == 0 {
+			issues = append(issues, &IdError{Err: ErrNodeHasNoEdges, Id: node.id})
+		}
+
+		if node.equipmentId != 0 && len(t.edgeIdArrayFromEquipmentId[node.equipmentId]) != 0 {
+			issues = append(issues, fmt.Errorf("%w: %d of node id %d and edge id %d",
+				ErrDuplicateEquipmentId, node.equipmentId, node.id, t.edgeIdArrayFromEquipmentId[node.equipmentId][0]))
+		}
+	}
+
+	if t.unusedNodeSlots > 0 {
+		issues = append(issues, fmt.Errorf("%w: %d of %d", ErrUnusedNodeSlots, t.unusedNodeSlots, len(t.nodes)))
+	}
+
+	return issues
+}
@@ -0,0 +1,88 @@
+package topogrid
+
+import (
+	"sort"
+
+	"github.com/yourbasic/graph"
+)
+
+// SetNodeVoltageLevel sets the voltage level of the node in kV. Nodes on both sides of a transformer
+// (TypeTransformer edge) have different voltage levels
+func (t *TopologyGridStruct) SetNodeVoltageLevel(nodeId int, kv float64) error {
+	t.Lock()
+	defer t.Unlock()
+
+	if _, exists := t.nodeIdxFromNodeId[nodeId]; !exists {
+		return nodeNotFound(nodeId)
+	}
+
+	t.voltageLevelFromNodeId[nodeId] = kv
+	t.recordChange(ChangeNodeData, nodeId)
+
+	return nil
+}
+
+// NodeVoltageLevel returns the voltage level of the node in kV and false if it is not set
+func (t *TopologyGridStruct) NodeVoltageLevel(nodeId int) (float64, bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	kv, exists := t.voltageLevelFromNodeId[nodeId]
+
+	return kv, exists
+}
+
+// NodesAtVoltageLevel returns sorted ids of nodes with the voltage level
+func (t *TopologyGridStruct) NodesAtVoltageLevel(kv float64) []int {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIds := make([]int, 0)
+	for nodeId, nodeKv := range t.voltageLevelFromNodeId {
+		if nodeKv == kv {
+			nodeIds = append(nodeIds, nodeId)
+		}
+	}
+
+	sort.Ints(nodeIds)
+
+	return nodeIds
+}
+
+// IslandsAtVoltageLevel returns groups of node ids with the voltage level galvanically connected
+// in the current topology graph by edges between nodes of the same voltage level, so the networks
+// on both sides of transformers are analyzed separately. Islands are sorted like GetIslands
+func (t *TopologyGridStruct) IslandsAtVoltageLevel(kv float64) [][]int {
+	t.RLock()
+	defer t.RUnlock()
+
+	atLevel := make([]bool, t.nodeIdx)
+	for nodeIdx, node := range t.nodes[:t.nodeIdx] {
+		if nodeKv, exists := t.voltageLevelFromNodeId[node.id]; exists && nodeKv == kv {
+			atLevel[nodeIdx] = true
+		}
+	}
+
+	levelGraph := graph.New(t.nodeIdx)
+	for v := 0; v < t.nodeIdx; v++ {
+		if !atLevel[v] {
+			continue
+		}
+
+		t.currentGraph.Visit(v, func(w int, c int64) bool {
+			if w < t.nodeIdx && atLevel[w] {
+				levelGraph.AddCost(v, w, c)
+			}
+			return false
+		})
+	}
+
+	islands := make([][]int, 0)
+	for _, island := range t.islands(levelGraph) {
+		if atLevel[t.nodeIdxFromNodeId[island[0]]] {
+			islands = append(islands, island)
+		}
+	}
+
+	return islands
+}
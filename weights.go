@@ -0,0 +1,105 @@
+package topogrid
+
+import (
+	"fmt"
+
+	"github.com/yourbasic/graph"
+)
+
+// Metric selects the cost of edges in path queries
+type Metric int
+
+const (
+	MetricSwitches Metric = iota // Number of circuit breakers
+	MetricWeight                 // Sum of edge weights set by SetEdgeWeight, e.g. the line length
+)
+
+// SetEdgeWeight sets the cost of the edge in the weighted topology graph used by MetricWeight queries.
+// Edges are added with zero weight. Returns ErrNegativeWeight if the weight is negative
+func (t *TopologyGridStruct) SetEdgeWeight(edgeId int, weight int64) error {
+	t.Lock()
+	defer t.Unlock()
+
+	edgeIdx, exists := t.edgeIdxFromEdgeId[edgeId]
+	if !exists {
+		return edgeNotFound(edgeId)
+	}
+
+	if weight < 0 {
+		return fmt.Errorf("%w: %d for edge id %d", ErrNegativeWeight, weight, edgeId)
+	}
+
+	t.edges[edgeIdx].weight = weight
+	t.updateArcs(t.edges[edgeIdx].terminal.node1Id, t.edges[edgeIdx].terminal.node2Id)
+	t.recordChange(ChangeEdgeData, edgeId)
+
+	return nil
+}
+
+// EdgeWeight returns the cost of the edge in the weighted topology graph
+func (t *TopologyGridStruct) EdgeWeight(edgeId int) (int64, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	edgeIdx, exists := t.edgeIdxFromEdgeId[edgeId]
+	if !exists {
+		return 0, edgeNotFound(edgeId)
+	}
+
+	return t.edges[edgeIdx].weight, nil
+}
+
+// Distance returns the cost of the cheapest path between two nodes in the current topology graph by the metric.
+// Returns PathError with ErrNoPath if the nodes are not connected
+func (t *TopologyGridStruct) Distance(nodeId1 int, nodeId2 int, metric Metric) (int64, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	node1Idx, exists := t.nodeIdxFromNodeId[nodeId1]
+	if !exists {
+		return 0, nodeNotFound(nodeId1)
+	}
+
+	node2Idx, exists := t.nodeIdxFromNodeId[nodeId2]
+	if !exists {
+		return 0, nodeNotFound(nodeId2)
+	}
+
+	path, cost := graph.ShortestPath(t.metricGraph(metric), node1Idx, node2Idx)
+	if len(path) == 0 {
+		return 0, &PathError{Err: ErrNoPath, FromNodeId: nodeId1, ToNodeId: nodeId2}
+	}
+
+	return cost, nil
+}
+
+// DistancesFromNode returns costs of the cheapest paths by the metric from the node to every node reachable
+// in the current topology graph, keyed by the node id
+func (t *TopologyGridStruct) DistancesFromNode(nodeId int, metric Metric) (map[int]int64, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
+	if !exists {
+		return nil, nodeNotFound(nodeId)
+	}
+
+	parents, costs := graph.ShortestPaths(t.metricGraph(metric), nodeIdx)
+
+	distances := make(map[int]int64)
+	for idx := 0; idx < t.nodeIdx; idx++ {
+		if idx == nodeIdx || parents[idx] != -1 {
+			distances[t.nodes[idx].id] = costs[idx]
+		}
+	}
+
+	return distances, nil
+}
+
+// metricGraph returns the current topology graph with edge costs by the metric
+func (t *TopologyGridStruct) metricGraph(metric Metric) *graph.Mutable {
+	if metric == MetricWeight {
+		return t.weightedGraph
+	}
+	return t.currentGraph
+}
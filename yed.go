@@ -0,0 +1,266 @@
+package topogrid
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const yedNamespace = "http://www.yworks.com/xml/graphml"
+
+// ColorBy selects the colors of exported nodes and edges
+type ColorBy int
+
+const (
+	ColorByType   ColorBy = iota // Colors and shapes of equipment types, see RegisterEquipmentType
+	ColorByState                 // Electrical state: power sources are red, energized equipment is green, isolated is grey
+	ColorByFeeder                // Feeders found by ComputeFeeders, multi-fed equipment is grey
+)
+
+// LabelContent selects what labels of exported nodes and edges contain, flags can be combined
+type LabelContent int
+
+const (
+	LabelName        LabelContent = 1 << iota // Equipment name, node names are annotated with the voltage level
+	LabelId                                   // Node id or edge id
+	LabelEquipmentId                          // Equipment id
+)
+
+// graphExportOptions are options of graph exports set by GraphExportOption
+type graphExportOptions struct {
+	groupKey string
+	colorBy  ColorBy
+	labels   LabelContent
+}
+
+// GraphExportOption sets an option of graph exports
+type GraphExportOption func(options *graphExportOptions)
+
+// WithGroupByAttribute groups nodes by the value of the equipment attribute, e.g. a substation code.
+// Nodes without the attribute are not grouped
+func WithGroupByAttribute(key string) GraphExportOption {
+	return func(options *graphExportOptions) {
+		options.groupKey = key
+	}
+}
+
+// WithColorBy sets the colors of nodes and edges, ColorByType by default
+func WithColorBy(colorBy ColorBy) GraphExportOption {
+	return func(options *graphExportOptions) {
+		options.colorBy = colorBy
+	}
+}
+
+// WithLabels sets the content of labels, LabelName by default
+func WithLabels(labels LabelContent) GraphExportOption {
+	return func(options *graphExportOptions) {
+		options.labels = labels
+	}
+}
+
+func newGraphExportOptions(options []GraphExportOption) graphExportOptions {
+	exportOptions := graphExportOptions{colorBy: ColorByType, labels: LabelName}
+
+	for _, option := range options {
+		option(&exportOptions)
+	}
+
+	return exportOptions
+}
+
+// GetAsYedGraphML returns a string with a graph represented by the GraphML XML format with the yEd extensions:
+// nodes are y:ShapeNode with shapes, colors and positions from the node coordinates, edges are y:PolyLineEdge,
+// dotted if the switch state is not close. Nodes can be grouped into yEd group nodes by an equipment attribute
+func (t *TopologyGridStruct) GetAsYedGraphML(options ...GraphExportOption) string {
+	t.RLock()
+	defer t.RUnlock()
+
+	var yed strings.Builder
+
+	t.writeYedGraphML(&yed, newGraphExportOptions(options))
+
+	return yed.String()
+}
+
+// WriteYedGraphML writes the graph returned by GetAsYedGraphML into w
+func (t *TopologyGridStruct) WriteYedGraphML(w io.Writer, options ...GraphExportOption) error {
+	t.RLock()
+	defer t.RUnlock()
+
+	return writeBuffered(w, func(writer exportWriter) error {
+		t.writeYedGraphML(writer, newGraphExportOptions(options))
+		return nil
+	})
+}
+
+func (t *TopologyGridStruct) writeYedGraphML(yed exportWriter, exportOptions graphExportOptions) {
+	var feeders *feederCache
+	if exportOptions.colorBy == ColorByFeeder {
+		feeders = t.feederCache()
+	}
+
+	groups := make(map[string][]NodeStruct)
+	ungrouped := make([]NodeStruct, 0, t.nodeIdx)
+
+	for _, node := range t.nodes[:t.nodeIdx] {
+		if group, exists := t.attributesFromEquipmentId[node.equipmentId][exportOptions.groupKey]; exists && exportOptions.groupKey != "" {
+			groups[group] = append(groups[group], node)
+		} else {
+			ungrouped = append(ungrouped, node)
+		}
+	}
+
+	_, _ = yed.WriteString(xml.Header)
+	_, _ = fmt.Fprintf(yed, "<graphml xmlns=\"%s\" xmlns:y=\"%s\">\n", graphMlNamespace, yedNamespace)
+	_, _ = yed.WriteString("  <key id=\"d0\" for=\"node\" yfiles.type=\"nodegraphics\"></key>\n")
+	_, _ = yed.WriteString("  <key id=\"d1\" for=\"edge\" yfiles.type=\"edgegraphics\"></key>\n")
+	_, _ = yed.WriteString("  <graph id=\"G\" edgedefault=\"undirected\">\n")
+
+	for _, node := range ungrouped {
+		t.writeYedNode(yed, node, exportOptions, feeders, "    ")
+	}
+
+	groupNames := make([]string, 0, len(groups))
+	for group := range groups {
+		groupNames = append(groupNames, group)
+	}
+	sort.Strings(groupNames)
+
+	for i, group := range groupNames {
+		_, _ = fmt.Fprintf(yed, "    <node id=\"g%d\" yfiles.foldertype=\"group\">\n", i)
+		_, _ = yed.WriteString("      <data key=\"d0\"><y:ProxyAutoBoundsNode><y:Realizers active=\"0\"><y:GroupNode>")
+		_, _ = fmt.Fprintf(yed, "<y:Fill color=\"#F5F5F5\" transparent=\"false\"/><y:BorderStyle color=\"#000000\" type=\"dashed\" width=\"1.0\"/>"+
+			"<y:NodeLabel modelName=\"internal\" modelPosition=\"t\">%s</y:NodeLabel><y:Shape type=\"roundrectangle\"/><y:State closed=\"false\"/>", xmlEscape(group))
+		_, _ = yed.WriteString("</y:GroupNode></y:Realizers></y:ProxyAutoBoundsNode></data>\n")
+		_, _ = fmt.Fprintf(yed, "      <graph id=\"g%d:\" edgedefault=\"undirected\">\n", i)
+
+		for _, node := range groups[group] {
+			t.writeYedNode(yed, node, exportOptions, feeders, "        ")
+		}
+
+		_, _ = yed.WriteString("      </graph>\n    </node>\n")
+	}
+
+	for _, edge := range t.edges {
+		lineType := "line"
+		if _, state := t.edgeState(edge); state != SwitchStateClose {
+			lineType = "dotted"
+		}
+
+		_, _ = fmt.Fprintf(yed, "    <edge id=\"e%d\" source=\"%s\" target=\"%s\">\n", edge.id, graphMlNodeId(edge.terminal.node1Id), graphMlNodeId(edge.terminal.node2Id))
+		_, _ = fmt.Fprintf(yed, "      <data key=\"d1\"><y:PolyLineEdge><y:LineStyle color=\"%s\" type=\"%s\" width=\"2.0\"/>"+
+			"<y:Arrows source=\"none\" target=\"none\"/><y:EdgeLabel>%s</y:EdgeLabel></y:PolyLineEdge></data>\n",
+			t.exportEdgeFill(edge, exportOptions.colorBy, feeders), lineType, xmlEscape(t.exportEdgeLabel(edge, exportOptions.labels)))
+		_, _ = yed.WriteString("    </edge>\n")
+	}
+
+	_, _ = yed.WriteString("  </graph>\n</graphml>\n")
+}
+
+// writeYedNode writes the node as y:ShapeNode with the indent
+func (t *TopologyGridStruct) writeYedNode(yed exportWriter, node NodeStruct, options graphExportOptions, feeders *feederCache, indent string) {
+	shape, fill := t.exportNodeShape(node, options.colorBy, feeders)
+
+	width, height := shape.width, shape.height
+	if width == 0 && height == 0 {
+		width, height = 30.0, 30.0
+	}
+
+	geometry := fmt.Sprintf("<y:Geometry width=\"%.1f\" height=\"%.1f\"/>", width, height)
+	if coordinates, exists := t.coordinatesFromNodeId[node.id]; exists {
+		geometry = fmt.Sprintf("<y:Geometry x=\"%f\" y=\"%f\" width=\"%.1f\" height=\"%.1f\"/>", coordinates.x, coordinates.y, width, height)
+	}
+
+	_, _ = fmt.Fprintf(yed, "%s<node id=\"%s\">\n", indent, graphMlNodeId(node.id))
+	_, _ = fmt.Fprintf(yed, "%s  <data key=\"d0\"><y:ShapeNode>%s<y:Fill color=\"%s\" transparent=\"false\"/>"+
+		"<y:BorderStyle color=\"#000000\" type=\"line\" width=\"1.0\"/><y:NodeLabel>%s</y:NodeLabel><y:Shape type=\"%s\"/></y:ShapeNode></data>\n",
+		indent, geometry, fill, xmlEscape(t.exportNodeLabel(node, options.labels)), shape.shapeType)
+	_, _ = fmt.Fprintf(yed, "%s</node>\n", indent)
+}
+
+// exportNodeShape returns the node shape of the equipment type and the fill color selected by colorBy
+func (t *TopologyGridStruct) exportNodeShape(node NodeStruct, colorBy ColorBy, feeders *feederCache) (gmlNodeShape, string) {
+	shape, fill := t.gmlNodeShapeByType(node)
+
+	if t.equipment[node.equipmentId].typeId == TypePower {
+		return shape, fill
+	}
+
+	switch colorBy {
+	case ColorByState:
+		fill = gmlFillByElectricalState(node.electricalState)
+	case ColorByFeeder:
+		fill = feeders.fill(feeders.feedersFromNodeIdx[t.nodeIdxFromNodeId[node.id]])
+	}
+
+	return shape, fill
+}
+
+// exportEdgeFill returns the edge color selected by colorBy, edges without graphics of their type are black
+func (t *TopologyGridStruct) exportEdgeFill(edge EdgeStruct, colorBy ColorBy, feeders *feederCache) string {
+	switch colorBy {
+	case ColorByState:
+		return gmlFillByElectricalState(t.equipment[edge.equipmentId].electricalState)
+	case ColorByFeeder:
+		return feeders.fill(feeders.feedersFromEquipmentId[edge.equipmentId])
+	}
+
+	typeId, _ := t.edgeState(edge)
+	if registered := registeredEquipmentType(typeId); registered.edgeGraphics() {
+		return registered.fill
+	}
+
+	return gmlFillStateOff
+}
+
+// exportNodeLabel returns the node label with the content selected by labels, e.g. "TP-12 0.4 kV (node 3, equipment 103)"
+func (t *TopologyGridStruct) exportNodeLabel(node NodeStruct, labels LabelContent) string {
+	var name string
+	if labels&LabelName != 0 {
+		name = t.gmlNodeLabel(node)
+	}
+
+	return exportLabel(name, "node", node.id, node.equipmentId, labels)
+}
+
+// exportEdgeLabel returns the edge label with the content selected by labels, e.g. "CB-10 (edge 10, equipment 110)"
+func (t *TopologyGridStruct) exportEdgeLabel(edge EdgeStruct, labels LabelContent) string {
+	var name string
+	if labels&LabelName != 0 {
+		name = t.equipment[edge.equipmentId].name
+	}
+
+	return exportLabel(name, "edge", edge.id, edge.equipmentId, labels)
+}
+
+func exportLabel(name string, kind string, id int, equipmentId int, labels LabelContent) string {
+	ids := make([]string, 0, 2)
+
+	if labels&LabelId != 0 {
+		ids = append(ids, fmt.Sprintf("%s %d", kind, id))
+	}
+
+	if labels&LabelEquipmentId != 0 && equipmentId != 0 {
+		ids = append(ids, fmt.Sprintf("equipment %d", equipmentId))
+	}
+
+	if len(ids) == 0 {
+		return name
+	}
+
+	if name == "" {
+		return strings.Join(ids, ", ")
+	}
+
+	return name + " (" + strings.Join(ids, ", ") + ")"
+}
+
+// xmlEscape returns the text with XML special characters escaped
+func xmlEscape(s string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(s))
+
+	return escaped.String()
+}
@@ -0,0 +1,131 @@
+package topogrid
+
+import (
+	"sort"
+)
+
+// zoneCache is a partition of the full topology graph into zones connected by zero cost arcs, i.e. bounded by
+// circuit breakers and fuses, and the edges of every equipment type touching every zone
+type zoneCache struct {
+	version                    uint64
+	zoneFromNodeIdx            []int                 // NodeIdx -> Zone
+	edgeIdArrayFromTypeAndZone map[int]map[int][]int // EquipmentTypeId -> Zone -> []EdgeId
+}
+
+// zoneCache returns zones of the full topology graph, building them if the graph has changed since the last call.
+// Must be called with at least the read lock held
+func (t *TopologyGridStruct) zoneCache() *zoneCache {
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+
+	if t.zones == nil || t.zones.version != t.graphVersion {
+		t.zones = t.buildZones()
+	}
+
+	return t.zones
+}
+
+// buildZones joins nodes connected by zero cost arcs of the full topology graph with union-find
+func (t *TopologyGridStruct) buildZones() *zoneCache {
+	parent := make([]int, t.nodeIdx)
+	for v := range parent {
+		parent[v] = v
+	}
+
+	find := func(v int) int {
+		for parent[v] != v {
+			parent[v] = parent[parent[v]]
+			v = parent[v]
+		}
+		return v
+	}
+
+	for v := 0; v < t.nodeIdx; v++ {
+		t.fullGraph.Visit(v, func(w int, c int64) bool {
+			if c == 0 && w < t.nodeIdx {
+				parent[find(v)] = find(w)
+			}
+			return false
+		})
+	}
+
+	zones := &zoneCache{
+		version:                    t.graphVersion,
+		zoneFromNodeIdx:            make([]int, t.nodeIdx),
+		edgeIdArrayFromTypeAndZone: make(map[int]map[int][]int),
+	}
+
+	for v := range zones.zoneFromNodeIdx {
+		zones.zoneFromNodeIdx[v] = find(v)
+	}
+
+	for typeId, edgeIdArray := range t.edgeIdArrayFromEquipmentTypeId {
+		edgeIdArrayFromZone := make(map[int][]int)
+
+		for _, edgeId := range edgeIdArray {
+			edgeIdx, exists := t.edgeIdxFromEdgeId[edgeId]
+			if !exists {
+				continue
+			}
+
+			edge := t.edges[edgeIdx]
+			node1Idx, existsNode1 := t.nodeIdxFromNodeId[edge.terminal.node1Id]
+			node2Idx, existsNode2 := t.nodeIdxFromNodeId[edge.terminal.node2Id]
+
+			if existsNode1 {
+				zone := zones.zoneFromNodeIdx[node1Idx]
+				edgeIdArrayFromZone[zone] = append(edgeIdArrayFromZone[zone], edgeId)
+			}
+
+			if existsNode2 && (!existsNode1 || zones.zoneFromNodeIdx[node2Idx] != zones.zoneFromNodeIdx[node1Idx]) {
+				zone := zones.zoneFromNodeIdx[node2Idx]
+				edgeIdArrayFromZone[zone] = append(edgeIdArrayFromZone[zone], edgeId)
+			}
+		}
+
+		zones.edgeIdArrayFromTypeAndZone[typeId] = edgeIdArrayFromZone
+	}
+
+	return zones
+}
+
+// DisconnectSwitchesNextToNode returns a sorted array of disconnect switch edge ids next to the node
+// in the full topology graph
+func (t *TopologyGridStruct) DisconnectSwitchesNextToNode(nodeId int) ([]int, error) {
+	return t.SwitchesNextToNode(nodeId, TypeDisconnectSwitch)
+}
+
+// ProtectiveDevicesNextToNode returns a sorted array of circuit breaker and fuse edge ids next to the node
+// in the full topology graph
+func (t *TopologyGridStruct) ProtectiveDevicesNextToNode(nodeId int) ([]int, error) {
+	return t.SwitchesNextToNode(nodeId, TypeCircuitBreaker, TypeFuse)
+}
+
+// SwitchesNextToNode returns a sorted array of edge ids of the equipment types next to the node in the full topology
+// graph, i.e. touching the zone of the node bounded by circuit breakers and fuses. Circuit breakers and disconnect switches are
+// returned if no type is given
+func (t *TopologyGridStruct) SwitchesNextToNode(nodeId int, typeIds ...int) ([]int, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	nodeIdx, exists := t.nodeIdxFromNodeId[nodeId]
+	if !exists {
+		return nil, nodeNotFound(nodeId)
+	}
+
+	if len(typeIds) == 0 {
+		typeIds = []int{TypeCircuitBreaker, TypeDisconnectSwitch}
+	}
+
+	zones := t.zoneCache()
+	zone := zones.zoneFromNodeIdx[nodeIdx]
+
+	edgeIds := make([]int, 0)
+	for _, typeId := range typeIds {
+		edgeIds = append(edgeIds, zones.edgeIdArrayFromTypeAndZone[typeId][zone]...)
+	}
+
+	sort.Ints(edgeIds)
+
+	return edgeIds, nil
+}